@@ -1,6 +1,27 @@
 package ast
 
-import "encoding/json"
+import (
+	"encoding/json"
+	"fmt"
+)
+
+// Position is a location in a source document, lines and columns start from 1. The zero value is used for nodes that were not parsed from a source (for example nodes built in code).
+type Position struct {
+	Line, Column int
+}
+
+// IsValid reports whether the position holds source information.
+func (p Position) IsValid() bool {
+	return p.Line > 0
+}
+
+func (p Position) String() string {
+	if !p.IsValid() {
+		return "-"
+	}
+
+	return fmt.Sprintf("%d:%d", p.Line, p.Column)
+}
 
 // Node is an abstract syntax tree representation of the TextML without the parsing information and mostly used during transformations.
 type Node interface{ sealNode() }
@@ -11,6 +32,8 @@ type Block []Node
 // TextNode represents a text node
 type TextNode struct {
 	Text string
+
+	Pos Position
 }
 
 func (TextNode) sealNode() {}
@@ -26,6 +49,8 @@ func (n *TextNode) MarshalJSON() ([]byte, error) {
 type ElementNode struct {
 	Name      string
 	Arguments []Block
+
+	Pos Position
 }
 
 func (ElementNode) sealNode() {}
@@ -0,0 +1,49 @@
+package ast_test
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/aziis98/textml"
+	"github.com/aziis98/textml/ast"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestBuilder(t *testing.T) {
+	doc := ast.B(
+		ast.E("title", ast.T("Example")),
+		ast.T("\nSome "),
+		ast.E("bold", ast.T("bold")),
+		ast.T(" text and a "),
+		ast.EN("link",
+			ast.B(ast.T("link")),
+			ast.B(ast.T("https://example.org")),
+		),
+		ast.T("\n"),
+		ast.E("figure", ast.Attr("src", "image.png"), ast.Attr("placement", "left")),
+	)
+
+	assert.Equal(t,
+		"#title{ Example }\nSome #bold{ bold } text and a #link{ link }{ https://example.org }\n#figure{ #src{ image.png }#placement{ left } }",
+		doc.String(),
+	)
+
+	parsed, err := textml.ParseDocument(strings.NewReader(doc.String()))
+	assert.Nil(t, err)
+	assert.True(t, parsed.Equal(doc))
+}
+
+func TestCloneAndEqual(t *testing.T) {
+	doc := ast.B(ast.E("a", ast.T("x"), ast.E("b", ast.T("y"))))
+
+	clone := doc.Clone()
+	assert.True(t, clone.Equal(doc))
+
+	clone[0].(*ast.ElementNode).Arguments[0][1].(*ast.ElementNode).Name = "c"
+	assert.False(t, clone.Equal(doc))
+	assert.Equal(t, "#a{ x#b{ y } }", doc.String())
+	assert.Equal(t, "#a{ x#c{ y } }", clone.String())
+
+	assert.False(t, ast.B(ast.T("x")).Equal(ast.B(ast.E("x"))))
+	assert.True(t, ast.EqualNode(ast.EN("x"), &ast.ElementNode{Name: "x"}))
+}
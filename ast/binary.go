@@ -0,0 +1,284 @@
+package ast
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"io"
+)
+
+// BinaryVersion is the version of the binary encoding written by [EncodeBinary], it changes whenever the format changes and older versions are rejected by [DecodeBinary].
+//
+// An encoded block starts with the magic "TMLB", the version and a flags byte (bit 0 is set when positions are included). Nodes are written as a tag byte (0 for text, 1 for elements) followed by the text or the element name, the position as two uvarints if present and for elements the list of arguments. Lengths and counts are uvarints. Element names are interned, each name is written as an index in the table of already seen names, an index equal to the size of the table is followed by a new name.
+const BinaryVersion = 1
+
+var binaryMagic = []byte("TMLB")
+
+const (
+	binaryFlagPositions = 1 << iota
+)
+
+const (
+	binaryTagText = iota
+	binaryTagElement
+)
+
+// BinaryOptions for [EncodeBinary]
+type BinaryOptions struct {
+	// Positions includes the source position of each node
+	Positions bool
+}
+
+// EncodeBinary writes the compact binary encoding of a block.
+func EncodeBinary(w io.Writer, block Block, opts BinaryOptions) error {
+	e := &binaryEncoder{w: bufio.NewWriter(w), opts: opts, names: map[string]int{}}
+
+	var flags byte
+	if opts.Positions {
+		flags |= binaryFlagPositions
+	}
+
+	e.w.Write(binaryMagic)
+	e.w.WriteByte(BinaryVersion)
+	e.w.WriteByte(flags)
+	e.writeBlock(block)
+
+	return e.w.Flush()
+}
+
+type binaryEncoder struct {
+	w     *bufio.Writer
+	opts  BinaryOptions
+	names map[string]int
+}
+
+func (e *binaryEncoder) writeUvarint(n int) {
+	buf := make([]byte, binary.MaxVarintLen64)
+	e.w.Write(buf[:binary.PutUvarint(buf, uint64(n))])
+}
+
+func (e *binaryEncoder) writeString(s string) {
+	e.writeUvarint(len(s))
+	e.w.WriteString(s)
+}
+
+func (e *binaryEncoder) writePosition(pos Position) {
+	if e.opts.Positions {
+		e.writeUvarint(pos.Line)
+		e.writeUvarint(pos.Column)
+	}
+}
+
+func (e *binaryEncoder) writeBlock(block Block) {
+	e.writeUvarint(len(block))
+
+	for _, n := range block {
+		switch n := n.(type) {
+		case *TextNode:
+			e.w.WriteByte(binaryTagText)
+			e.writeString(n.Text)
+			e.writePosition(n.Pos)
+
+		case *ElementNode:
+			e.w.WriteByte(binaryTagElement)
+			if index, ok := e.names[n.Name]; ok {
+				e.writeUvarint(index)
+			} else {
+				e.names[n.Name] = len(e.names)
+				e.writeUvarint(len(e.names) - 1)
+				e.writeString(n.Name)
+			}
+			e.writePosition(n.Pos)
+
+			e.writeUvarint(len(n.Arguments))
+			for _, arg := range n.Arguments {
+				e.writeBlock(arg)
+			}
+
+		default:
+			panic(fmt.Errorf("unexpected node of type: %T", n))
+		}
+	}
+}
+
+// MarshalBinary returns the binary encoding of the block including positions, see [EncodeBinary].
+func (b Block) MarshalBinary() ([]byte, error) {
+	buf := &bytes.Buffer{}
+	if err := EncodeBinary(buf, b, BinaryOptions{Positions: true}); err != nil {
+		return nil, err
+	}
+
+	return buf.Bytes(), nil
+}
+
+// UnmarshalBinary decodes a block encoded with [EncodeBinary].
+func (b *Block) UnmarshalBinary(data []byte) error {
+	block, err := DecodeBinary(bytes.NewReader(data))
+	if err != nil {
+		return err
+	}
+
+	*b = block
+	return nil
+}
+
+// DecodeBinary reads a block written by [EncodeBinary].
+func DecodeBinary(r io.Reader) (Block, error) {
+	d := &binaryDecoder{r: bufio.NewReader(r)}
+
+	header := make([]byte, len(binaryMagic)+2)
+	if _, err := io.ReadFull(d.r, header); err != nil {
+		return nil, fmt.Errorf("binary ast: reading header: %w", err)
+	}
+	if !bytes.Equal(header[:len(binaryMagic)], binaryMagic) {
+		return nil, fmt.Errorf("binary ast: invalid header")
+	}
+	if version := header[len(binaryMagic)]; version != BinaryVersion {
+		return nil, fmt.Errorf("binary ast: unsupported version %d, expected %d", version, BinaryVersion)
+	}
+
+	d.positions = header[len(binaryMagic)+1]&binaryFlagPositions != 0
+
+	block, err := d.readBlock()
+	if err != nil {
+		if errors.Is(err, io.EOF) {
+			err = io.ErrUnexpectedEOF
+		}
+
+		return nil, fmt.Errorf("binary ast: %w", err)
+	}
+
+	return block, nil
+}
+
+type binaryDecoder struct {
+	r         *bufio.Reader
+	positions bool
+	names     []string
+}
+
+func (d *binaryDecoder) readUvarint() (int, error) {
+	n, err := binary.ReadUvarint(d.r)
+	if err != nil {
+		return 0, err
+	}
+	if n > uint64(1<<31) {
+		return 0, fmt.Errorf("value %d out of range", n)
+	}
+
+	return int(n), nil
+}
+
+func (d *binaryDecoder) readString() (string, error) {
+	size, err := d.readUvarint()
+	if err != nil {
+		return "", err
+	}
+
+	// the buffer grows with the data actually read, a corrupted size can't allocate more than the size of the input
+	buf := &bytes.Buffer{}
+	if n, err := io.CopyN(buf, d.r, int64(size)); err != nil {
+		if err == io.EOF && n < int64(size) {
+			err = io.ErrUnexpectedEOF
+		}
+
+		return "", err
+	}
+
+	return buf.String(), nil
+}
+
+func (d *binaryDecoder) readPosition() (Position, error) {
+	if !d.positions {
+		return Position{}, nil
+	}
+
+	line, err := d.readUvarint()
+	if err != nil {
+		return Position{}, err
+	}
+
+	column, err := d.readUvarint()
+	if err != nil {
+		return Position{}, err
+	}
+
+	return Position{line, column}, nil
+}
+
+func (d *binaryDecoder) readBlock() (Block, error) {
+	count, err := d.readUvarint()
+	if err != nil {
+		return nil, err
+	}
+
+	block := Block{}
+	for i := 0; i < count; i++ {
+		tag, err := d.r.ReadByte()
+		if err != nil {
+			return nil, err
+		}
+
+		switch tag {
+		case binaryTagText:
+			text, err := d.readString()
+			if err != nil {
+				return nil, err
+			}
+
+			pos, err := d.readPosition()
+			if err != nil {
+				return nil, err
+			}
+
+			block = append(block, &TextNode{Text: text, Pos: pos})
+
+		case binaryTagElement:
+			index, err := d.readUvarint()
+			if err != nil {
+				return nil, err
+			}
+
+			switch {
+			case index == len(d.names):
+				name, err := d.readString()
+				if err != nil {
+					return nil, err
+				}
+
+				d.names = append(d.names, name)
+			case index > len(d.names):
+				return nil, fmt.Errorf("invalid name index %d", index)
+			}
+
+			pos, err := d.readPosition()
+			if err != nil {
+				return nil, err
+			}
+
+			argCount, err := d.readUvarint()
+			if err != nil {
+				return nil, err
+			}
+
+			elem := &ElementNode{Name: d.names[index], Arguments: []Block{}, Pos: pos}
+			for k := 0; k < argCount; k++ {
+				arg, err := d.readBlock()
+				if err != nil {
+					return nil, err
+				}
+
+				elem.Arguments = append(elem.Arguments, arg)
+			}
+
+			block = append(block, elem)
+
+		default:
+			return nil, fmt.Errorf("invalid node tag %d", tag)
+		}
+	}
+
+	return block, nil
+}
@@ -0,0 +1,103 @@
+package ast_test
+
+import (
+	"bytes"
+	"encoding/binary"
+	"encoding/json"
+	"runtime"
+	"testing"
+
+	"github.com/aziis98/textml/ast"
+	"github.com/stretchr/testify/assert"
+)
+
+const binarySource = "#title{ Example }\nSome #bold{ bold } and #link{ a #bold{ link } }{ https://example.org }.\n#{ x }"
+
+func TestBinaryRoundTrip(t *testing.T) {
+	doc := parse(t, binarySource)
+
+	buf := &bytes.Buffer{}
+	assert.Nil(t, ast.EncodeBinary(buf, doc, ast.BinaryOptions{}))
+
+	decoded, err := ast.DecodeBinary(buf)
+	assert.Nil(t, err)
+	assert.True(t, decoded.Equal(doc))
+	assert.False(t, decoded[0].(*ast.ElementNode).Pos.IsValid())
+
+	// the binary encoding is smaller than the JSON one
+	data, err := json.Marshal(doc)
+	assert.Nil(t, err)
+
+	buf.Reset()
+	assert.Nil(t, ast.EncodeBinary(buf, doc, ast.BinaryOptions{}))
+	assert.Less(t, buf.Len(), len(data)/2)
+}
+
+func TestBinaryPositions(t *testing.T) {
+	doc := parse(t, binarySource)
+
+	data, err := doc.MarshalBinary()
+	assert.Nil(t, err)
+
+	var decoded ast.Block
+	assert.Nil(t, decoded.UnmarshalBinary(data))
+	assert.True(t, decoded.Equal(doc))
+
+	positions := []ast.Position{}
+	decoded.Walk(func(n ast.Node) error {
+		switch n := n.(type) {
+		case *ast.TextNode:
+			positions = append(positions, n.Pos)
+		case *ast.ElementNode:
+			positions = append(positions, n.Pos)
+		}
+		return nil
+	})
+
+	expected := []ast.Position{}
+	doc.Walk(func(n ast.Node) error {
+		switch n := n.(type) {
+		case *ast.TextNode:
+			expected = append(expected, n.Pos)
+		case *ast.ElementNode:
+			expected = append(expected, n.Pos)
+		}
+		return nil
+	})
+
+	assert.Equal(t, expected, positions)
+}
+
+func TestBinaryErrors(t *testing.T) {
+	data, err := parse(t, binarySource).MarshalBinary()
+	assert.Nil(t, err)
+
+	var block ast.Block
+	assert.NotNil(t, block.UnmarshalBinary([]byte("nope")))
+	assert.NotNil(t, block.UnmarshalBinary(data[:len(data)-3]))
+
+	outdated := append([]byte{}, data...)
+	outdated[4] = ast.BinaryVersion + 1
+	assert.EqualError(t, block.UnmarshalBinary(outdated), "binary ast: unsupported version 2, expected 1")
+}
+
+func TestBinaryCorruptedSize(t *testing.T) {
+	data, err := ast.Block{ast.T("abc")}.MarshalBinary()
+	assert.Nil(t, err)
+
+	// a text node claiming to be 1 GiB long
+	size := make([]byte, binary.MaxVarintLen64)
+	size = size[:binary.PutUvarint(size, 1<<30)]
+
+	corrupted := append(append(append([]byte{}, data[:8]...), size...), "abc"...)
+
+	var stats runtime.MemStats
+	runtime.ReadMemStats(&stats)
+	allocated := stats.TotalAlloc
+
+	var block ast.Block
+	assert.EqualError(t, block.UnmarshalBinary(corrupted), "binary ast: unexpected EOF")
+
+	runtime.ReadMemStats(&stats)
+	assert.Less(t, stats.TotalAlloc-allocated, uint64(1<<20))
+}
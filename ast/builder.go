@@ -0,0 +1,121 @@
+package ast
+
+import "strings"
+
+// T creates a text node.
+func T(text string) *TextNode {
+	return &TextNode{Text: text}
+}
+
+// B creates a block from a list of nodes.
+func B(nodes ...Node) Block {
+	return append(Block{}, nodes...)
+}
+
+// E creates an element with a single argument holding the given nodes, for example E("bold", T("x")) is #bold{ x }.
+func E(name string, nodes ...Node) *ElementNode {
+	return &ElementNode{Name: name, Arguments: []Block{B(nodes...)}}
+}
+
+// EN creates an element with one argument for each block, for example
+//
+//	EN("link", B(T("Wikipedia")), B(T("https://en.wikipedia.org")))
+func EN(name string, args ...Block) *ElementNode {
+	return &ElementNode{Name: name, Arguments: append([]Block{}, args...)}
+}
+
+// Attr creates an attribute-style element with a single text argument like #src{ image.png }.
+func Attr(name, value string) *ElementNode {
+	return E(name, T(value))
+}
+
+// Clone returns a deep copy of the block.
+func (b Block) Clone() Block {
+	if b == nil {
+		return nil
+	}
+
+	nodes := make(Block, len(b))
+	for i, n := range b {
+		nodes[i] = CloneNode(n)
+	}
+
+	return nodes
+}
+
+// CloneNode returns a deep copy of a node.
+func CloneNode(n Node) Node {
+	switch n := n.(type) {
+	case *TextNode:
+		clone := *n
+		return &clone
+
+	case *ElementNode:
+		args := make([]Block, len(n.Arguments))
+		for i, arg := range n.Arguments {
+			args[i] = arg.Clone()
+		}
+
+		return &ElementNode{Name: n.Name, Arguments: args, Pos: n.Pos}
+
+	default:
+		panic("illegal state")
+	}
+}
+
+// Equal reports whether two blocks have the same structure, names and text. Positions are ignored and so is the difference between nil and empty blocks.
+func (b Block) Equal(other Block) bool {
+	if len(b) != len(other) {
+		return false
+	}
+
+	for i := range b {
+		if !EqualNode(b[i], other[i]) {
+			return false
+		}
+	}
+
+	return true
+}
+
+// EqualNode is like [Block.Equal] for single nodes.
+func EqualNode(a, b Node) bool {
+	switch a := a.(type) {
+	case *TextNode:
+		b, ok := b.(*TextNode)
+		return ok && a.Text == b.Text
+
+	case *ElementNode:
+		b, ok := b.(*ElementNode)
+		if !ok || a.Name != b.Name || len(a.Arguments) != len(b.Arguments) {
+			return false
+		}
+
+		for i := range a.Arguments {
+			if !a.Arguments[i].Equal(b.Arguments[i]) {
+				return false
+			}
+		}
+
+		return true
+
+	default:
+		return false
+	}
+}
+
+// String returns the TextML source of the block, blocks that can't be read back (see [Print]) are printed anyway.
+func (b Block) String() string {
+	sb := &strings.Builder{}
+	p := &printer{w: sb, lenient: true}
+	p.printBlock(b, 1)
+	return sb.String()
+}
+
+func (n *TextNode) String() string {
+	return Block{n}.String()
+}
+
+func (n *ElementNode) String() string {
+	return Block{n}.String()
+}
@@ -3,10 +3,11 @@ package ast
 import (
 	"fmt"
 
+	"github.com/aziis98/textml/lexer"
 	"github.com/aziis98/textml/parser"
 )
 
-// Compile a [*parser.Block] into a [Block] instance (for now just strips token information).
+// Compile a [*parser.Block] into a [Block] instance, token information is reduced to the [Position] of each node.
 func Compile(block *parser.Block) Block {
 	nodes := Block{}
 	for _, child := range block.Children {
@@ -14,6 +15,7 @@ func Compile(block *parser.Block) Block {
 		case *parser.TextNode:
 			nodes = append(nodes, &TextNode{
 				Text: child.Text,
+				Pos:  tokenPosition(child.Token),
 			})
 
 		case *parser.ElementNode:
@@ -25,6 +27,7 @@ func Compile(block *parser.Block) Block {
 			nodes = append(nodes, &ElementNode{
 				Name:      child.Name,
 				Arguments: args,
+				Pos:       tokenPosition(child.Token),
 			})
 
 		default:
@@ -34,3 +37,15 @@ func Compile(block *parser.Block) Block {
 
 	return nodes
 }
+
+// tokenPosition converts the zero based token information of the lexer to a [Position].
+func tokenPosition(t *lexer.Token) Position {
+	if t == nil {
+		return Position{}
+	}
+
+	return Position{
+		Line:   t.TokenInfo.Line + 1,
+		Column: t.TokenInfo.Column + 1,
+	}
+}
@@ -0,0 +1,144 @@
+package ast
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+)
+
+// JSONVersion is the version of the JSON format described in "docs/json.md"
+const JSONVersion = 1
+
+// JSONDocument is the top level object of a document in the JSON format, the version tells readers which format the nodes are encoded with.
+type JSONDocument struct {
+	Version int   `json:"version"`
+	Nodes   Block `json:"nodes"`
+}
+
+// NewJSONDocument wraps the nodes of a document with the current version of the JSON format.
+func NewJSONDocument(block Block) *JSONDocument {
+	if block == nil {
+		block = Block{}
+	}
+
+	return &JSONDocument{Version: JSONVersion, Nodes: block}
+}
+
+// UnmarshalJSON decodes a document checking its version, documents written by a newer version of the format are rejected.
+func (d *JSONDocument) UnmarshalJSON(data []byte) error {
+	var v struct {
+		Version *int            `json:"version"`
+		Nodes   json.RawMessage `json:"nodes"`
+	}
+	if err := json.Unmarshal(data, &v); err != nil {
+		var typeErr *json.UnmarshalTypeError
+		if errors.As(err, &typeErr) && typeErr.Field == "" {
+			return fmt.Errorf(`expected a document object with "version" and "nodes" fields, got %s`, typeErr.Value)
+		}
+
+		return err
+	}
+	if v.Version == nil {
+		return fmt.Errorf(`document without "version" field`)
+	}
+	if *v.Version != JSONVersion {
+		return fmt.Errorf("unsupported document version %d, expected %d", *v.Version, JSONVersion)
+	}
+	if v.Nodes == nil {
+		return fmt.Errorf(`document without "nodes" field`)
+	}
+
+	var nodes Block
+	if err := json.Unmarshal(v.Nodes, &nodes); err != nil {
+		return err
+	}
+
+	*d = JSONDocument{Version: *v.Version, Nodes: nodes}
+	return nil
+}
+
+// UnmarshalJSON decodes a JSON array of nodes (version 1 of the format described in "docs/json.md"), each node is dispatched on its "type" field.
+func (b *Block) UnmarshalJSON(data []byte) error {
+	var items []json.RawMessage
+	if err := json.Unmarshal(data, &items); err != nil {
+		return err
+	}
+
+	nodes := Block{}
+	for i, item := range items {
+		var header struct {
+			Type string `json:"type"`
+		}
+		if err := json.Unmarshal(item, &header); err != nil {
+			return fmt.Errorf("node %d: %w", i, err)
+		}
+
+		var node Node
+		switch header.Type {
+		case "text":
+			node = &TextNode{}
+		case "element":
+			node = &ElementNode{}
+		default:
+			return fmt.Errorf("node %d: invalid node type %q", i, header.Type)
+		}
+
+		if err := json.Unmarshal(item, node); err != nil {
+			return fmt.Errorf("node %d: %w", i, err)
+		}
+
+		nodes = append(nodes, node)
+	}
+
+	*b = nodes
+	return nil
+}
+
+func (n *TextNode) UnmarshalJSON(data []byte) error {
+	var v struct {
+		Type string  `json:"type"`
+		Text *string `json:"text"`
+	}
+	if err := json.Unmarshal(data, &v); err != nil {
+		return err
+	}
+	if v.Type != "text" {
+		return fmt.Errorf(`expected node of type "text", got %q`, v.Type)
+	}
+	if v.Text == nil {
+		return fmt.Errorf(`text node without "text" field`)
+	}
+
+	*n = TextNode{Text: *v.Text}
+	return nil
+}
+
+func (n *ElementNode) UnmarshalJSON(data []byte) error {
+	var v struct {
+		Type string            `json:"type"`
+		Name *string           `json:"name"`
+		Args []json.RawMessage `json:"args"`
+	}
+	if err := json.Unmarshal(data, &v); err != nil {
+		return err
+	}
+	if v.Type != "element" {
+		return fmt.Errorf(`expected node of type "element", got %q`, v.Type)
+	}
+	if v.Name == nil {
+		return fmt.Errorf(`element node without "name" field`)
+	}
+
+	args := []Block{}
+	for i, arg := range v.Args {
+		var block Block
+		if err := json.Unmarshal(arg, &block); err != nil {
+			return fmt.Errorf("element %q: argument %d: %w", *v.Name, i, err)
+		}
+
+		args = append(args, block)
+	}
+
+	*n = ElementNode{Name: *v.Name, Arguments: args}
+	return nil
+}
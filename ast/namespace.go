@@ -0,0 +1,89 @@
+package ast
+
+import (
+	"fmt"
+	"strings"
+)
+
+// NamespaceElement is the name of the element declaring namespace aliases, #namespace{ ALIAS }{ NAMESPACE } lets a document write #ALIAS.NAME for #NAMESPACE.NAME.
+const NamespaceElement = "namespace"
+
+// SplitName splits an element name at its last dot in the namespace and the local name, names without dots are in the empty namespace. For example "html.svg.circle" gives "html.svg" and "circle".
+func SplitName(name string) (namespace, local string) {
+	if i := strings.LastIndex(name, "."); i >= 0 {
+		return name[:i], name[i+1:]
+	}
+
+	return "", name
+}
+
+// Namespace returns the namespace of the element name, see [SplitName].
+func (n *ElementNode) Namespace() string {
+	namespace, _ := SplitName(n.Name)
+	return namespace
+}
+
+// LocalName returns the element name without its namespace, see [SplitName].
+func (n *ElementNode) LocalName() string {
+	_, local := SplitName(n.Name)
+	return local
+}
+
+// InNamespace reports whether the element belongs to the namespace or to one nested in it, for example #html.svg.circle is in both "html.svg" and "html".
+func (n *ElementNode) InNamespace(namespace string) bool {
+	ns := n.Namespace()
+	return ns == namespace || strings.HasPrefix(ns, namespace+".")
+}
+
+// ResolveNamespaces returns a copy of the document where the aliases declared by top level #namespace{ ALIAS }{ NAMESPACE } elements are expanded and the declarations are removed, for example with #namespace{ h }{ html } the element #h.div becomes #html.div. Aliases apply to the whole document, also before their declaration, and only to the first part of a namespace.
+func ResolveNamespaces(block Block) (Block, error) {
+	aliases := map[string]string{}
+	result := Block{}
+
+	for _, n := range block {
+		elem, ok := n.(*ElementNode)
+		if !ok || elem.Name != NamespaceElement {
+			result = append(result, n)
+			continue
+		}
+
+		if len(elem.Arguments) != 2 {
+			return nil, fmt.Errorf("%v: #%s expects 2 arguments, got %d", elem.Pos, NamespaceElement, len(elem.Arguments))
+		}
+
+		alias := strings.TrimSpace(elem.Arguments[0].TextContent())
+		namespace := strings.TrimSpace(elem.Arguments[1].TextContent())
+
+		if alias == "" || strings.Contains(alias, ".") || !IsValidName(alias) {
+			return nil, fmt.Errorf("%v: invalid namespace alias %q", elem.Pos, alias)
+		}
+		if namespace == "" || !IsValidName(namespace) {
+			return nil, fmt.Errorf("%v: invalid namespace %q", elem.Pos, namespace)
+		}
+		if previous, ok := aliases[alias]; ok && previous != namespace {
+			return nil, fmt.Errorf("%v: namespace alias %q already declared for %q", elem.Pos, alias, previous)
+		}
+
+		aliases[alias] = namespace
+	}
+
+	if len(aliases) == 0 {
+		return result, nil
+	}
+
+	return mapBlock(result, func(block Block) Block {
+		for _, n := range block {
+			elem, ok := n.(*ElementNode)
+			if !ok || elem.Namespace() == "" {
+				continue
+			}
+
+			first, rest, _ := strings.Cut(elem.Name, ".")
+			if namespace, ok := aliases[first]; ok {
+				elem.Name = namespace + "." + rest
+			}
+		}
+
+		return block
+	}), nil
+}
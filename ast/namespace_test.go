@@ -0,0 +1,51 @@
+package ast_test
+
+import (
+	"testing"
+
+	"github.com/aziis98/textml/ast"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestSplitName(t *testing.T) {
+	for _, tc := range []struct{ name, namespace, local string }{
+		{"title", "", "title"},
+		{"html.div", "html", "div"},
+		{"html.svg.circle", "html.svg", "circle"},
+		{"", "", ""},
+	} {
+		namespace, local := ast.SplitName(tc.name)
+		assert.Equal(t, tc.namespace, namespace, tc.name)
+		assert.Equal(t, tc.local, local, tc.name)
+	}
+
+	elem := &ast.ElementNode{Name: "html.svg.circle"}
+	assert.Equal(t, "html.svg", elem.Namespace())
+	assert.Equal(t, "circle", elem.LocalName())
+	assert.True(t, elem.InNamespace("html"))
+	assert.True(t, elem.InNamespace("html.svg"))
+	assert.False(t, elem.InNamespace("htm"))
+}
+
+func TestResolveNamespaces(t *testing.T) {
+	doc := parse(t, "#namespace{ h }{ html }\n#h.div{ #h.p{ text } #h.svg.circle{ x } #hr{ y } #other.p{ z } }")
+
+	resolved, err := ast.ResolveNamespaces(doc)
+	assert.Nil(t, err)
+	assert.Equal(t, "\n#html.div{ #html.p{ text } #html.svg.circle{ x } #hr{ y } #other.p{ z } }", resolved.String())
+
+	// the input is not modified
+	assert.Equal(t, "h.div", doc[2].(*ast.ElementNode).Name)
+}
+
+func TestResolveNamespacesErrors(t *testing.T) {
+	for _, source := range []string{
+		"#namespace{ h }",
+		"#namespace{ h.x }{ html }",
+		"#namespace{ h }{ not valid }",
+		"#namespace{ h }{ html }#namespace{ h }{ svg }",
+	} {
+		_, err := ast.ResolveNamespaces(parse(t, source))
+		assert.NotNil(t, err, source)
+	}
+}
@@ -0,0 +1,202 @@
+package ast
+
+import (
+	"regexp"
+	"strings"
+	"unicode"
+
+	"github.com/aziis98/textml/utils"
+)
+
+// Pass is a normalization pass, passes return a new block and never modify their argument so they can be freely composed with [Normalize] and [Chain].
+type Pass func(Block) Block
+
+// Normalize applies the passes to the block in order.
+func Normalize(block Block, passes ...Pass) Block {
+	for _, pass := range passes {
+		block = pass(block)
+	}
+
+	return block
+}
+
+// Chain returns a pass applying the given passes in order.
+func Chain(passes ...Pass) Pass {
+	return func(block Block) Block {
+		return Normalize(block, passes...)
+	}
+}
+
+// mapBlock returns a copy of the block where f is applied to every argument and to the block itself, blocks are transformed bottom up.
+func mapBlock(block Block, f func(Block) Block) Block {
+	result := Block{}
+
+	for _, n := range block {
+		switch n := n.(type) {
+		case *TextNode:
+			result = append(result, &TextNode{Text: n.Text, Pos: n.Pos})
+		case *ElementNode:
+			args := []Block{}
+			for _, arg := range n.Arguments {
+				args = append(args, mapBlock(arg, f))
+			}
+
+			result = append(result, &ElementNode{Name: n.Name, Arguments: args, Pos: n.Pos})
+		}
+	}
+
+	return f(result)
+}
+
+// mapText returns a copy of the block with f applied to the text of every text node.
+func mapText(block Block, f func(string) string) Block {
+	return mapBlock(block, func(block Block) Block {
+		for _, n := range block {
+			if n, ok := n.(*TextNode); ok {
+				n.Text = f(n.Text)
+			}
+		}
+
+		return block
+	})
+}
+
+// MergeText joins adjacent text nodes and removes empty ones, the merged node keeps the position of the first one.
+func MergeText(block Block) Block {
+	return mapBlock(block, func(block Block) Block {
+		result := Block{}
+
+		for _, n := range block {
+			text, ok := n.(*TextNode)
+			if !ok {
+				result = append(result, n)
+				continue
+			}
+			if text.Text == "" {
+				continue
+			}
+
+			if len(result) > 0 {
+				if prev, ok := result[len(result)-1].(*TextNode); ok {
+					prev.Text += text.Text
+					continue
+				}
+			}
+
+			result = append(result, text)
+		}
+
+		return result
+	})
+}
+
+// StripBlankText returns a pass removing whitespace only text nodes placed between block elements, that is when both neighbours are block elements or one of them is a block element and the other is the start or end of the argument. This should run after [MergeText] as only direct neighbours are considered.
+func StripBlankText(isBlock func(*ElementNode) bool) Pass {
+	isBlockAt := func(block Block, i int) (isElem, ok bool) {
+		if i < 0 || i >= len(block) {
+			return false, true
+		}
+
+		elem, isElem := block[i].(*ElementNode)
+		return isElem, isElem && isBlock(elem)
+	}
+
+	return func(block Block) Block {
+		return mapBlock(block, func(block Block) Block {
+			result := Block{}
+
+			for i, n := range block {
+				if text, ok := n.(*TextNode); ok && strings.TrimSpace(text.Text) == "" {
+					prevElem, prevOk := isBlockAt(block, i-1)
+					nextElem, nextOk := isBlockAt(block, i+1)
+
+					if prevOk && nextOk && (prevElem || nextElem) {
+						continue
+					}
+				}
+
+				result = append(result, n)
+			}
+
+			return result
+		})
+	}
+}
+
+// Dedent removes the indentation common to all non blank lines of each argument, lines start after a newline so text on the same line as the opening braces doesn't count. The top level block is also dedented.
+func Dedent(block Block) Block {
+	return mapBlock(block, func(block Block) Block {
+		indent, found := "", false
+
+		for i, n := range block {
+			text, ok := n.(*TextNode)
+			if !ok {
+				continue
+			}
+
+			lines := strings.Split(text.Text, "\n")
+			for k, line := range lines[1:] {
+				lineIndent := line[:len(line)-len(strings.TrimLeft(line, " \t"))]
+
+				// blank lines don't count, a line ending the text node is not blank if an element follows
+				last := k == len(lines)-2
+				if len(lineIndent) == len(line) && (!last || i == len(block)-1 || isText(block[i+1])) {
+					continue
+				}
+
+				if !found {
+					indent, found = lineIndent, true
+				} else {
+					indent = commonPrefix(indent, lineIndent)
+				}
+			}
+		}
+
+		if indent == "" {
+			return block
+		}
+
+		for _, n := range block {
+			if text, ok := n.(*TextNode); ok {
+				lines := strings.Split(text.Text, "\n")
+				for k := 1; k < len(lines); k++ {
+					lines[k] = strings.TrimPrefix(lines[k], commonPrefix(indent, lines[k]))
+				}
+
+				text.Text = strings.Join(lines, "\n")
+			}
+		}
+
+		return block
+	})
+}
+
+func isText(n Node) bool {
+	_, ok := n.(*TextNode)
+	return ok
+}
+
+func commonPrefix(a, b string) string {
+	i := 0
+	for i < len(a) && i < len(b) && a[i] == b[i] {
+		i++
+	}
+
+	return a[:i]
+}
+
+var regexLineWithIndent = regexp.MustCompile(` *\n\s*`)
+
+// JoinLines removes newlines together with the spaces before them and the indentation after them, this is the pass used by the #inline element of the template runtime.
+func JoinLines(block Block) Block {
+	return mapText(block, func(s string) string {
+		return regexLineWithIndent.ReplaceAllString(s, "")
+	})
+}
+
+// CollapseWhitespace replaces each run of whitespace (newlines included) in text nodes with a single space.
+func CollapseWhitespace(block Block) Block {
+	return mapText(block, func(s string) string {
+		return utils.CollapseSpaces(s, unicode.IsSpace)
+	})
+}
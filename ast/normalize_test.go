@@ -0,0 +1,77 @@
+package ast_test
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/aziis98/textml"
+	"github.com/aziis98/textml/ast"
+	"github.com/stretchr/testify/assert"
+)
+
+func parse(t *testing.T, source string) ast.Block {
+	t.Helper()
+
+	block, err := textml.ParseDocument(strings.NewReader(source))
+	assert.Nil(t, err)
+	return block
+}
+
+func TestMergeText(t *testing.T) {
+	doc := ast.B(ast.T("a"), ast.T(""), ast.T("b"), ast.E("x", ast.T("c"), ast.T("d")), ast.T("e"))
+
+	merged := ast.MergeText(doc)
+	assert.True(t, merged.Equal(ast.B(ast.T("ab"), ast.E("x", ast.T("cd")), ast.T("e"))))
+
+	// the input is not modified
+	assert.Len(t, doc, 5)
+	assert.Equal(t, "a", doc[0].(*ast.TextNode).Text)
+}
+
+func TestStripBlankText(t *testing.T) {
+	isBlock := func(elem *ast.ElementNode) bool { return elem.Name == "p" }
+
+	doc := ast.B(
+		ast.T("\n"),
+		ast.E("p", ast.T("one")),
+		ast.T("\n\n"),
+		ast.E("p", ast.T("two "), ast.E("b", ast.T("bold")), ast.T(" "), ast.E("i", ast.T("italic"))),
+		ast.T(" "),
+		ast.E("b", ast.T("inline")),
+		ast.T("\n"),
+	)
+
+	assert.Equal(t,
+		"#p{ one }#p{ two #b{ bold } #i{ italic } } #b{ inline }\n",
+		ast.StripBlankText(isBlock)(doc).String(),
+	)
+}
+
+func TestDedent(t *testing.T) {
+	doc := parse(t, "#code{{\n        func main() {\n            #b{{ x }}\n\n        }\n    }}")
+
+	assert.Equal(t,
+		"#code{{\nfunc main() {\n    #b{{ x }}\n\n}\n}}",
+		ast.Dedent(doc).String(),
+	)
+}
+
+func TestJoinLines(t *testing.T) {
+	doc := parse(t, "#inline{\n    Hi,   \n    #b{ world\n    }!\n}")
+
+	assert.Equal(t, "#inline{ Hi,#b{ world }! }", ast.JoinLines(doc).String())
+}
+
+func TestCollapseWhitespace(t *testing.T) {
+	doc := ast.B(ast.T("  a \n\t b  "), ast.E("x", ast.T("c\n\nd")))
+
+	assert.Equal(t, " a b #x{ c d }", ast.CollapseWhitespace(doc).String())
+}
+
+func TestChain(t *testing.T) {
+	pass := ast.Chain(ast.MergeText, ast.CollapseWhitespace)
+
+	doc := ast.B(ast.T("a\n"), ast.T("\nb"))
+	assert.True(t, pass(doc).Equal(ast.B(ast.T("a b"))))
+	assert.True(t, ast.Normalize(doc, pass).Equal(ast.B(ast.T("a b"))))
+}
@@ -0,0 +1,150 @@
+package ast
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+// Path addresses a node in a document as a sequence of indices alternating between node indices and argument indices, for example [3 1 0] is the first node in the second argument of the fourth top level node. Paths are written like JSON Pointers as "/3/args/1/0", the empty path refers to the document itself.
+type Path []int
+
+// Child returns the path of the node at index i in argument arg of the node at p, for the empty path arg is ignored and the result is the path of the top level node i.
+func (p Path) Child(arg, i int) Path {
+	if len(p) == 0 {
+		return Path{i}
+	}
+
+	return append(append(Path{}, p...), arg, i)
+}
+
+// Parent returns the path of the element containing the node at p and the index of the argument holding it, for top level nodes the parent is the empty path.
+func (p Path) Parent() (Path, int) {
+	if len(p) <= 1 {
+		return Path{}, 0
+	}
+
+	return p[:len(p)-2], p[len(p)-2]
+}
+
+// Last returns the index of the node in its block.
+func (p Path) Last() int {
+	return p[len(p)-1]
+}
+
+func (p Path) String() string {
+	sb := &strings.Builder{}
+
+	for i, index := range p {
+		if i%2 == 1 {
+			sb.WriteString("/args")
+		}
+
+		fmt.Fprintf(sb, "/%d", index)
+	}
+
+	return sb.String()
+}
+
+func (p Path) MarshalText() ([]byte, error) {
+	return []byte(p.String()), nil
+}
+
+func (p *Path) UnmarshalText(text []byte) error {
+	path, err := ParsePath(string(text))
+	if err != nil {
+		return err
+	}
+
+	*p = path
+	return nil
+}
+
+// ParsePath reads a path written by [Path.String].
+func ParsePath(s string) (Path, error) {
+	if s == "" {
+		return Path{}, nil
+	}
+	if !strings.HasPrefix(s, "/") {
+		return nil, fmt.Errorf(`invalid path %q, paths must start with "/"`, s)
+	}
+
+	// paths are made of a node index followed by any number of "args/ARG/NODE" steps
+	parts := strings.Split(s[1:], "/")
+	if len(parts)%3 != 1 {
+		return nil, fmt.Errorf(`invalid path %q, expected "/NODE" followed by "/args/ARG/NODE" steps`, s)
+	}
+
+	path := Path{}
+	for i, part := range parts {
+		if i%3 == 1 {
+			if part != "args" {
+				return nil, fmt.Errorf(`invalid path %q, expected "args" instead of %q`, s, part)
+			}
+			continue
+		}
+
+		index, err := strconv.Atoi(part)
+		if err != nil || index < 0 {
+			return nil, fmt.Errorf("invalid path %q, invalid index %q", s, part)
+		}
+
+		path = append(path, index)
+	}
+
+	return path, nil
+}
+
+// Resolve returns the node at the given path.
+func (b Block) Resolve(path Path) (Node, error) {
+	if len(path) == 0 {
+		return nil, fmt.Errorf("cannot resolve the empty path to a node")
+	}
+	if len(path)%2 == 0 {
+		return nil, fmt.Errorf("invalid path %v, it refers to an argument", path)
+	}
+
+	block := b
+	for k := 0; ; k += 2 {
+		if path[k] < 0 || path[k] >= len(block) {
+			return nil, fmt.Errorf("no node at %v", path[:k+1])
+		}
+
+		n := block[path[k]]
+		if k == len(path)-1 {
+			return n, nil
+		}
+
+		elem, ok := n.(*ElementNode)
+		if !ok || path[k+1] < 0 || path[k+1] >= len(elem.Arguments) {
+			return nil, fmt.Errorf("no argument at %v", path[:k+2])
+		}
+
+		block = elem.Arguments[path[k+1]]
+	}
+}
+
+// WalkPath walks the AST like [Block.Walk] and also passes the path of each visited node. The traversal finishes if the visit function returns a non nil error.
+func (b Block) WalkPath(visitFunc func(Path, Node) error) error {
+	return b.walkPath(Path{}, visitFunc)
+}
+
+func (b Block) walkPath(parent Path, visitFunc func(Path, Node) error) error {
+	for i, node := range b {
+		path := append(append(Path{}, parent...), i)
+
+		if err := visitFunc(path, node); err != nil {
+			return err
+		}
+
+		if elem, ok := node.(*ElementNode); ok {
+			for k, arg := range elem.Arguments {
+				if err := arg.walkPath(append(append(Path{}, path...), k), visitFunc); err != nil {
+					return err
+				}
+			}
+		}
+	}
+
+	return nil
+}
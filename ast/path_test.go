@@ -0,0 +1,79 @@
+package ast_test
+
+import (
+	"encoding/json"
+	"testing"
+
+	"github.com/aziis98/textml/ast"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestPathString(t *testing.T) {
+	assert.Equal(t, "", ast.Path{}.String())
+	assert.Equal(t, "/3", ast.Path{3}.String())
+	assert.Equal(t, "/3/args/1/0", ast.Path{3, 1, 0}.String())
+
+	for _, s := range []string{"", "/0", "/3/args/1/0", "/1/args/0/2/args/1/4"} {
+		path, err := ast.ParsePath(s)
+		assert.Nil(t, err)
+		assert.Equal(t, s, path.String())
+	}
+
+	for _, s := range []string{"3", "/", "/a", "/3/1/0", "/3/args/1", "/3/args/1/0/", "/-1"} {
+		_, err := ast.ParsePath(s)
+		assert.NotNil(t, err, s)
+	}
+}
+
+func TestPathNavigation(t *testing.T) {
+	assert.Equal(t, ast.Path{2}, ast.Path{}.Child(0, 2))
+	assert.Equal(t, ast.Path{2, 1, 0}, ast.Path{2}.Child(1, 0))
+
+	parent, arg := ast.Path{2, 1, 0}.Parent()
+	assert.Equal(t, ast.Path{2}, parent)
+	assert.Equal(t, 1, arg)
+	assert.Equal(t, 0, ast.Path{2, 1, 0}.Last())
+}
+
+func TestResolve(t *testing.T) {
+	doc := parse(t, "Some #link{ #bold{ text } }{ url } here")
+
+	n, err := doc.Resolve(ast.Path{1, 0, 0, 0, 0})
+	assert.Nil(t, err)
+	assert.Equal(t, "text", n.(*ast.TextNode).Text)
+
+	n, err = doc.Resolve(ast.Path{1, 1, 0})
+	assert.Nil(t, err)
+	assert.Equal(t, "url", n.(*ast.TextNode).Text)
+
+	for _, path := range []ast.Path{{}, {1, 0}, {5}, {0, 0, 0}, {1, 2, 0}, {1, 0, 3}} {
+		_, err := doc.Resolve(path)
+		assert.NotNil(t, err, path.String())
+	}
+}
+
+func TestWalkPath(t *testing.T) {
+	doc := parse(t, "Some #link{ #bold{ text } }{ url } here")
+
+	paths := []string{}
+	doc.WalkPath(func(path ast.Path, n ast.Node) error {
+		paths = append(paths, path.String())
+
+		resolved, err := doc.Resolve(path)
+		assert.Nil(t, err)
+		assert.Same(t, n, resolved)
+		return nil
+	})
+
+	assert.Equal(t, []string{"/0", "/1", "/1/args/0/0", "/1/args/0/0/args/0/0", "/1/args/1/0", "/2"}, paths)
+}
+
+func TestPathJSON(t *testing.T) {
+	data, err := json.Marshal(struct{ Path ast.Path }{ast.Path{3, 1, 0}})
+	assert.Nil(t, err)
+	assert.Equal(t, `{"Path":"/3/args/1/0"}`, string(data))
+
+	var v struct{ Path ast.Path }
+	assert.Nil(t, json.Unmarshal(data, &v))
+	assert.Equal(t, ast.Path{3, 1, 0}, v.Path)
+}
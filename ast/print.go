@@ -0,0 +1,182 @@
+package ast
+
+import (
+	"fmt"
+	"io"
+	"regexp"
+	"strings"
+	"unicode"
+
+	"github.com/aziis98/textml/utils"
+)
+
+// IsValidName reports whether name can be used as an element name, this follows the rules of the lexer so the empty name is also valid.
+func IsValidName(name string) bool {
+	for _, r := range name {
+		if !isNameRune(r) {
+			return false
+		}
+	}
+
+	return true
+}
+
+func isNameRune(r rune) bool {
+	return unicode.IsLetter(r) || unicode.IsDigit(r) || r == '-' || r == '_' || r == '.'
+}
+
+// regexElementLike matches text that the lexer would read as the start of an element, the first group holds the braces.
+var regexElementLike = regexp.MustCompile(`#[\p{L}\p{Nd}\-_.]* *(\{+)`)
+
+// Print writes the TextML source of a block. The brace depth of each argument is chosen so that text containing braces or element-like sequences is read back unchanged.
+//
+// Some blocks have no source representation, for example elements without arguments or top level text containing closing braces, in that case an error is returned.
+func Print(w io.Writer, block Block) error {
+	p := &printer{w: w}
+	if err := p.checkTopLevel(block); err != nil {
+		return err
+	}
+
+	p.printBlock(block, 1)
+	return p.err
+}
+
+type printer struct {
+	w   io.Writer
+	err error
+
+	// lenient printers skip validation and never fail
+	lenient bool
+}
+
+func (p *printer) write(s string) {
+	if p.err != nil {
+		return
+	}
+
+	_, p.err = io.WriteString(p.w, s)
+}
+
+// checkTopLevel returns an error if the top level text of the document can't be read back, at this level the brace depth is fixed to 1.
+func (p *printer) checkTopLevel(block Block) error {
+	if depth := BraceDepth(block, 1); depth > 1 {
+		return fmt.Errorf("cannot print document, top level text contains braces that would be read as markup")
+	}
+
+	return nil
+}
+
+func (p *printer) printBlock(block Block, depth int) {
+	for _, n := range block {
+		switch n := n.(type) {
+		case *TextNode:
+			p.write(n.Text)
+
+		case *ElementNode:
+			if p.err != nil {
+				return
+			}
+			if !IsValidName(n.Name) && !p.lenient {
+				p.err = fmt.Errorf("cannot print element with invalid name %q", n.Name)
+				return
+			}
+			if len(n.Arguments) == 0 && !p.lenient {
+				p.err = fmt.Errorf("cannot print element #%s without arguments", n.Name)
+				return
+			}
+
+			p.write("#" + n.Name)
+			for _, arg := range n.Arguments {
+				argDepth := BraceDepth(arg, depth)
+
+				p.write(strings.Repeat("{", argDepth))
+				if len(arg) > 0 {
+					// the lexer skips a single space after the opening and before the closing braces, so a space is added unless the argument starts or ends on a new line
+					if !strings.HasPrefix(firstText(arg), "\n") {
+						p.write(" ")
+					}
+					p.printBlock(arg, argDepth)
+					if !strings.HasSuffix(lastText(arg), "\n") {
+						p.write(" ")
+					}
+				}
+				p.write(strings.Repeat("}", argDepth))
+			}
+
+		default:
+			panic(fmt.Errorf("unexpected node of type: %T", n))
+		}
+	}
+}
+
+// BraceDepth computes the number of braces used by [Print] for an argument nested in an argument of the given depth (1 for top level elements), this is the smallest depth for which the text of the argument doesn't close it early or start new elements.
+func BraceDepth(block Block, enclosingDepth int) int {
+	depth := enclosingDepth
+
+	afterElement := false
+	text := ""
+	flush := func() {
+		if afterElement {
+			// braces right after an element would be read as another argument
+			depth = utils.Max(depth, countPrefix(text, '{')+1)
+		}
+
+		depth = utils.Max(depth, longestRun(text, '}')+1)
+		for _, m := range regexElementLike.FindAllStringSubmatch(text, -1) {
+			depth = utils.Max(depth, len(m[1])+1)
+		}
+
+		text = ""
+	}
+
+	for _, n := range block {
+		switch n := n.(type) {
+		case *TextNode:
+			text += n.Text
+		case *ElementNode:
+			flush()
+			afterElement = true
+		}
+	}
+	flush()
+
+	return depth
+}
+
+func firstText(block Block) string {
+	if n, ok := block[0].(*TextNode); ok {
+		return n.Text
+	}
+	return ""
+}
+
+func lastText(block Block) string {
+	if n, ok := block[len(block)-1].(*TextNode); ok {
+		return n.Text
+	}
+	return ""
+}
+
+func countPrefix(s string, r rune) int {
+	count := 0
+	for _, c := range s {
+		if c != r {
+			break
+		}
+		count++
+	}
+	return count
+}
+
+func longestRun(s string, r rune) int {
+	longest, current := 0, 0
+	for _, c := range s {
+		if c == r {
+			current++
+			longest = utils.Max(longest, current)
+		} else {
+			current = 0
+		}
+	}
+	return longest
+}
@@ -0,0 +1,112 @@
+package ast
+
+import (
+	"strings"
+	"unicode"
+	"unicode/utf8"
+)
+
+// TextOptions configures the text extraction done by [Block.Text].
+type TextOptions struct {
+	// Recursive includes the text of element arguments, otherwise elements are skipped like in [Block.TextContent]
+	Recursive bool
+
+	// Arguments are the indices of the element arguments that contribute text, when empty all arguments are used. For example []int{0} keeps only the label of a #link{ LABEL }{ URL }.
+	Arguments []int
+
+	// ArgumentSeparator is written between the text of two arguments of the same element
+	ArgumentSeparator string
+
+	// ElementSeparator is written between the text of an element and the text around it, unless one of the two sides already has whitespace there
+	ElementSeparator string
+
+	// NormalizeSpace replaces runs of whitespace with a single space and trims the result
+	NormalizeSpace bool
+
+	// Overrides gives the text of elements by name, these are used also when Recursive is false
+	Overrides map[string]func(elem *ElementNode, opts TextOptions) string
+}
+
+// ValueText are the options used for the values of #KEY{ VALUE } entries like the ones of #metadata, formatting elements only contribute their text so "An #italic{ example }" gives "An example" and a #link{ LABEL }{ URL } gives its label.
+var ValueText = TextOptions{Recursive: true, Arguments: []int{0}}
+
+// Text extracts the text of this block following the given options, for example
+//
+//	block.Text(ast.TextOptions{Recursive: true})
+//
+// returns "foo bar" for the block of "foo #bold{ bar }".
+func (b Block) Text(opts TextOptions) string {
+	text := b.text(opts)
+
+	if opts.NormalizeSpace {
+		return strings.Join(strings.FieldsFunc(text, unicode.IsSpace), " ")
+	}
+
+	return text
+}
+
+func (b Block) text(opts TextOptions) string {
+	sb := &strings.Builder{}
+
+	// write appends a piece of text, separating it from the previous one when one of them comes from an element
+	lastFromElement := false
+	write := func(s string, fromElement bool) {
+		if s == "" {
+			return
+		}
+
+		if (fromElement || lastFromElement) && sb.Len() > 0 && opts.ElementSeparator != "" {
+			last, _ := utf8.DecodeLastRuneInString(sb.String())
+			first, _ := utf8.DecodeRuneInString(s)
+
+			if !unicode.IsSpace(last) && !unicode.IsSpace(first) {
+				sb.WriteString(opts.ElementSeparator)
+			}
+		}
+
+		sb.WriteString(s)
+		lastFromElement = fromElement
+	}
+
+	for _, n := range b {
+		switch n := n.(type) {
+		case *TextNode:
+			write(n.Text, false)
+		case *ElementNode:
+			if override, ok := opts.Overrides[n.Name]; ok {
+				write(override(n, opts), true)
+				continue
+			}
+			if opts.Recursive {
+				write(n.text(opts), true)
+			}
+		}
+	}
+
+	return sb.String()
+}
+
+// text returns the text of the arguments of an element selected by the options.
+func (n *ElementNode) text(opts TextOptions) string {
+	parts := []string{}
+
+	add := func(arg Block) {
+		if s := arg.text(opts); s != "" {
+			parts = append(parts, s)
+		}
+	}
+
+	if len(opts.Arguments) == 0 {
+		for _, arg := range n.Arguments {
+			add(arg)
+		}
+	} else {
+		for _, i := range opts.Arguments {
+			if i >= 0 && i < len(n.Arguments) {
+				add(n.Arguments[i])
+			}
+		}
+	}
+
+	return strings.Join(parts, opts.ArgumentSeparator)
+}
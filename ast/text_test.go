@@ -0,0 +1,61 @@
+package ast_test
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/aziis98/textml/ast"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestText(t *testing.T) {
+	doc := parse(t, "See #link{ foo #bold{bar} }{ https://example.org } and #code{x}y")
+
+	assert.Equal(t, "See  and y", doc.TextContent())
+	assert.Equal(t, "See  and y", doc.Text(ast.TextOptions{}))
+
+	assert.Equal(t,
+		"See foo barhttps://example.org and xy",
+		doc.Text(ast.TextOptions{Recursive: true}),
+	)
+	assert.Equal(t,
+		"See foo bar and xy",
+		doc.Text(ast.TextOptions{Recursive: true, Arguments: []int{0}}),
+	)
+	assert.Equal(t,
+		"See foo bar | https://example.org and x y",
+		doc.Text(ast.TextOptions{Recursive: true, ArgumentSeparator: " | ", ElementSeparator: " "}),
+	)
+}
+
+func TestTextNormalizeSpace(t *testing.T) {
+	doc := parse(t, "#p{\n    Some   #b{ bold }\n    text\n}")
+
+	assert.Equal(t, "Some bold text", doc.Text(ast.TextOptions{Recursive: true, NormalizeSpace: true}))
+}
+
+func TestTextOverrides(t *testing.T) {
+	doc := parse(t, "Hello #name{ world }, #link{ docs }{ https://example.org }!")
+
+	opts := ast.TextOptions{
+		Overrides: map[string]func(*ast.ElementNode, ast.TextOptions) string{
+			"name": func(elem *ast.ElementNode, opts ast.TextOptions) string {
+				return strings.ToUpper(elem.Arguments[0].Text(opts))
+			},
+			"link": func(elem *ast.ElementNode, opts ast.TextOptions) string {
+				return elem.Arguments[0].Text(opts) + " (" + elem.Arguments[1].Text(opts) + ")"
+			},
+		},
+	}
+
+	assert.Equal(t, "Hello WORLD, docs (https://example.org)!", doc.Text(opts))
+}
+
+func TestValueText(t *testing.T) {
+	doc := parse(t, "#title{ An #italic{ example } } #link{ docs }{ https://example.org }")
+	assert.Equal(t, "An example docs", doc.Text(ast.ValueText))
+
+	assert.True(t, doc.IsDictionary())
+	assert.False(t, parse(t, "#a{ b } c").IsDictionary())
+	assert.False(t, parse(t, "  ").IsDictionary())
+}
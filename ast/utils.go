@@ -1,5 +1,7 @@
 package ast
 
+import "strings"
+
 // FirstElement returns the first [ast.ElementNode] in this block or nil otherwise.
 func (b Block) FirstElement() *ElementNode {
 	for _, n := range b {
@@ -10,7 +12,7 @@ func (b Block) FirstElement() *ElementNode {
 	return nil
 }
 
-// TextContent concatenates all [ast.TextNode] text in this block (for now [ast.ElementNode]s are skipped)
+// TextContent concatenates all [ast.TextNode] text in this block, [ast.ElementNode]s are skipped. Use [Block.Text] to also include the text of elements.
 func (b Block) TextContent() string {
 	s := ""
 	for _, n := range b {
@@ -21,6 +23,21 @@ func (b Block) TextContent() string {
 	return s
 }
 
+// IsDictionary reports whether this block is made of #KEY{ VALUE } entries, that is it has elements and no text other than whitespace.
+func (b Block) IsDictionary() bool {
+	if b.FirstElement() == nil {
+		return false
+	}
+
+	for _, n := range b {
+		if n, ok := n.(*TextNode); ok && strings.TrimSpace(n.Text) != "" {
+			return false
+		}
+	}
+
+	return true
+}
+
 // WalkNodes walks the AST using depth-first pre-order traversal (first the visit the node itself and then all its children). The traversal finishes if the visit function returns a non nil error.
 func (b Block) Walk(visitFunc func(Node) error) error {
 	for _, node := range b {
@@ -0,0 +1,168 @@
+// Package cache stores parsed documents on disk in the binary encoding of the ast package, keyed by a hash of their source.
+//
+// Entries live in DIR/XX/HASH.tmlb where HASH is the hex SHA-256 of the binary format version, the parser version and the source. Corrupted or outdated entries are ignored and replaced, so the cache directory can be removed at any time. The least recently used entries are removed when the entries take more than [Cache.MaxSize] bytes.
+package cache
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"time"
+
+	"github.com/aziis98/textml"
+	"github.com/aziis98/textml/ast"
+)
+
+// DefaultMaxSize is the size limit of the caches returned by [New]
+const DefaultMaxSize = 64 << 20
+
+// Cache is an on-disk cache of parsed documents, a nil *Cache is valid and always parses.
+type Cache struct {
+	Dir string
+
+	// MaxSize is the total size in bytes of the entries kept in Dir, older entries are removed after writing a new one that exceeds it. Zero means no limit.
+	MaxSize int64
+}
+
+// New returns a cache storing entries in dir with the [DefaultMaxSize] limit, the directory is created when the first entry is written.
+func New(dir string) *Cache {
+	return &Cache{Dir: dir, MaxSize: DefaultMaxSize}
+}
+
+// DefaultDir returns the cache directory used by the textml command. Caching is opt-in, an empty result means caching is disabled and this is the case unless $TEXTML_CACHE is set. The value "on" selects "textml" in the user cache directory, "off" disables caching and other values are used as the directory.
+func DefaultDir() string {
+	switch dir := os.Getenv("TEXTML_CACHE"); dir {
+	case "", "off":
+		return ""
+	case "on":
+		userDir, err := os.UserCacheDir()
+		if err != nil {
+			return ""
+		}
+
+		return filepath.Join(userDir, "textml")
+	default:
+		return dir
+	}
+}
+
+// key returns the name of the entry for a source.
+func key(source []byte) string {
+	h := sha256.New()
+	fmt.Fprintf(h, "textml-ast-v%d\ntextml-parser-v%d\n", ast.BinaryVersion, textml.ParserVersion)
+	h.Write(source)
+
+	return hex.EncodeToString(h.Sum(nil))
+}
+
+func (c *Cache) path(source []byte) string {
+	k := key(source)
+	return filepath.Join(c.Dir, k[:2], k+".tmlb")
+}
+
+// Parse returns the document for a source, reading it from the cache when possible. Cache errors are never reported, in the worst case the source is just parsed again.
+func (c *Cache) Parse(source []byte) (ast.Block, error) {
+	if c == nil || c.Dir == "" {
+		return textml.ParseDocument(bytes.NewReader(source))
+	}
+
+	path := c.path(source)
+
+	if data, err := os.ReadFile(path); err == nil {
+		var block ast.Block
+		if err := block.UnmarshalBinary(data); err == nil {
+			// the modification time of entries tracks their last use
+			now := time.Now()
+			os.Chtimes(path, now, now)
+
+			return block, nil
+		}
+	}
+
+	block, err := textml.ParseDocument(bytes.NewReader(source))
+	if err != nil {
+		return nil, err
+	}
+
+	c.store(path, block)
+	return block, nil
+}
+
+// ParseFile reads and parses a file using the cache.
+func (c *Cache) ParseFile(filename string) (ast.Block, error) {
+	source, err := os.ReadFile(filename)
+	if err != nil {
+		return nil, err
+	}
+
+	return c.Parse(source)
+}
+
+// store writes an entry, first to a temporary file that is then renamed so concurrent readers never see partial entries.
+func (c *Cache) store(path string, block ast.Block) {
+	data, err := block.MarshalBinary()
+	if err != nil {
+		return
+	}
+
+	if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+		return
+	}
+
+	f, err := os.CreateTemp(filepath.Dir(path), ".tmp-*")
+	if err != nil {
+		return
+	}
+	defer os.Remove(f.Name())
+
+	_, err = f.Write(data)
+	if closeErr := f.Close(); err != nil || closeErr != nil {
+		return
+	}
+
+	if err := os.Rename(f.Name(), path); err != nil {
+		return
+	}
+
+	c.prune()
+}
+
+// prune removes the least recently used entries until they take at most MaxSize bytes.
+func (c *Cache) prune() {
+	if c.MaxSize <= 0 {
+		return
+	}
+
+	paths, err := filepath.Glob(filepath.Join(c.Dir, "*", "*.tmlb"))
+	if err != nil {
+		return
+	}
+
+	entries := []os.FileInfo{}
+	total := int64(0)
+	for _, path := range paths {
+		if info, err := os.Stat(path); err == nil {
+			entries = append(entries, info)
+			total += info.Size()
+		}
+	}
+
+	sort.Slice(entries, func(i, j int) bool {
+		return entries[i].ModTime().Before(entries[j].ModTime())
+	})
+
+	for _, info := range entries {
+		if total <= c.MaxSize {
+			break
+		}
+
+		name := info.Name()
+		if os.Remove(filepath.Join(c.Dir, name[:2], name)) == nil {
+			total -= info.Size()
+		}
+	}
+}
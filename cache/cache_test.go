@@ -0,0 +1,138 @@
+package cache_test
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/aziis98/textml/ast"
+	"github.com/aziis98/textml/cache"
+	"github.com/stretchr/testify/assert"
+)
+
+func entries(t *testing.T, dir string) []string {
+	t.Helper()
+
+	files, err := filepath.Glob(filepath.Join(dir, "*", "*.tmlb"))
+	assert.Nil(t, err)
+	return files
+}
+
+func TestParse(t *testing.T) {
+	dir := t.TempDir()
+	c := cache.New(dir)
+
+	source := []byte("#title{ Example }\nSome #bold{ text }")
+
+	doc, err := c.Parse(source)
+	assert.Nil(t, err)
+	assert.Len(t, entries(t, dir), 1)
+
+	cached, err := c.Parse(source)
+	assert.Nil(t, err)
+	assert.True(t, cached.Equal(doc))
+	assert.Equal(t, ast.Position{Line: 2, Column: 6}, cached[2].(*ast.ElementNode).Pos)
+
+	_, err = c.Parse([]byte("Other"))
+	assert.Nil(t, err)
+	assert.Len(t, entries(t, dir), 2)
+}
+
+func TestOutdatedEntry(t *testing.T) {
+	dir := t.TempDir()
+	c := cache.New(dir)
+
+	source := []byte("#br{}")
+
+	// an entry stored before the parser version was part of the key
+	h := sha256.Sum256([]byte(fmt.Sprintf("textml-ast-v%d\n%s", ast.BinaryVersion, source)))
+	old := hex.EncodeToString(h[:])
+	data, err := ast.Block{ast.T("stale")}.MarshalBinary()
+	assert.Nil(t, err)
+	assert.Nil(t, os.MkdirAll(filepath.Join(dir, old[:2]), 0o755))
+	assert.Nil(t, os.WriteFile(filepath.Join(dir, old[:2], old+".tmlb"), data, 0o644))
+
+	doc, err := c.Parse(source)
+	assert.Nil(t, err)
+	assert.True(t, ast.Block{ast.EN("br", ast.B())}.Equal(doc))
+	assert.Len(t, entries(t, dir), 2)
+}
+
+func TestCorruptedEntry(t *testing.T) {
+	dir := t.TempDir()
+	c := cache.New(dir)
+
+	source := []byte("#a{ b }")
+
+	doc, err := c.Parse(source)
+	assert.Nil(t, err)
+
+	entry := entries(t, dir)[0]
+	assert.Nil(t, os.WriteFile(entry, []byte("garbage"), 0o644))
+
+	reparsed, err := c.Parse(source)
+	assert.Nil(t, err)
+	assert.True(t, reparsed.Equal(doc))
+
+	// the entry is rewritten
+	data, err := os.ReadFile(entry)
+	assert.Nil(t, err)
+	assert.NotEqual(t, "garbage", string(data))
+}
+
+func TestParseErrorsAreNotCached(t *testing.T) {
+	dir := t.TempDir()
+
+	_, err := cache.New(dir).Parse([]byte("#a{ b }}"))
+	assert.NotNil(t, err)
+	assert.Empty(t, entries(t, dir))
+}
+
+func TestMaxSize(t *testing.T) {
+	dir := t.TempDir()
+	c := cache.New(dir)
+
+	_, err := c.Parse([]byte("#a{ first }"))
+	assert.Nil(t, err)
+	first := entries(t, dir)[0]
+
+	info, err := os.Stat(first)
+	assert.Nil(t, err)
+	c.MaxSize = 2 * info.Size()
+
+	// entries are removed from the least recently used one
+	old := time.Now().Add(-time.Hour)
+	assert.Nil(t, os.Chtimes(first, old, old))
+
+	_, err = c.Parse([]byte("#a{ other }"))
+	assert.Nil(t, err)
+	assert.Len(t, entries(t, dir), 2)
+
+	_, err = c.Parse([]byte("#a{ third }"))
+	assert.Nil(t, err)
+	assert.Len(t, entries(t, dir), 2)
+	assert.NotContains(t, entries(t, dir), first)
+}
+
+func TestDefaultDir(t *testing.T) {
+	t.Setenv("TEXTML_CACHE", "")
+	assert.Equal(t, "", cache.DefaultDir())
+
+	t.Setenv("TEXTML_CACHE", "off")
+	assert.Equal(t, "", cache.DefaultDir())
+
+	t.Setenv("TEXTML_CACHE", "/tmp/textml-cache")
+	assert.Equal(t, "/tmp/textml-cache", cache.DefaultDir())
+}
+
+func TestDisabled(t *testing.T) {
+	var c *cache.Cache
+
+	doc, err := c.Parse([]byte("#a{ b }"))
+	assert.Nil(t, err)
+	assert.Equal(t, "#a{ b }", doc.String())
+}
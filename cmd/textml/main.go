@@ -1,15 +1,23 @@
 package main
 
 import (
-	"bufio"
+	"encoding/json"
+	"errors"
 	"fmt"
+	"io"
 	"log"
 	"os"
 	"strings"
 
-	"github.com/aziis98/textml"
+	"github.com/aziis98/textml/ast"
+	"github.com/aziis98/textml/cache"
+	"github.com/aziis98/textml/diff"
+	"github.com/aziis98/textml/importer"
+	"github.com/aziis98/textml/merge"
 	"github.com/aziis98/textml/runtime/template"
 	"github.com/aziis98/textml/runtime/transpile"
+	"github.com/aziis98/textml/schema"
+	"github.com/aziis98/textml/schema/gogen"
 
 	flag "github.com/spf13/pflag"
 )
@@ -24,6 +32,12 @@ const usage = `usage: textml COMMAND ...
 Available commands:
     transpile   Used to read .tml files and convert them to other formats
     template    Use textml as a templating language
+    check       Validate .tml files against a schema
+    gen         Generate code from a schema, for now only "textml gen go"
+    diff        Show the structural differences between two .tml files
+    patch       Apply a patch produced by "textml diff -f json" to a .tml file
+    merge       Merge overlay .tml files into a base file
+    import      Convert HTML, Markdown and XML documents to .tml
 `
 
 func main() {
@@ -36,7 +50,7 @@ func main() {
 	case "transpile":
 		cmd := flag.NewFlagSet("transpile", flag.ExitOnError)
 		cmd.Usage = func() {
-			fmt.Printf("usage: textml transpile [-f FORMAT] FILE\n\n")
+			fmt.Printf("usage: textml transpile [--from tml|json|sexpr] [-f FORMAT] [-O KEY=VALUE]... FILE\n\n")
 			cmd.PrintDefaults()
 		}
 
@@ -47,6 +61,12 @@ func main() {
 		var format string
 		cmd.StringVarP(&format, "format", "f", "repr", `output format of the parsed file`)
 
+		var options map[string]string
+		cmd.StringToStringVarP(&options, "option", "O", nil, `format specific options as KEY=VALUE, for example -O inline=true`)
+
+		var from string
+		cmd.StringVar(&from, "from", "tml", `input format, "tml", "json" or "sexpr" (when reading json or sexpr the default output format is "tml")`)
+
 		var output string
 		cmd.StringVarP(&output, "output", "o", "-", `output file, "-" is stdout`)
 
@@ -66,8 +86,8 @@ func main() {
 
 		if listFormats {
 			fmt.Printf("Available formats:\n")
-			for format := range transpile.Registry {
-				fmt.Printf("- %q\n", format)
+			for _, format := range transpile.Formats() {
+				fmt.Printf("    %-14s %s\n", format.Name, format.Description)
 			}
 
 			os.Exit(0)
@@ -88,7 +108,11 @@ func main() {
 			log.Fatal(err)
 		}
 
-		commandTranspile(inputFile, outputFile, format)
+		if (from == "json" || from == "sexpr") && !cmd.Changed("format") {
+			format = "tml"
+		}
+
+		commandTranspile(inputFile, outputFile, from, format, options)
 	case "template":
 		cmd := flag.NewFlagSet("template", flag.ExitOnError)
 		cmd.Usage = func() {
@@ -129,40 +153,304 @@ func main() {
 		}
 
 		commandTemplate(inputFile, outputFile)
+	case "check":
+		cmd := flag.NewFlagSet("check", flag.ExitOnError)
+		cmd.Usage = func() {
+			fmt.Printf("usage: textml check --schema SCHEMA FILES...\n\n")
+			cmd.PrintDefaults()
+		}
+
+		var schemaFile string
+		cmd.StringVarP(&schemaFile, "schema", "s", "", `schema file used to validate the documents`)
+
+		var showHelp bool
+		cmd.BoolVarP(&showHelp, "help", "h", false, "Display help text")
+
+		if err := cmd.Parse(os.Args[2:]); err != nil {
+			if err != flag.ErrHelp {
+				log.Fatal(err)
+			}
+		}
+
+		if showHelp || schemaFile == "" || cmd.NArg() == 0 {
+			cmd.Usage()
+			os.Exit(0)
+		}
+
+		if !commandCheck(schemaFile, cmd.Args()) {
+			os.Exit(1)
+		}
+	case "gen":
+		cmd := flag.NewFlagSet("gen", flag.ExitOnError)
+		cmd.Usage = func() {
+			fmt.Printf("usage: textml gen go --schema SCHEMA [--package NAME] [--prefix PREFIX] [-o OUTPUT]\n\n")
+			cmd.PrintDefaults()
+		}
+
+		var schemaFile string
+		cmd.StringVarP(&schemaFile, "schema", "s", "", `schema file describing the elements`)
+
+		var packageName string
+		cmd.StringVarP(&packageName, "package", "p", "main", `name of the generated package`)
+
+		var prefix string
+		cmd.StringVar(&prefix, "prefix", "", `prefix of the generated names, to generate many schemas in the same package`)
+
+		var output string
+		cmd.StringVarP(&output, "output", "o", "-", `output file, "-" is stdout`)
+
+		var showHelp bool
+		cmd.BoolVarP(&showHelp, "help", "h", false, "Display help text")
+
+		if err := cmd.Parse(os.Args[2:]); err != nil {
+			if err != flag.ErrHelp {
+				log.Fatal(err)
+			}
+		}
+
+		if showHelp || schemaFile == "" || cmd.NArg() != 1 {
+			cmd.Usage()
+			os.Exit(0)
+		}
+		if cmd.Arg(0) != "go" {
+			log.Fatalf("invalid target language %q", cmd.Arg(0))
+		}
+
+		outputFile := os.Stdout
+		if output != "-" {
+			f, err := os.Create(output)
+			if err != nil {
+				log.Fatal(err)
+			}
+
+			outputFile = f
+		}
+
+		commandGenGo(schemaFile, packageName, prefix, outputFile)
+	case "diff":
+		cmd := flag.NewFlagSet("diff", flag.ExitOnError)
+		cmd.Usage = func() {
+			fmt.Printf("usage: textml diff [-f tml|html|json] OLD NEW\n\n")
+			cmd.PrintDefaults()
+		}
+
+		var format string
+		cmd.StringVarP(&format, "format", "f", "tml", `output format, "tml" (annotated document), "html" or "json" (patch)`)
+
+		var output string
+		cmd.StringVarP(&output, "output", "o", "-", `output file, "-" is stdout`)
+
+		var showHelp bool
+		cmd.BoolVarP(&showHelp, "help", "h", false, "Display help text")
+
+		if err := cmd.Parse(os.Args[2:]); err != nil {
+			if err != flag.ErrHelp {
+				log.Fatal(err)
+			}
+		}
+
+		if showHelp || cmd.NArg() != 2 {
+			cmd.Usage()
+			os.Exit(0)
+		}
+
+		outputFile := os.Stdout
+		if output != "-" {
+			f, err := os.Create(output)
+			if err != nil {
+				log.Fatal(err)
+			}
+
+			outputFile = f
+		}
+
+		commandDiff(cmd.Arg(0), cmd.Arg(1), format, outputFile)
+	case "patch":
+		cmd := flag.NewFlagSet("patch", flag.ExitOnError)
+		cmd.Usage = func() {
+			fmt.Printf("usage: textml patch [-o OUTPUT] FILE PATCH\n\n")
+			cmd.PrintDefaults()
+		}
+
+		var output string
+		cmd.StringVarP(&output, "output", "o", "-", `output file, "-" is stdout`)
+
+		var showHelp bool
+		cmd.BoolVarP(&showHelp, "help", "h", false, "Display help text")
+
+		if err := cmd.Parse(os.Args[2:]); err != nil {
+			if err != flag.ErrHelp {
+				log.Fatal(err)
+			}
+		}
+
+		if showHelp || cmd.NArg() != 2 {
+			cmd.Usage()
+			os.Exit(0)
+		}
+
+		outputFile := os.Stdout
+		if output != "-" {
+			f, err := os.Create(output)
+			if err != nil {
+				log.Fatal(err)
+			}
+
+			outputFile = f
+		}
+
+		commandPatch(cmd.Arg(0), cmd.Arg(1), outputFile)
+	case "merge":
+		cmd := flag.NewFlagSet("merge", flag.ExitOnError)
+		cmd.Usage = func() {
+			fmt.Printf("usage: textml merge [--repeated replace|append] [--append NAMES] [--replace NAMES] BASE OVERLAYS...\n\n")
+			cmd.PrintDefaults()
+		}
+
+		var repeated string
+		cmd.StringVar(&repeated, "repeated", "replace", `strategy for elements with a repeated name, "replace" or "append"`)
+
+		var appendNames []string
+		cmd.StringSliceVar(&appendNames, "append", nil, `names of elements always appended`)
+
+		var replaceNames []string
+		cmd.StringSliceVar(&replaceNames, "replace", nil, `names of elements always replaced instead of merged`)
+
+		var output string
+		cmd.StringVarP(&output, "output", "o", "-", `output file, "-" is stdout`)
+
+		var showHelp bool
+		cmd.BoolVarP(&showHelp, "help", "h", false, "Display help text")
+
+		if err := cmd.Parse(os.Args[2:]); err != nil {
+			if err != flag.ErrHelp {
+				log.Fatal(err)
+			}
+		}
+
+		if showHelp || cmd.NArg() < 2 {
+			cmd.Usage()
+			os.Exit(0)
+		}
+
+		opts := merge.Options{Strategies: map[string]merge.Strategy{}}
+
+		var err error
+		if opts.Repeated, err = merge.ParseStrategy(repeated); err != nil {
+			log.Fatal(err)
+		}
+		for _, name := range appendNames {
+			opts.Strategies[name] = merge.Append
+		}
+		for _, name := range replaceNames {
+			opts.Strategies[name] = merge.Replace
+		}
+
+		outputFile := os.Stdout
+		if output != "-" {
+			f, err := os.Create(output)
+			if err != nil {
+				log.Fatal(err)
+			}
+
+			outputFile = f
+		}
+
+		commandMerge(cmd.Args(), opts, outputFile)
+	case "import":
+		cmd := flag.NewFlagSet("import", flag.ExitOnError)
+		cmd.Usage = func() {
+			fmt.Printf("usage: textml import html|markdown|xml [--document] [--arg NAME] [-o OUTPUT] FILE\n\n")
+			cmd.PrintDefaults()
+		}
+
+		var document bool
+		cmd.BoolVar(&document, "document", false, `map common HTML tags to the document vocabulary like #title, #bold and #link, Markdown always uses it`)
+
+		var arg string
+		cmd.StringVar(&arg, "arg", "arg", `name of the XML elements holding arguments, as set with "transpile -f xml -O arg=NAME"`)
+
+		var output string
+		cmd.StringVarP(&output, "output", "o", "-", `output file, "-" is stdout`)
+
+		var showHelp bool
+		cmd.BoolVarP(&showHelp, "help", "h", false, "Display help text")
+
+		if err := cmd.Parse(os.Args[2:]); err != nil {
+			if err != flag.ErrHelp {
+				log.Fatal(err)
+			}
+		}
+
+		if showHelp || cmd.NArg() != 2 {
+			cmd.Usage()
+			os.Exit(0)
+		}
+
+		inputFile := os.Stdin
+		if cmd.Arg(1) != "-" {
+			f, err := os.Open(cmd.Arg(1))
+			if err != nil {
+				log.Fatal(err)
+			}
+			defer f.Close()
+
+			inputFile = f
+		}
+
+		outputFile := os.Stdout
+		if output != "-" {
+			f, err := os.Create(output)
+			if err != nil {
+				log.Fatal(err)
+			}
+
+			outputFile = f
+		}
+
+		commandImport(cmd.Arg(0), inputFile, outputFile, document, arg)
 	default:
 		log.Fatalf("invalid command %q", os.Args[1])
 	}
 }
 
-func commandTranspile(inputFile *os.File, outputFile *os.File, format string) {
-	doc, err := textml.ParseDocument(bufio.NewReader(inputFile))
+func commandTranspile(inputFile *os.File, outputFile *os.File, from, format string, options map[string]string) {
+	transpiler, err := transpile.New(format, options)
 	if err != nil {
 		log.Fatal(err)
 	}
 
-	transpiler := transpile.Registry[format]
+	var doc ast.Block
 
-	switch t := transpiler.(type) {
-	case transpile.StringTranspiler:
-		s, err := t.Transpile(doc)
-		if err != nil {
-			log.Fatal(err)
-		}
-		fmt.Fprint(outputFile, s)
-
-	case transpile.WriteTranspiler:
-		if err := t.Transpile(outputFile, doc); err != nil {
-			log.Fatal(err)
+	switch from {
+	case "tml":
+		var source []byte
+		if source, err = io.ReadAll(inputFile); err == nil {
+			doc, err = documentCache.Parse(source)
 		}
-
+	case "json":
+		doc, err = (&transpile.Json{}).Read(inputFile)
+	case "sexpr":
+		doc, err = (&transpile.Sexpr{}).Read(inputFile)
 	default:
-		panic(fmt.Errorf("invalid transpiler type: %T", transpiler))
+		log.Fatalf("invalid input format %q", from)
+	}
+	if err != nil {
+		log.Fatal(err)
 	}
 
+	if err := transpiler.Transpile(outputFile, doc); err != nil {
+		log.Fatal(err)
+	}
 }
 
 func commandTemplate(inputFile *os.File, outputFile *os.File) {
-	doc, err := textml.ParseDocument(bufio.NewReader(inputFile))
+	source, err := io.ReadAll(inputFile)
+	if err != nil {
+		log.Fatal(err)
+	}
+
+	doc, err := documentCache.Parse(source)
 	if err != nil {
 		log.Fatal(err)
 	}
@@ -183,3 +471,160 @@ func commandTemplate(inputFile *os.File, outputFile *os.File) {
 		log.Fatal(err)
 	}
 }
+
+// documentCache keeps parsed documents across runs when enabled with $TEXTML_CACHE, see [cache.DefaultDir]
+var documentCache = cache.New(cache.DefaultDir())
+
+func parseFile(filename string) (ast.Block, error) {
+	return documentCache.ParseFile(filename)
+}
+
+func commandGenGo(schemaFile, packageName, prefix string, outputFile *os.File) {
+	schemaDoc, err := parseFile(schemaFile)
+	if err != nil {
+		log.Fatal(err)
+	}
+
+	s, err := schema.Parse(schemaDoc)
+	if err != nil {
+		log.Fatalf("%s: %v", schemaFile, err)
+	}
+
+	if err := gogen.Generate(outputFile, s, gogen.Options{Package: packageName, Prefix: prefix}); err != nil {
+		log.Fatal(err)
+	}
+}
+
+func commandDiff(oldFile, newFile, format string, outputFile *os.File) {
+	old, err := parseFile(oldFile)
+	if err != nil {
+		log.Fatalf("%s: %v", oldFile, err)
+	}
+
+	new, err := parseFile(newFile)
+	if err != nil {
+		log.Fatalf("%s: %v", newFile, err)
+	}
+
+	switch format {
+	case "tml":
+		// annotated documents can always be printed as their text comes from parsed files
+		if err := ast.Print(outputFile, diff.Annotate(old, new)); err != nil {
+			log.Fatal(err)
+		}
+	case "html":
+		if err := diff.WriteHTML(outputFile, old, new); err != nil {
+			log.Fatal(err)
+		}
+	case "json":
+		enc := json.NewEncoder(outputFile)
+		enc.SetIndent("", "    ")
+		if err := enc.Encode(diff.Diff(old, new)); err != nil {
+			log.Fatal(err)
+		}
+	default:
+		log.Fatalf("invalid diff format %q", format)
+	}
+}
+
+func commandPatch(file, patchFile string, outputFile *os.File) {
+	doc, err := parseFile(file)
+	if err != nil {
+		log.Fatalf("%s: %v", file, err)
+	}
+
+	data, err := os.ReadFile(patchFile)
+	if err != nil {
+		log.Fatal(err)
+	}
+
+	var patch diff.Patch
+	if err := json.Unmarshal(data, &patch); err != nil {
+		log.Fatalf("%s: %v", patchFile, err)
+	}
+
+	result, err := diff.Apply(doc, patch)
+	if err != nil {
+		log.Fatal(err)
+	}
+
+	if err := ast.Print(outputFile, result); err != nil {
+		log.Fatal(err)
+	}
+}
+
+func commandMerge(files []string, opts merge.Options, outputFile *os.File) {
+	docs := []ast.Block{}
+	for _, file := range files {
+		doc, err := parseFile(file)
+		if err != nil {
+			log.Fatalf("%s: %v", file, err)
+		}
+
+		docs = append(docs, doc)
+	}
+
+	if err := ast.Print(outputFile, merge.MergeAll(opts, docs[0], docs[1:]...)); err != nil {
+		log.Fatal(err)
+	}
+}
+
+func commandImport(format string, inputFile, outputFile *os.File, document bool, arg string) {
+	var doc ast.Block
+	var err error
+
+	switch format {
+	case "html":
+		doc, err = importer.HTML(inputFile, importer.HTMLOptions{Document: document})
+	case "markdown", "md":
+		doc, err = importer.Markdown(inputFile, importer.MarkdownOptions{})
+	case "xml":
+		doc, err = importer.XML(inputFile, importer.XMLOptions{Arg: arg})
+	default:
+		log.Fatalf("invalid import format %q", format)
+	}
+	if err != nil {
+		log.Fatalf("%s: %v", inputFile.Name(), err)
+	}
+
+	if err := ast.Print(outputFile, doc); err != nil {
+		log.Fatal(err)
+	}
+	fmt.Fprintln(outputFile)
+}
+
+// commandCheck validates each file and prints all violations, returns false if some file is invalid.
+func commandCheck(schemaFile string, files []string) bool {
+	schemaDoc, err := parseFile(schemaFile)
+	if err != nil {
+		log.Fatal(err)
+	}
+
+	s, err := schema.Parse(schemaDoc)
+	if err != nil {
+		log.Fatalf("%s: %v", schemaFile, err)
+	}
+
+	valid := true
+	for _, file := range files {
+		doc, err := parseFile(file)
+		if err != nil {
+			fmt.Printf("%s: %v\n", file, err)
+			valid = false
+			continue
+		}
+
+		var violations schema.Violations
+		if err := s.Validate(doc); errors.As(err, &violations) {
+			for _, v := range violations {
+				fmt.Printf("%s:%v\n", file, v)
+			}
+			valid = false
+		} else if err != nil {
+			fmt.Printf("%s: %v\n", file, err)
+			valid = false
+		}
+	}
+
+	return valid
+}
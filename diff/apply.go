@@ -0,0 +1,143 @@
+package diff
+
+import (
+	"fmt"
+	"sort"
+
+	"github.com/aziis98/textml/ast"
+)
+
+type insertion struct {
+	index int
+	node  ast.Node
+}
+
+type applier struct {
+	removed map[string]bool
+	updates map[string]ast.Node
+	inserts map[string][]insertion
+}
+
+// Apply returns a new document obtained by applying the patch to the old one, the old document is not modified.
+func Apply(old ast.Block, patch Patch) (ast.Block, error) {
+	a := &applier{
+		removed: map[string]bool{},
+		updates: map[string]ast.Node{},
+		inserts: map[string][]insertion{},
+	}
+
+	for _, op := range patch {
+		if len(op.Path)%2 == 0 {
+			return nil, fmt.Errorf("invalid patch: %v does not refer to a node", op)
+		}
+
+		switch op.Kind {
+		case Delete:
+			if _, err := old.Resolve(op.Path); err != nil {
+				return nil, fmt.Errorf("invalid patch: %v: %w", op, err)
+			}
+
+			a.removed[op.Path.String()] = true
+
+		case Update:
+			if _, ok := op.Node.(*ast.TextNode); !ok {
+				return nil, fmt.Errorf("invalid patch: %v must hold a text node", op)
+			}
+			if n, err := old.Resolve(op.Path); err != nil {
+				return nil, fmt.Errorf("invalid patch: %v: %w", op, err)
+			} else if _, ok := n.(*ast.TextNode); !ok {
+				return nil, fmt.Errorf("invalid patch: %v does not refer to a text node", op)
+			}
+
+			a.updates[op.Path.String()] = op.Node
+
+		case Insert:
+			if op.Node == nil {
+				return nil, fmt.Errorf("invalid patch: %v has no node", op)
+			}
+
+			a.insert(op.Path, ast.CloneNode(op.Node))
+
+		case Move:
+			n, err := old.Resolve(op.From)
+			if err != nil {
+				return nil, fmt.Errorf("invalid patch: %v: %w", op, err)
+			}
+
+			a.removed[op.From.String()] = true
+			a.insert(op.Path, ast.CloneNode(n))
+
+		default:
+			return nil, fmt.Errorf("invalid patch: unknown operation %q", op.Kind)
+		}
+	}
+
+	for _, list := range a.inserts {
+		sort.SliceStable(list, func(i, j int) bool { return list[i].index < list[j].index })
+	}
+
+	return a.applyBlock(old, ast.Path{}, ast.Path{})
+}
+
+func (a *applier) insert(path ast.Path, n ast.Node) {
+	parent := path[:len(path)-1].String()
+	a.inserts[parent] = append(a.inserts[parent], insertion{path[len(path)-1], n})
+}
+
+// applyBlock rebuilds a block, the surviving old nodes fill the positions not taken by inserted nodes.
+func (a *applier) applyBlock(block ast.Block, oldPath, newPath ast.Path) (ast.Block, error) {
+	inserts := a.inserts[newPath.String()]
+	result := ast.Block{}
+
+	flush := func() {
+		for len(inserts) > 0 && inserts[0].index == len(result) {
+			result = append(result, inserts[0].node)
+			inserts = inserts[1:]
+		}
+	}
+
+	for i, n := range block {
+		if a.removed[child(oldPath, i).String()] {
+			continue
+		}
+
+		flush()
+
+		node, err := a.applyNode(n, child(oldPath, i), child(newPath, len(result)))
+		if err != nil {
+			return nil, err
+		}
+
+		result = append(result, node)
+	}
+
+	flush()
+	if len(inserts) > 0 {
+		return nil, fmt.Errorf("invalid patch: cannot insert at %v", child(newPath, inserts[0].index))
+	}
+
+	return result, nil
+}
+
+func (a *applier) applyNode(n ast.Node, oldPath, newPath ast.Path) (ast.Node, error) {
+	if updated, ok := a.updates[oldPath.String()]; ok {
+		return ast.CloneNode(updated), nil
+	}
+
+	elem, ok := n.(*ast.ElementNode)
+	if !ok {
+		return ast.CloneNode(n), nil
+	}
+
+	args := []ast.Block{}
+	for k, arg := range elem.Arguments {
+		block, err := a.applyBlock(arg, child(oldPath, k), child(newPath, k))
+		if err != nil {
+			return nil, err
+		}
+
+		args = append(args, block)
+	}
+
+	return &ast.ElementNode{Name: elem.Name, Arguments: args, Pos: elem.Pos}, nil
+}
@@ -0,0 +1,286 @@
+// Package diff computes structural differences between TextML documents.
+//
+// The difference between two blocks is a [Patch], a list of operations on nodes addressed by their [ast.Path]. Nodes are matched by equality first, then elements with the same name and text nodes are paired up and compared recursively, the remaining nodes become insertions and deletions (or moves when an equal node is deleted somewhere and inserted elsewhere).
+package diff
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/aziis98/textml/ast"
+)
+
+// child returns the path p followed by the given indices.
+func child(p ast.Path, i ...int) ast.Path {
+	return append(append(ast.Path{}, p...), i...)
+}
+
+// OpKind is the kind of a patch operation.
+type OpKind string
+
+const (
+	// Insert adds Node at Path (in the new document)
+	Insert OpKind = "insert"
+	// Delete removes the node at Path (in the old document)
+	Delete OpKind = "delete"
+	// Update replaces the text node at Path (in the old document) with Node
+	Update OpKind = "update"
+	// Move moves the node at From (in the old document) to Path (in the new document)
+	Move OpKind = "move"
+)
+
+// Op is a single patch operation. Paths of deleted, updated and moved nodes refer to the old document while paths of inserted and moved nodes refer to the new document.
+type Op struct {
+	Kind OpKind
+	Path ast.Path
+	From ast.Path
+	Node ast.Node
+}
+
+func (op Op) String() string {
+	switch op.Kind {
+	case Insert:
+		if op.Node == nil {
+			return fmt.Sprintf("insert %v", op.Path)
+		}
+
+		return fmt.Sprintf("insert %v %v", op.Path, op.Node)
+	case Delete:
+		return fmt.Sprintf("delete %v", op.Path)
+	case Update:
+		// invalid patches can hold other nodes, these are rejected by [Apply]
+		if text, ok := op.Node.(*ast.TextNode); ok {
+			return fmt.Sprintf("update %v %q", op.Path, text.Text)
+		}
+
+		return fmt.Sprintf("update %v %v", op.Path, op.Node)
+	case Move:
+		return fmt.Sprintf("move %v %v", op.From, op.Path)
+	default:
+		return fmt.Sprintf("invalid operation %q", op.Kind)
+	}
+}
+
+// Patch is a list of operations transforming a document into another.
+type Patch []Op
+
+// changeKind is the kind of a change in a block
+type changeKind int
+
+const (
+	keepChange changeKind = iota
+	deleteChange
+	insertChange
+	updateChange
+	moveOutChange
+	moveInChange
+)
+
+// change is a node of a diff tree, updates of elements hold the diffs of their arguments.
+type change struct {
+	kind      changeKind
+	oldPath   ast.Path
+	newPath   ast.Path
+	oldNode   ast.Node
+	newNode   ast.Node
+	args      [][]*change
+	movedTwin *change
+}
+
+// Diff computes the patch transforming the old block into the new one.
+func Diff(old, new ast.Block) Patch {
+	return patchOf(diffTree(old, new))
+}
+
+// diffTree computes the diff tree of two documents and detects moves.
+func diffTree(old, new ast.Block) []*change {
+	changes := diffBlocks(old, new, ast.Path{}, ast.Path{})
+	detectMoves(changes)
+	return changes
+}
+
+func diffBlocks(old, new ast.Block, oldPath, newPath ast.Path) []*change {
+	changes := []*change{}
+
+	anchors := lcs(len(old), len(new), func(i, j int) bool {
+		return ast.EqualNode(old[i], new[j])
+	})
+	anchors = append(anchors, [2]int{len(old), len(new)})
+
+	i, j := 0, 0
+	for _, anchor := range anchors {
+		changes = append(changes, diffGap(old, new, oldPath, newPath, i, anchor[0], j, anchor[1])...)
+
+		if anchor[0] < len(old) {
+			changes = append(changes, &change{
+				kind:    keepChange,
+				oldPath: child(oldPath, anchor[0]),
+				newPath: child(newPath, anchor[1]),
+				oldNode: old[anchor[0]],
+				newNode: new[anchor[1]],
+			})
+		}
+
+		i, j = anchor[0]+1, anchor[1]+1
+	}
+
+	return changes
+}
+
+// pairable reports whether two different nodes should be compared as an update instead of being deleted and inserted.
+func pairable(a, b ast.Node) bool {
+	switch a := a.(type) {
+	case *ast.TextNode:
+		_, ok := b.(*ast.TextNode)
+		return ok
+	case *ast.ElementNode:
+		b, ok := b.(*ast.ElementNode)
+		return ok && a.Name == b.Name && len(a.Arguments) == len(b.Arguments)
+	}
+
+	return false
+}
+
+// diffGap diffs the old nodes in [oldFrom, oldTo) against the new nodes in [newFrom, newTo), none of these are equal.
+func diffGap(old, new ast.Block, oldPath, newPath ast.Path, oldFrom, oldTo, newFrom, newTo int) []*change {
+	changes := []*change{}
+
+	pairs := lcs(oldTo-oldFrom, newTo-newFrom, func(i, j int) bool {
+		return pairable(old[oldFrom+i], new[newFrom+j])
+	})
+	pairs = append(pairs, [2]int{oldTo - oldFrom, newTo - newFrom})
+
+	i, j := oldFrom, newFrom
+	for _, pair := range pairs {
+		pi, pj := oldFrom+pair[0], newFrom+pair[1]
+
+		for ; i < pi; i++ {
+			changes = append(changes, &change{kind: deleteChange, oldPath: child(oldPath, i), oldNode: old[i]})
+		}
+		for ; j < pj; j++ {
+			changes = append(changes, &change{kind: insertChange, newPath: child(newPath, j), newNode: new[j]})
+		}
+
+		if pi < oldTo {
+			c := &change{
+				kind:    updateChange,
+				oldPath: child(oldPath, pi),
+				newPath: child(newPath, pj),
+				oldNode: old[pi],
+				newNode: new[pj],
+			}
+
+			if oldElem, ok := old[pi].(*ast.ElementNode); ok {
+				newElem := new[pj].(*ast.ElementNode)
+				for k := range oldElem.Arguments {
+					c.args = append(c.args, diffBlocks(
+						oldElem.Arguments[k], newElem.Arguments[k],
+						child(c.oldPath, k), child(c.newPath, k),
+					))
+				}
+			}
+
+			changes = append(changes, c)
+		}
+
+		i, j = pi+1, pj+1
+	}
+
+	return changes
+}
+
+// detectMoves turns a deletion and an insertion of equal nodes anywhere in the tree into a move, blank text is never moved.
+func detectMoves(changes []*change) {
+	deletes, inserts := []*change{}, []*change{}
+
+	var collect func(changes []*change)
+	collect = func(changes []*change) {
+		for _, c := range changes {
+			switch c.kind {
+			case deleteChange:
+				deletes = append(deletes, c)
+			case insertChange:
+				inserts = append(inserts, c)
+			case updateChange:
+				for _, arg := range c.args {
+					collect(arg)
+				}
+			}
+		}
+	}
+	collect(changes)
+
+	for _, ins := range inserts {
+		if text, ok := ins.newNode.(*ast.TextNode); ok && strings.TrimSpace(text.Text) == "" {
+			continue
+		}
+
+		for _, del := range deletes {
+			if del.kind == deleteChange && ast.EqualNode(del.oldNode, ins.newNode) {
+				del.kind, ins.kind = moveOutChange, moveInChange
+				del.movedTwin, ins.movedTwin = ins, del
+				break
+			}
+		}
+	}
+}
+
+func patchOf(changes []*change) Patch {
+	patch := Patch{}
+
+	for _, c := range changes {
+		switch c.kind {
+		case deleteChange:
+			patch = append(patch, Op{Kind: Delete, Path: c.oldPath})
+		case insertChange:
+			patch = append(patch, Op{Kind: Insert, Path: c.newPath, Node: c.newNode})
+		case moveInChange:
+			patch = append(patch, Op{Kind: Move, Path: c.newPath, From: c.movedTwin.oldPath})
+		case updateChange:
+			if _, ok := c.newNode.(*ast.TextNode); ok {
+				patch = append(patch, Op{Kind: Update, Path: c.oldPath, Node: c.newNode})
+			}
+			for _, arg := range c.args {
+				patch = append(patch, patchOf(arg)...)
+			}
+		}
+	}
+
+	return patch
+}
+
+// lcs returns the index pairs of a longest common subsequence of two sequences of lengths n and m.
+func lcs(n, m int, eq func(i, j int) bool) [][2]int {
+	table := make([][]int, n+1)
+	for i := range table {
+		table[i] = make([]int, m+1)
+	}
+
+	for i := n - 1; i >= 0; i-- {
+		for j := m - 1; j >= 0; j-- {
+			if eq(i, j) {
+				table[i][j] = table[i+1][j+1] + 1
+			} else if table[i+1][j] >= table[i][j+1] {
+				table[i][j] = table[i+1][j]
+			} else {
+				table[i][j] = table[i][j+1]
+			}
+		}
+	}
+
+	pairs := [][2]int{}
+	for i, j := 0, 0; i < n && j < m; {
+		switch {
+		case eq(i, j):
+			pairs = append(pairs, [2]int{i, j})
+			i++
+			j++
+		case table[i+1][j] >= table[i][j+1]:
+			i++
+		default:
+			j++
+		}
+	}
+
+	return pairs
+}
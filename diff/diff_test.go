@@ -0,0 +1,143 @@
+package diff_test
+
+import (
+	"encoding/json"
+	"strings"
+	"testing"
+
+	"github.com/aziis98/textml"
+	"github.com/aziis98/textml/ast"
+	"github.com/aziis98/textml/diff"
+	"github.com/stretchr/testify/assert"
+)
+
+func parse(t *testing.T, source string) ast.Block {
+	t.Helper()
+
+	block, err := textml.ParseDocument(strings.NewReader(source))
+	assert.Nil(t, err)
+	return block
+}
+
+var roundTripCases = []struct{ old, new string }{
+	{"same", "same"},
+	{"Hello", "Hello, world!"},
+	{"#a{ x } y #b{ z }", "y #b{ z } #a{ x }"},
+	{"#title{ Example }\nSome #bold{ bold } text", "#title{ Another example }\nSome #italic{ italic } text"},
+	{"#link{ a }{ b }", "#link{ a }{ c }#link{ a }{ b }"},
+	{"#list{ #item{ one } #item{ two } #item{ three } }", "#list{ #item{ three } #item{ one } #item{ 2 } }"},
+	{"#a{ #b{ #c{ deep } } }", "#c{ deep } #a{ #b{ x } }"},
+	{"#x{ 1 }#x{ 2 }#x{ 3 }", "#y{ 1 }"},
+}
+
+func TestDiffApply(t *testing.T) {
+	for _, tc := range roundTripCases {
+		old, new := parse(t, tc.old), parse(t, tc.new)
+
+		patch := diff.Diff(old, new)
+		result, err := diff.Apply(old, patch)
+		assert.Nil(t, err, "%q -> %q", tc.old, tc.new)
+		assert.True(t, result.Equal(new), "%q -> %q: got %q with patch %v", tc.old, tc.new, result.String(), patch)
+
+		// the old document is unchanged
+		assert.True(t, old.Equal(parse(t, tc.old)))
+	}
+}
+
+func TestDiffOperations(t *testing.T) {
+	old := parse(t, "#title{ Example }\nSome text #note{ remove me }")
+	new := parse(t, "#title{ Example! }\nSome text")
+
+	assert.Equal(t, []string{
+		`update /0/args/0/0 "Example!"`,
+		`update /1 "\nSome text"`,
+		`delete /2`,
+	}, opStrings(diff.Diff(old, new)))
+
+	assert.Empty(t, diff.Diff(old, old))
+}
+
+func TestDiffMoves(t *testing.T) {
+	old := parse(t, "#a{ x #b{ moved } } text")
+	new := parse(t, "#a{ x } text #b{ moved }")
+
+	assert.Equal(t, []string{
+		"move /0/args/0/1 /2",
+	}, filterKind(diff.Diff(old, new), diff.Move))
+}
+
+func TestAnnotate(t *testing.T) {
+	old := parse(t, "#title{ Example }\nSome #bold{ bold } text #note{ old }")
+	new := parse(t, "#title{ An example }\nSome #bold{ bold } text #tip{ new }")
+
+	assert.Equal(t,
+		"#title{ #diff.del{ E }#diff.ins{ An e }xample }\nSome #bold{ bold } text #diff.del{ #note{ old } }#diff.ins{ #tip{ new } }",
+		diff.Annotate(old, new).String(),
+	)
+}
+
+func TestWriteHTML(t *testing.T) {
+	old := parse(t, "#a{ x < y } #b{ gone }")
+	new := parse(t, "#a{ x > y }")
+
+	sb := &strings.Builder{}
+	assert.Nil(t, diff.WriteHTML(sb, old, new))
+	assert.Equal(t,
+		`<pre class="textml-diff">#a{ x <del>&lt;</del><ins>&gt;</ins> y }<del> </del><del>#b{ gone }</del></pre>`+"\n",
+		sb.String(),
+	)
+}
+
+func TestPatchJSON(t *testing.T) {
+	old := parse(t, "#a{ x } y #b{ z }")
+	new := parse(t, "y #b{ z!! } #a{ x } #c{ w }")
+
+	patch := diff.Diff(old, new)
+
+	data, err := json.Marshal(patch)
+	assert.Nil(t, err)
+
+	var decoded diff.Patch
+	assert.Nil(t, json.Unmarshal(data, &decoded))
+
+	result, err := diff.Apply(old, decoded)
+	assert.Nil(t, err)
+	assert.True(t, result.Equal(new))
+}
+
+func TestApplyInvalid(t *testing.T) {
+	old := parse(t, "#a{ x }")
+
+	_, err := diff.Apply(old, diff.Patch{{Kind: diff.Delete, Path: ast.Path{3}}})
+	assert.NotNil(t, err)
+
+	_, err = diff.Apply(old, diff.Patch{{Kind: diff.Insert, Path: ast.Path{5}, Node: ast.T("x")}})
+	assert.NotNil(t, err)
+
+	var patch diff.Patch
+	assert.Nil(t, json.Unmarshal([]byte(`[{"op":"insert","path":"/0"}]`), &patch))
+	_, err = diff.Apply(old, patch)
+	assert.EqualError(t, err, "invalid patch: insert /0 has no node")
+
+	assert.Nil(t, json.Unmarshal([]byte(`[{"op":"update","path":"/0"}]`), &patch))
+	_, err = diff.Apply(old, patch)
+	assert.EqualError(t, err, "invalid patch: update /0 <nil> must hold a text node")
+}
+
+func opStrings(patch diff.Patch) []string {
+	result := []string{}
+	for _, op := range patch {
+		result = append(result, op.String())
+	}
+	return result
+}
+
+func filterKind(patch diff.Patch, kind diff.OpKind) []string {
+	result := []string{}
+	for _, op := range patch {
+		if op.Kind == kind {
+			result = append(result, op.String())
+		}
+	}
+	return result
+}
@@ -0,0 +1,51 @@
+package diff
+
+import (
+	"encoding/json"
+	"fmt"
+
+	"github.com/aziis98/textml/ast"
+)
+
+// jsonOp is the JSON representation of an [Op], paths are strings like "/3/args/1/0" and nodes use the format described in "docs/json.md"
+type jsonOp struct {
+	Op   OpKind          `json:"op"`
+	Path ast.Path        `json:"path"`
+	From ast.Path        `json:"from,omitempty"`
+	Node json.RawMessage `json:"node,omitempty"`
+}
+
+func (op Op) MarshalJSON() ([]byte, error) {
+	v := jsonOp{Op: op.Kind, Path: op.Path, From: op.From}
+
+	if op.Node != nil {
+		node, err := json.Marshal(op.Node)
+		if err != nil {
+			return nil, err
+		}
+
+		v.Node = node
+	}
+
+	return json.Marshal(v)
+}
+
+func (op *Op) UnmarshalJSON(data []byte) error {
+	var v jsonOp
+	if err := json.Unmarshal(data, &v); err != nil {
+		return err
+	}
+
+	*op = Op{Kind: v.Op, Path: v.Path, From: v.From}
+
+	if len(v.Node) > 0 {
+		var block ast.Block
+		if err := json.Unmarshal([]byte("["+string(v.Node)+"]"), &block); err != nil {
+			return fmt.Errorf("%s operation: %w", v.Op, err)
+		}
+
+		op.Node = block[0]
+	}
+
+	return nil
+}
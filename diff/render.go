@@ -0,0 +1,144 @@
+package diff
+
+import (
+	"fmt"
+	"html"
+	"io"
+	"strings"
+
+	"github.com/aziis98/textml/ast"
+)
+
+// Annotate returns the new document where changes are wrapped in #diff.del, #diff.ins, #diff.moved-from and #diff.moved-to elements. Updated text nodes only wrap the part that changed.
+func Annotate(old, new ast.Block) ast.Block {
+	return annotateBlock(diffTree(old, new))
+}
+
+func annotateBlock(changes []*change) ast.Block {
+	block := ast.Block{}
+
+	for _, c := range changes {
+		switch c.kind {
+		case keepChange:
+			block = append(block, ast.CloneNode(c.newNode))
+		case deleteChange:
+			block = append(block, ast.E("diff.del", ast.CloneNode(c.oldNode)))
+		case insertChange:
+			block = append(block, ast.E("diff.ins", ast.CloneNode(c.newNode)))
+		case moveOutChange:
+			block = append(block, ast.E("diff.moved-from", ast.CloneNode(c.oldNode)))
+		case moveInChange:
+			block = append(block, ast.E("diff.moved-to", ast.CloneNode(c.newNode)))
+		case updateChange:
+			if oldText, ok := c.oldNode.(*ast.TextNode); ok {
+				prefix, removed, added, suffix := splitTextChange(oldText.Text, c.newNode.(*ast.TextNode).Text)
+
+				for _, n := range []ast.Node{
+					ast.T(prefix), ast.E("diff.del", ast.T(removed)), ast.E("diff.ins", ast.T(added)), ast.T(suffix),
+				} {
+					if !isEmpty(n) {
+						block = append(block, n)
+					}
+				}
+				continue
+			}
+
+			elem := c.newNode.(*ast.ElementNode)
+			args := []ast.Block{}
+			for _, arg := range c.args {
+				args = append(args, annotateBlock(arg))
+			}
+
+			block = append(block, ast.EN(elem.Name, args...))
+		}
+	}
+
+	return block
+}
+
+// isEmpty reports whether n is an empty text node or an element wrapping an empty text node.
+func isEmpty(n ast.Node) bool {
+	switch n := n.(type) {
+	case *ast.TextNode:
+		return n.Text == ""
+	case *ast.ElementNode:
+		return isEmpty(n.Arguments[0][0])
+	}
+
+	return false
+}
+
+// splitTextChange splits two strings in their common prefix and suffix and the differing parts.
+func splitTextChange(old, new string) (prefix, removed, added, suffix string) {
+	o, n := []rune(old), []rune(new)
+
+	i := 0
+	for i < len(o) && i < len(n) && o[i] == n[i] {
+		i++
+	}
+
+	j := 0
+	for j < len(o)-i && j < len(n)-i && o[len(o)-1-j] == n[len(n)-1-j] {
+		j++
+	}
+
+	return string(o[:i]), string(o[i : len(o)-j]), string(n[i : len(n)-j]), string(o[len(o)-j:])
+}
+
+// WriteHTML writes the source of the new document as HTML inside a <pre> element, deleted and inserted parts are wrapped in <del> and <ins> elements (moved nodes also have the "moved" class).
+func WriteHTML(w io.Writer, old, new ast.Block) error {
+	sb := &strings.Builder{}
+
+	sb.WriteString(`<pre class="textml-diff">`)
+	writeHTMLBlock(sb, diffTree(old, new), 1)
+	sb.WriteString("</pre>\n")
+
+	_, err := io.WriteString(w, sb.String())
+	return err
+}
+
+func writeHTMLBlock(sb *strings.Builder, changes []*change, depth int) {
+	source := func(n ast.Node) string {
+		return html.EscapeString(ast.Block{n}.String())
+	}
+
+	for _, c := range changes {
+		switch c.kind {
+		case keepChange:
+			sb.WriteString(source(c.newNode))
+		case deleteChange:
+			fmt.Fprintf(sb, "<del>%s</del>", source(c.oldNode))
+		case insertChange:
+			fmt.Fprintf(sb, "<ins>%s</ins>", source(c.newNode))
+		case moveOutChange:
+			fmt.Fprintf(sb, `<del class="moved">%s</del>`, source(c.oldNode))
+		case moveInChange:
+			fmt.Fprintf(sb, `<ins class="moved">%s</ins>`, source(c.newNode))
+		case updateChange:
+			if oldText, ok := c.oldNode.(*ast.TextNode); ok {
+				prefix, removed, added, suffix := splitTextChange(oldText.Text, c.newNode.(*ast.TextNode).Text)
+
+				sb.WriteString(html.EscapeString(prefix))
+				if removed != "" {
+					fmt.Fprintf(sb, "<del>%s</del>", html.EscapeString(removed))
+				}
+				if added != "" {
+					fmt.Fprintf(sb, "<ins>%s</ins>", html.EscapeString(added))
+				}
+				sb.WriteString(html.EscapeString(suffix))
+				continue
+			}
+
+			elem := c.newNode.(*ast.ElementNode)
+			sb.WriteString(html.EscapeString("#" + elem.Name))
+
+			for k, arg := range c.args {
+				argDepth := ast.BraceDepth(elem.Arguments[k], depth)
+
+				sb.WriteString(strings.Repeat("{", argDepth) + " ")
+				writeHTMLBlock(sb, arg, argDepth)
+				sb.WriteString(" " + strings.Repeat("}", argDepth))
+			}
+		}
+	}
+}
@@ -0,0 +1,447 @@
+package html
+
+import (
+	"strings"
+)
+
+//
+// Traversal
+//
+
+// Matcher selects elements in queries like [FindAll].
+type Matcher func(elem *Element) bool
+
+// ByTag matches elements with the given tag name, ignoring case.
+func ByTag(tagName string) Matcher {
+	return func(elem *Element) bool {
+		return strings.EqualFold(elem.TagName, tagName)
+	}
+}
+
+// ByClass matches elements having the class in their class list.
+func ByClass(class string) Matcher {
+	return func(elem *Element) bool {
+		return elem.HasClass(class)
+	}
+}
+
+// ByID matches the elements with the given id.
+func ByID(id string) Matcher {
+	return ByAttribute("id", id)
+}
+
+// ByAttribute matches elements with an attribute having exactly the given value.
+func ByAttribute(name, value string) Matcher {
+	return func(elem *Element) bool {
+		v, ok := elem.Attr(name)
+		return ok && v == value
+	}
+}
+
+// HasAttribute matches elements with the attribute, whatever its value.
+func HasAttribute(name string) Matcher {
+	return func(elem *Element) bool {
+		return elem.HasAttr(name)
+	}
+}
+
+// And matches the elements matched by all the matchers.
+func And(matchers ...Matcher) Matcher {
+	return func(elem *Element) bool {
+		for _, m := range matchers {
+			if !m(elem) {
+				return false
+			}
+		}
+
+		return true
+	}
+}
+
+// Walk visits the nodes and their descendants in document order, when fn returns false the children of the node are skipped.
+func Walk(nodes []Node, fn func(n Node) bool) {
+	for _, n := range nodes {
+		if !fn(n) {
+			continue
+		}
+
+		if elem, ok := asElement(n); ok {
+			Walk(elem.Children, fn)
+		}
+	}
+}
+
+// FindAll returns the elements among the nodes and their descendants selected by the matcher, in document order.
+func FindAll(nodes []Node, m Matcher) []*Element {
+	result := []*Element{}
+	Walk(nodes, func(n Node) bool {
+		if elem, ok := n.(*Element); ok && m(elem) {
+			result = append(result, elem)
+		}
+
+		return true
+	})
+
+	return result
+}
+
+// Find returns the first element selected by the matcher among the nodes and their descendants, or nil if there is none.
+func Find(nodes []Node, m Matcher) *Element {
+	var result *Element
+	Walk(nodes, func(n Node) bool {
+		if result != nil {
+			return false
+		}
+		if elem, ok := n.(*Element); ok && m(elem) {
+			result = elem
+			return false
+		}
+
+		return true
+	})
+
+	return result
+}
+
+// FindAll returns the descendants of the element selected by the matcher.
+func (e *Element) FindAll(m Matcher) []*Element {
+	return FindAll(e.Children, m)
+}
+
+// Find returns the first descendant of the element selected by the matcher, or nil if there is none.
+func (e *Element) Find(m Matcher) *Element {
+	return Find(e.Children, m)
+}
+
+// Closest returns the element itself or its nearest ancestor selected by the matcher, or nil if there is none.
+func (e *Element) Closest(m Matcher) *Element {
+	for elem := e; elem != nil; elem = elem.Parent {
+		if m(elem) {
+			return elem
+		}
+	}
+
+	return nil
+}
+
+// SetParents sets the parent links of the elements in the trees of the nodes, this is needed only for elements built without [NewElementNode] or whose children were changed directly. The top level elements get a nil parent.
+func SetParents(nodes []Node) {
+	for _, n := range nodes {
+		if elem, ok := n.(*Element); ok {
+			elem.Parent = nil
+		}
+	}
+
+	Walk(nodes, func(n Node) bool {
+		if elem, ok := n.(*Element); ok {
+			elem.adopt(elem.Children...)
+		}
+
+		return true
+	})
+}
+
+// TextContent returns the text of the element and of its descendants.
+func (e *Element) TextContent() string {
+	sb := &strings.Builder{}
+	Walk(e.Children, func(n Node) bool {
+		switch n := n.(type) {
+		case *Text:
+			sb.WriteString(n.Value)
+		case Text:
+			sb.WriteString(n.Value)
+		}
+
+		return true
+	})
+
+	return sb.String()
+}
+
+//
+// Mutation
+//
+
+// adopt sets the element as the parent of the element nodes.
+func (e *Element) adopt(nodes ...Node) {
+	for _, n := range nodes {
+		if child, ok := n.(*Element); ok {
+			child.Parent = e
+		}
+	}
+}
+
+// detach removes element nodes from their current parent, so that a node is never the child of two elements.
+func detach(nodes ...Node) {
+	for _, n := range nodes {
+		if child, ok := n.(*Element); ok {
+			child.Remove()
+		}
+	}
+}
+
+// sameNode reports whether a and b are the same node, elements are compared by pointer.
+func sameNode(a, b Node) bool {
+	if _, ok := a.(Element); ok {
+		return false
+	}
+	if _, ok := b.(Element); ok {
+		return false
+	}
+
+	return a == b
+}
+
+// IndexOf returns the index of the child, or -1 if the node is not a child of the element.
+func (e *Element) IndexOf(child Node) int {
+	for i, n := range e.Children {
+		if sameNode(n, child) {
+			return i
+		}
+	}
+
+	return -1
+}
+
+// InsertChildren inserts the nodes before the child at index i, an index equal to the number of children appends them. Elements are first removed from their previous parent, when some of them were children of this element the index refers to the remaining children.
+func (e *Element) InsertChildren(i int, nodes ...Node) {
+	detach(nodes...)
+	if i > len(e.Children) {
+		i = len(e.Children)
+	}
+
+	children := append([]Node{}, e.Children[:i]...)
+	children = append(children, nodes...)
+	e.Children = append(children, e.Children[i:]...)
+
+	e.adopt(nodes...)
+}
+
+// AppendChild adds the nodes at the end of the children.
+func (e *Element) AppendChild(nodes ...Node) {
+	detach(nodes...)
+	e.Children = append(e.Children, nodes...)
+	e.adopt(nodes...)
+}
+
+// PrependChild adds the nodes at the start of the children.
+func (e *Element) PrependChild(nodes ...Node) {
+	e.InsertChildren(0, nodes...)
+}
+
+// InsertBefore inserts the nodes before the reference child and reports whether it was found.
+func (e *Element) InsertBefore(ref Node, nodes ...Node) bool {
+	detach(nodes...)
+
+	i := e.IndexOf(ref)
+	if i < 0 {
+		return false
+	}
+
+	e.InsertChildren(i, nodes...)
+	return true
+}
+
+// InsertAfter inserts the nodes after the reference child and reports whether it was found.
+func (e *Element) InsertAfter(ref Node, nodes ...Node) bool {
+	detach(nodes...)
+
+	i := e.IndexOf(ref)
+	if i < 0 {
+		return false
+	}
+
+	e.InsertChildren(i+1, nodes...)
+	return true
+}
+
+// RemoveChild removes the child and reports whether it was found.
+func (e *Element) RemoveChild(child Node) bool {
+	i := e.IndexOf(child)
+	if i < 0 {
+		return false
+	}
+
+	e.Children = append(e.Children[:i:i], e.Children[i+1:]...)
+	if elem, ok := child.(*Element); ok {
+		elem.Parent = nil
+	}
+
+	return true
+}
+
+// ReplaceChild puts the nodes in place of the child and reports whether it was found.
+func (e *Element) ReplaceChild(child Node, nodes ...Node) bool {
+	detach(nodes...)
+
+	i := e.IndexOf(child)
+	if i < 0 {
+		return false
+	}
+
+	e.RemoveChild(child)
+	e.InsertChildren(i, nodes...)
+	return true
+}
+
+// Remove removes the element from its parent, top level elements have no parent and must be removed from their list by the caller.
+func (e *Element) Remove() {
+	if e.Parent != nil {
+		e.Parent.RemoveChild(e)
+	}
+}
+
+// ReplaceWith puts the nodes in place of the element in its parent and reports whether the element has a parent.
+func (e *Element) ReplaceWith(nodes ...Node) bool {
+	if e.Parent == nil {
+		return false
+	}
+
+	return e.Parent.ReplaceChild(e, nodes...)
+}
+
+// Wrap puts the wrapper in place of the element and the element at the end of the children of the wrapper, for example to wrap tables in a scrollable <div>. Elements without a parent are only appended to the wrapper, use the [Wrap] function for top level elements.
+func (e *Element) Wrap(wrapper *Element) {
+	e.ReplaceWith(wrapper)
+	wrapper.AppendChild(e)
+}
+
+// ReplaceWith is like [Element.ReplaceWith] but also works for the top level element of a list of nodes, it returns the nodes with the replacement. Elements that are neither top level nor have a parent are left unchanged.
+func ReplaceWith(nodes []Node, e *Element, replacements ...Node) []Node {
+	if e.Parent != nil {
+		e.ReplaceWith(replacements...)
+		return nodes
+	}
+
+	for i, n := range nodes {
+		if sameNode(n, e) {
+			detach(replacements...)
+
+			result := append([]Node{}, nodes[:i]...)
+			result = append(result, replacements...)
+			return append(result, nodes[i+1:]...)
+		}
+	}
+
+	return nodes
+}
+
+// Wrap is like [Element.Wrap] but also works for the top level elements of a list of nodes, it returns the nodes with the wrapper.
+func Wrap(nodes []Node, e *Element, wrapper *Element) []Node {
+	nodes = ReplaceWith(nodes, e, wrapper)
+	wrapper.AppendChild(e)
+	return nodes
+}
+
+//
+// Attributes
+//
+
+// Attr returns the value of the attribute and whether it is present, attributes without a value give an empty string.
+func (e *Element) Attr(name string) (string, bool) {
+	switch attr := e.Attributes[name].(type) {
+	case *Attribute:
+		return attr.Value, true
+	case Attribute:
+		return attr.Value, true
+	case nil:
+		return "", false
+	default:
+		return "", true
+	}
+}
+
+// HasAttr reports whether the element has the attribute.
+func (e *Element) HasAttr(name string) bool {
+	_, ok := e.Attributes[name]
+	return ok
+}
+
+// SetAttr sets the value of the attribute.
+func (e *Element) SetAttr(name, value string) {
+	if e.Attributes == nil {
+		e.Attributes = AttributeMap{}
+	}
+
+	e.Attributes[name] = &Attribute{Value: value}
+}
+
+// SetBoolAttr adds or removes an attribute without a value, like "disabled" or "checked".
+func (e *Element) SetBoolAttr(name string, present bool) {
+	if !present {
+		e.RemoveAttr(name)
+		return
+	}
+	if e.Attributes == nil {
+		e.Attributes = AttributeMap{}
+	}
+
+	e.Attributes[name] = EmptyAttribute{}
+}
+
+// RemoveAttr removes the attribute.
+func (e *Element) RemoveAttr(name string) {
+	delete(e.Attributes, name)
+}
+
+// Classes returns the class list of the element.
+func (e *Element) Classes() []string {
+	class, _ := e.Attr("class")
+	return strings.Fields(class)
+}
+
+// HasClass reports whether the class is in the class list of the element.
+func (e *Element) HasClass(class string) bool {
+	for _, c := range e.Classes() {
+		if c == class {
+			return true
+		}
+	}
+
+	return false
+}
+
+// setClasses sets the class list, an empty list removes the attribute.
+func (e *Element) setClasses(classes []string) {
+	if len(classes) == 0 {
+		e.RemoveAttr("class")
+		return
+	}
+
+	e.SetAttr("class", strings.Join(classes, " "))
+}
+
+// AddClass adds the classes missing from the class list at its end.
+func (e *Element) AddClass(classes ...string) {
+	list := e.Classes()
+	for _, class := range classes {
+		if !contains(list, class) {
+			list = append(list, class)
+		}
+	}
+
+	e.setClasses(list)
+}
+
+// RemoveClass removes the classes from the class list, the attribute is removed when the list becomes empty.
+func (e *Element) RemoveClass(classes ...string) {
+	list := []string{}
+	for _, c := range e.Classes() {
+		if !contains(classes, c) {
+			list = append(list, c)
+		}
+	}
+
+	e.setClasses(list)
+}
+
+// ToggleClass adds the class if missing or removes it otherwise, returns whether the element has the class after the call.
+func (e *Element) ToggleClass(class string) bool {
+	if e.HasClass(class) {
+		e.RemoveClass(class)
+		return false
+	}
+
+	e.AddClass(class)
+	return true
+}
@@ -0,0 +1,115 @@
+package html_test
+
+import (
+	"testing"
+
+	"github.com/aziis98/textml/html"
+	"github.com/stretchr/testify/assert"
+)
+
+const page = `<div id="main" class="content wide"><h1>Title</h1><table><tr><td>1</td></tr></table><p class="note">A <a href="/x">link</a></p><p>Other <input disabled></p></div>`
+
+func TestFind(t *testing.T) {
+	nodes := html.ParseString(page)
+
+	main := html.Find(nodes, html.ByID("main"))
+	assert.NotNil(t, main)
+	assert.Equal(t, []string{"content", "wide"}, main.Classes())
+
+	assert.Len(t, html.FindAll(nodes, html.ByTag("P")), 2)
+	assert.Equal(t, "A link", html.Find(nodes, html.ByClass("note")).TextContent())
+	assert.Equal(t, "a", html.Find(nodes, html.ByAttribute("href", "/x")).TagName)
+	assert.Equal(t, "input", main.Find(html.HasAttribute("disabled")).TagName)
+	assert.Nil(t, html.Find(nodes, html.And(html.ByTag("p"), html.ByClass("missing"))))
+
+	link := main.Find(html.ByTag("a"))
+	assert.Equal(t, "note", link.Parent.Classes()[0])
+	assert.Equal(t, main, link.Closest(html.ByTag("div")))
+	assert.Nil(t, main.Parent)
+}
+
+func TestMutation(t *testing.T) {
+	nodes := html.ParseString(page)
+	main := html.Find(nodes, html.ByID("main"))
+
+	table := main.Find(html.ByTag("table"))
+	table.Wrap(html.NewElementNode("div", html.AttributeMap{"class": &html.Attribute{Value: "scroll"}}, nil))
+	assert.Equal(t, "scroll", table.Parent.Classes()[0])
+	assert.Equal(t, main, table.Parent.Parent)
+
+	title := main.Find(html.ByTag("h1"))
+	assert.True(t, title.ReplaceWith(html.NewElementNode("h2", nil, []html.Node{html.NewTextNode("Subtitle")})))
+	assert.Nil(t, title.Parent)
+
+	note := main.Find(html.ByClass("note"))
+	note.Remove()
+	assert.Nil(t, note.Parent)
+	assert.Equal(t, -1, main.IndexOf(note))
+
+	// moving an element removes it from its previous parent
+	input := main.Find(html.ByTag("input"))
+	main.PrependChild(input)
+	main.InsertAfter(input, html.NewTextNode(" "))
+	main.AppendChild(html.NewCommentNode("end"))
+
+	assert.Equal(t,
+		`<div class="content wide" id="main"><input disabled> <h2>Subtitle</h2><div class="scroll"><table><tr><td>1</td></tr></table></div><p>Other </p><!-- end --></div>`,
+		html.RenderToString(nodes),
+	)
+	assert.False(t, main.RemoveChild(note))
+}
+
+func TestMutationTopLevel(t *testing.T) {
+	nodes := html.ParseString(`<table id="a"></table><div><table id="b"></table></div>`)
+
+	for _, table := range html.FindAll(nodes, html.ByTag("table")) {
+		nodes = html.Wrap(nodes, table, html.NewElementNode("div", html.AttributeMap{"class": &html.Attribute{Value: "scroll"}}, nil))
+	}
+
+	assert.Equal(t,
+		`<div class="scroll"><table id="a"></table></div><div><div class="scroll"><table id="b"></table></div></div>`,
+		html.RenderToString(nodes),
+	)
+	assert.Nil(t, nodes[0].(*html.Element).Parent)
+
+	hr := html.NewElementNode("hr", nil, nil)
+	nodes = html.ReplaceWith(nodes, nodes[1].(*html.Element), hr)
+	assert.Equal(t, `<div class="scroll"><table id="a"></table></div><hr>`, html.RenderToString(nodes))
+
+	// elements outside of the nodes are left unchanged
+	assert.Len(t, html.ReplaceWith(nodes, html.NewElementNode("p", nil, nil)), 2)
+}
+
+func TestAttributes(t *testing.T) {
+	elem := html.NewElementNode("p", nil, nil)
+
+	_, ok := elem.Attr("id")
+	assert.False(t, ok)
+
+	elem.SetAttr("id", "x")
+	elem.SetBoolAttr("hidden", true)
+	value, ok := elem.Attr("hidden")
+	assert.True(t, ok)
+	assert.Equal(t, "", value)
+
+	elem.AddClass("a", "b", "a")
+	elem.AddClass("c")
+	elem.RemoveClass("b")
+	assert.False(t, elem.ToggleClass("a"))
+	assert.True(t, elem.ToggleClass("d"))
+	assert.Equal(t, `<p class="c d" hidden id="x"></p>`, html.RenderToString([]html.Node{elem}))
+
+	elem.RemoveClass("c", "d")
+	elem.SetBoolAttr("hidden", false)
+	elem.RemoveAttr("id")
+	assert.Equal(t, `<p></p>`, html.RenderToString([]html.Node{elem}))
+}
+
+func TestSetParents(t *testing.T) {
+	child := &html.Element{TagName: "b"}
+	parent := &html.Element{TagName: "p", Children: []html.Node{child}}
+
+	html.SetParents([]html.Node{parent})
+	assert.Equal(t, parent, child.Parent)
+	assert.Nil(t, parent.Parent)
+}
@@ -3,6 +3,7 @@ package html
 import (
 	"fmt"
 	"io"
+	"sort"
 	"strings"
 )
 
@@ -35,15 +36,46 @@ type Attribute struct {
 func (Attribute) attributeSeal() {}
 
 func (a Attribute) Write(w io.Writer) {
-	fmt.Fprintf(w, `="%s"`, a.Value)
+	fmt.Fprintf(w, `="%s"`, attributeEscaper.Replace(a.Value))
+}
+
+// textEscaper escapes text content, inside double quoted attribute values only "&" and the quote need escaping
+var (
+	textEscaper      = strings.NewReplacer("&", "&amp;", "<", "&lt;", ">", "&gt;", "\u00a0", "&nbsp;")
+	attributeEscaper = strings.NewReplacer("&", "&amp;", `"`, "&quot;", "\u00a0", "&nbsp;")
+)
+
+// voidElements have no closing tag and no children
+var voidElements = map[string]bool{
+	"area": true, "base": true, "br": true, "col": true, "embed": true, "hr": true, "img": true,
+	"input": true, "link": true, "meta": true, "source": true, "track": true, "wbr": true,
+}
+
+// rawTextElements hold text that is written without escaping
+var rawTextElements = map[string]bool{
+	"script": true, "style": true,
+}
+
+// IsVoidElement reports whether the tag name is an HTML void element like "img" or "br".
+func IsVoidElement(tagName string) bool {
+	return voidElements[strings.ToLower(tagName)]
+}
+
+// IsRawTextElement reports whether the content of the tag name is raw text, like for "script" and "style".
+func IsRawTextElement(tagName string) bool {
+	return rawTextElements[strings.ToLower(tagName)]
 }
 
 type Element struct {
 	TagName    string
 	Attributes map[string]AttributeNode
 	Children   []Node
+
+	// Parent is the element containing this one, it is kept up to date by [NewElementNode], [Parse] and the mutation methods like [Element.AppendChild] while trees built by hand can be fixed with [SetParents].
+	Parent *Element
 }
 
+// NewElementNode returns a new element and sets it as the parent of its element children.
 func NewElementNode(tagName string, attributes map[string]AttributeNode, children []Node) *Element {
 	if attributes == nil {
 		attributes = map[string]AttributeNode{}
@@ -52,27 +84,77 @@ func NewElementNode(tagName string, attributes map[string]AttributeNode, childre
 		children = []Node{}
 	}
 
-	return &Element{tagName, attributes, children}
+	elem := &Element{TagName: tagName, Attributes: attributes, Children: children}
+	elem.adopt(children...)
+
+	return elem
 }
 
 func (Element) htmlSeal() {}
 
+// Write writes the element with its attributes sorted by name, void elements have no closing tag and the text of raw text elements (script and style) is not escaped.
 func (n Element) Write(w io.Writer) {
-	attrs := &strings.Builder{}
-	for k, attr := range n.Attributes {
-		fmt.Fprintf(attrs, " %s", k)
-		attr.Write(attrs)
-	}
+	fmt.Fprintf(w, "<%s", n.TagName)
+	n.writeAttributes(w)
+	fmt.Fprint(w, ">")
 
-	fmt.Fprintf(w, "<%s%s>", n.TagName, attrs.String())
+	if IsVoidElement(n.TagName) {
+		return
+	}
 
 	for _, child := range n.Children {
+		if text, ok := child.(*Text); ok && IsRawTextElement(n.TagName) {
+			writeRawText(w, n.TagName, text.Value)
+			continue
+		}
+
 		child.Write(w)
 	}
 
 	fmt.Fprintf(w, "</%s>", n.TagName)
 }
 
+func (n Element) writeAttributes(w io.Writer) {
+	names := []string{}
+	for name := range n.Attributes {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	for _, name := range names {
+		fmt.Fprintf(w, " %s", name)
+		n.Attributes[name].Write(w)
+	}
+}
+
+// writeRawText writes the content of a raw text element, the closing tag can't appear in it so it is broken as "<\/tag".
+func writeRawText(w io.Writer, tagName, text string) {
+	closing := "</" + strings.ToLower(tagName)
+
+	for {
+		i := indexFold(text, closing)
+		if i < 0 {
+			break
+		}
+
+		fmt.Fprint(w, text[:i]+"<\\/")
+		text = text[i+2:]
+	}
+
+	fmt.Fprint(w, text)
+}
+
+// indexFold is like [strings.Index] but ignores the case of ASCII letters in sub.
+func indexFold(s, sub string) int {
+	for i := 0; i+len(sub) <= len(s); i++ {
+		if strings.EqualFold(s[i:i+len(sub)], sub) {
+			return i
+		}
+	}
+
+	return -1
+}
+
 type Text struct {
 	Value string
 }
@@ -84,7 +166,7 @@ func NewTextNode(s string) *Text {
 func (Text) htmlSeal() {}
 
 func (n Text) Write(w io.Writer) {
-	fmt.Fprintf(w, `%s`, n.Value)
+	fmt.Fprint(w, textEscaper.Replace(n.Value))
 }
 
 type Comment struct {
@@ -97,8 +179,44 @@ func NewCommentNode(s string) *Comment {
 
 func (Comment) htmlSeal() {}
 
+// Write writes the comment, a "--" in its text would end the comment early so it is written as "- -".
 func (n Comment) Write(w io.Writer) {
-	fmt.Fprintf(w, `<!-- %s -->`, n.Value)
+	value := n.Value
+	for strings.Contains(value, "--") {
+		value = strings.ReplaceAll(value, "--", "- -")
+	}
+
+	fmt.Fprintf(w, `<!-- %s -->`, value)
+}
+
+// Doctype is the document type declaration, the name is "html" for HTML5 documents.
+type Doctype struct {
+	Name string
+}
+
+func NewDoctypeNode() *Doctype {
+	return &Doctype{"html"}
+}
+
+func (Doctype) htmlSeal() {}
+
+func (n Doctype) Write(w io.Writer) {
+	fmt.Fprintf(w, "<!DOCTYPE %s>", n.Name)
+}
+
+// Raw is a piece of HTML written as is, without escaping.
+type Raw struct {
+	Value string
+}
+
+func NewRawNode(s string) *Raw {
+	return &Raw{s}
+}
+
+func (Raw) htmlSeal() {}
+
+func (n Raw) Write(w io.Writer) {
+	fmt.Fprint(w, n.Value)
 }
 
 func RenderToString(nodes []Node) string {
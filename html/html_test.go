@@ -0,0 +1,87 @@
+package html_test
+
+import (
+	"flag"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/aziis98/textml/html"
+	"github.com/stretchr/testify/assert"
+)
+
+var update = flag.Bool("update", false, "update the golden files in testdata")
+
+func el(tagName string, attrs map[string]string, children ...html.Node) *html.Element {
+	attributes := html.AttributeMap{}
+	for name, value := range attrs {
+		attributes[name] = &html.Attribute{Value: value}
+	}
+
+	return html.NewElementNode(tagName, attributes, children)
+}
+
+func text(s string) *html.Text {
+	return html.NewTextNode(s)
+}
+
+var goldenCases = map[string][]html.Node{
+	"escaping": {
+		el("p", map[string]string{"title": `Tom & "Jerry" <3`, "class": "a b", "data-x": "it's"},
+			text("1 < 2 && 3 > 2, "),
+			el("code", nil, text("<br>")),
+			text(" non\u00a0breaking"),
+		),
+		html.NewCommentNode("a -- comment --> here"),
+	},
+	"void": {
+		el("img", map[string]string{"src": "image.png", "alt": "An image"}),
+		el("br", nil),
+		el("input", map[string]string{"type": "checkbox", "checked": ""}),
+		html.NewElementNode("input", html.AttributeMap{"disabled": html.EmptyAttribute{}}, nil),
+		el("hr", nil, text("ignored children")),
+	},
+	"rawtext": {
+		el("script", nil, text(`if (a < b && c > d) { console.log("</p>") }`)),
+		el("script", nil, text(`document.write("</SCRIPT><script>alert(1)</script>")`)),
+		el("style", nil, text(`a > b { content: "&amp;" }`)),
+		el("textarea", nil, text("<escaped> & text")),
+	},
+	"document": {
+		html.NewDoctypeNode(),
+		el("html", map[string]string{"lang": "en"},
+			el("head", nil,
+				el("meta", map[string]string{"charset": "utf-8"}),
+				el("title", nil, text("Example & more")),
+			),
+			el("body", nil,
+				html.NewRawNode("<p>Raw <b>HTML</b></p>"),
+				el("a", map[string]string{"href": "https://example.org/?a=1&b=2"}, text("link")),
+			),
+		),
+	},
+}
+
+func TestGolden(t *testing.T) {
+	for name, nodes := range goldenCases {
+		path := filepath.Join("testdata", name+".html")
+		rendered := html.RenderToString(nodes) + "\n"
+
+		if *update {
+			assert.Nil(t, os.WriteFile(path, []byte(rendered), 0o644))
+			continue
+		}
+
+		expected, err := os.ReadFile(path)
+		assert.Nil(t, err)
+		assert.Equal(t, string(expected), rendered, name)
+	}
+}
+
+func TestAttributeOrder(t *testing.T) {
+	node := el("div", map[string]string{"z": "1", "a": "2", "m": "3", "id": "x", "class": "y"})
+
+	for i := 0; i < 10; i++ {
+		assert.Equal(t, `<div a="2" class="y" id="x" m="3" z="1"></div>`, html.RenderToString([]html.Node{node}))
+	}
+}
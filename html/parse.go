@@ -0,0 +1,324 @@
+package html
+
+import (
+	stdhtml "html"
+	"io"
+	"strings"
+	"unicode"
+
+	"github.com/aziis98/textml/utils"
+)
+
+// rcdataElements hold text with entities but no elements, like raw text elements their content ends only at the closing tag
+var rcdataElements = map[string]bool{
+	"textarea": true, "title": true,
+}
+
+// closesParagraph are the elements that implicitly close an open <p>
+var closesParagraph = map[string]bool{
+	"address": true, "article": true, "aside": true, "blockquote": true, "details": true, "div": true,
+	"dl": true, "fieldset": true, "figcaption": true, "figure": true, "footer": true, "form": true,
+	"h1": true, "h2": true, "h3": true, "h4": true, "h5": true, "h6": true, "header": true, "hr": true,
+	"main": true, "nav": true, "ol": true, "p": true, "pre": true, "section": true, "table": true, "ul": true,
+}
+
+// implicitEnds lists for some elements the open elements they close and the elements that stop the search, for example a <li> closes an open <li> of the same list but not one of an outer list.
+var implicitEnds = map[string]struct{ closes, scope []string }{
+	"li":     {[]string{"li"}, []string{"ul", "ol"}},
+	"dt":     {[]string{"dt", "dd"}, []string{"dl"}},
+	"dd":     {[]string{"dt", "dd"}, []string{"dl"}},
+	"tr":     {[]string{"tr", "td", "th"}, []string{"table", "thead", "tbody", "tfoot"}},
+	"td":     {[]string{"td", "th"}, []string{"tr", "table"}},
+	"th":     {[]string{"td", "th"}, []string{"tr", "table"}},
+	"option": {[]string{"option"}, []string{"select", "datalist"}},
+}
+
+// Parse reads an HTML document or fragment. This is a lenient parser that covers the common cases of the HTML specification: tag and attribute names are lower cased, entities are decoded, void elements, raw text elements and elements with implicit end tags (like <p> and <li>) are handled and unmatched end tags are ignored.
+func Parse(r io.Reader) ([]Node, error) {
+	source, err := io.ReadAll(r)
+	if err != nil {
+		return nil, err
+	}
+
+	p := &htmlParser{src: string(source), root: &Element{Children: []Node{}}}
+	p.stack = []*Element{p.root}
+	p.parse()
+
+	return p.root.Children, nil
+}
+
+// ParseString is like [Parse] but reads from a string.
+func ParseString(s string) []Node {
+	nodes, _ := Parse(strings.NewReader(s))
+	return nodes
+}
+
+type htmlParser struct {
+	src   string
+	pos   int
+	root  *Element
+	stack []*Element
+}
+
+func (p *htmlParser) current() *Element {
+	return p.stack[len(p.stack)-1]
+}
+
+func (p *htmlParser) append(n Node) {
+	current := p.current()
+	current.Children = append(current.Children, n)
+
+	if elem, ok := n.(*Element); ok && current != p.root {
+		elem.Parent = current
+	}
+}
+
+func (p *htmlParser) rest() string {
+	return p.src[p.pos:]
+}
+
+func (p *htmlParser) parse() {
+	for p.pos < len(p.src) {
+		rest := p.rest()
+
+		switch {
+		case strings.HasPrefix(rest, "<!--"):
+			end := strings.Index(rest[4:], "-->")
+			if end < 0 {
+				p.append(NewCommentNode(strings.TrimSpace(rest[4:])))
+				p.pos = len(p.src)
+				continue
+			}
+
+			p.append(NewCommentNode(strings.TrimSpace(rest[4 : 4+end])))
+			p.pos += 4 + end + 3
+
+		case strings.HasPrefix(rest, "<!") || strings.HasPrefix(rest, "<?"):
+			end, next := tagEnd(rest)
+
+			declaration := rest[2:end]
+			if fields := strings.Fields(declaration); len(fields) > 1 && strings.EqualFold(fields[0], "doctype") {
+				p.append(&Doctype{Name: strings.ToLower(fields[1])})
+			}
+			p.pos += next
+
+		case strings.HasPrefix(rest, "</") && len(rest) > 2 && isASCIILetter(rest[2]):
+			end, next := tagEnd(rest)
+
+			name := strings.ToLower(strings.TrimSpace(tagName(rest[2:end])))
+			p.pos += next
+			p.closeElement(name)
+
+		case strings.HasPrefix(rest, "<") && len(rest) > 1 && isASCIILetter(rest[1]):
+			p.parseStartTag()
+
+		default:
+			end := strings.Index(rest[1:], "<")
+			if end < 0 {
+				end = len(rest)
+			} else {
+				end++
+			}
+
+			p.appendText(stdhtml.UnescapeString(rest[:end]))
+			p.pos += end
+		}
+	}
+}
+
+func (p *htmlParser) appendText(s string) {
+	current := p.current()
+	if len(current.Children) > 0 {
+		if text, ok := current.Children[len(current.Children)-1].(*Text); ok {
+			text.Value += s
+			return
+		}
+	}
+
+	p.append(NewTextNode(s))
+}
+
+// tagEnd returns the index of the ">" closing a tag at the start of s and the index after it, unterminated tags end with the input.
+func tagEnd(s string) (end, next int) {
+	end = strings.Index(s, ">")
+	if end < 0 {
+		return len(s), len(s)
+	}
+
+	return end, end + 1
+}
+
+func isASCIILetter(c byte) bool {
+	return 'a' <= c && c <= 'z' || 'A' <= c && c <= 'Z'
+}
+
+// tagName returns the tag name at the start of s
+func tagName(s string) string {
+	end := strings.IndexFunc(s, func(r rune) bool {
+		return unicode.IsSpace(r) || r == '/' || r == '>'
+	})
+	if end < 0 {
+		return s
+	}
+
+	return s[:end]
+}
+
+func (p *htmlParser) parseStartTag() {
+	p.pos++ // skip "<"
+
+	name := strings.ToLower(tagName(p.rest()))
+	p.pos += len(name)
+
+	attributes := AttributeMap{}
+	selfClosing := false
+
+	for p.pos < len(p.src) {
+		p.skipSpaces()
+		if p.pos >= len(p.src) {
+			break
+		}
+
+		if p.src[p.pos] == '>' {
+			p.pos++
+			break
+		}
+		if strings.HasPrefix(p.rest(), "/>") {
+			selfClosing = true
+			p.pos += 2
+			break
+		}
+		if p.src[p.pos] == '/' {
+			p.pos++
+			continue
+		}
+
+		attrName, attr := p.parseAttribute()
+		if _, ok := attributes[attrName]; !ok && attrName != "" {
+			attributes[attrName] = attr
+		}
+	}
+
+	p.openElement(name)
+
+	elem := NewElementNode(name, attributes, nil)
+	p.append(elem)
+
+	switch {
+	case IsVoidElement(name) || selfClosing:
+		// no children
+	case rawTextElements[name] || rcdataElements[name]:
+		rest := p.rest()
+
+		end := indexFold(rest, "</"+name)
+		if end < 0 {
+			end = len(rest)
+		}
+
+		text := rest[:end]
+		if rcdataElements[name] {
+			text = stdhtml.UnescapeString(text)
+		}
+		if text != "" {
+			elem.Children = append(elem.Children, NewTextNode(text))
+		}
+
+		p.pos += end
+		if close := strings.Index(p.rest(), ">"); close >= 0 {
+			p.pos += close + 1
+		}
+	default:
+		p.stack = append(p.stack, elem)
+	}
+}
+
+func (p *htmlParser) skipSpaces() {
+	for p.pos < len(p.src) && unicode.IsSpace(rune(p.src[p.pos])) {
+		p.pos++
+	}
+}
+
+func (p *htmlParser) parseAttribute() (string, AttributeNode) {
+	start := p.pos
+	for p.pos < len(p.src) {
+		c := p.src[p.pos]
+		if unicode.IsSpace(rune(c)) || c == '=' || c == '>' || (c == '/' && strings.HasPrefix(p.rest(), "/>")) {
+			break
+		}
+		p.pos++
+	}
+	name := strings.ToLower(p.src[start:p.pos])
+
+	p.skipSpaces()
+	if p.pos >= len(p.src) || p.src[p.pos] != '=' {
+		return name, EmptyAttribute{}
+	}
+	p.pos++
+	p.skipSpaces()
+
+	if p.pos >= len(p.src) {
+		return name, &Attribute{}
+	}
+
+	var value string
+	if quote := p.src[p.pos]; quote == '"' || quote == '\'' {
+		end := strings.IndexByte(p.src[p.pos+1:], quote)
+		if end < 0 {
+			end = len(p.src) - p.pos - 1
+		}
+
+		value = p.src[p.pos+1 : p.pos+1+end]
+		p.pos = utils.Min(p.pos+end+2, len(p.src))
+	} else {
+		start := p.pos
+		for p.pos < len(p.src) && !unicode.IsSpace(rune(p.src[p.pos])) && p.src[p.pos] != '>' {
+			p.pos++
+		}
+		value = p.src[start:p.pos]
+	}
+
+	return name, &Attribute{Value: stdhtml.UnescapeString(value)}
+}
+
+// openElement closes the elements implicitly ended by the start of an element with the given name.
+func (p *htmlParser) openElement(name string) {
+	if closesParagraph[name] && p.current().TagName == "p" {
+		p.stack = p.stack[:len(p.stack)-1]
+	}
+
+	ends, ok := implicitEnds[name]
+	if !ok {
+		return
+	}
+
+	for i := len(p.stack) - 1; i > 0; i-- {
+		tag := p.stack[i].TagName
+		if contains(ends.scope, tag) {
+			return
+		}
+		if contains(ends.closes, tag) {
+			p.stack = p.stack[:i]
+			return
+		}
+	}
+}
+
+// closeElement closes the innermost open element with the given name and the elements nested in it, end tags without an open element are ignored.
+func (p *htmlParser) closeElement(name string) {
+	for i := len(p.stack) - 1; i > 0; i-- {
+		if p.stack[i].TagName == name {
+			p.stack = p.stack[:i]
+			return
+		}
+	}
+}
+
+func contains(list []string, s string) bool {
+	for _, item := range list {
+		if item == s {
+			return true
+		}
+	}
+
+	return false
+}
@@ -0,0 +1,66 @@
+package html_test
+
+import (
+	"testing"
+
+	"github.com/aziis98/textml/html"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestParse(t *testing.T) {
+	nodes := html.ParseString(`<!DOCTYPE html><P Class="intro" hidden>Tom &amp; Jerry<br/>end</p><!-- note -->`)
+
+	assert.Equal(t, []html.Node{
+		&html.Doctype{Name: "html"},
+		html.NewElementNode("p", html.AttributeMap{
+			"class":  &html.Attribute{Value: "intro"},
+			"hidden": html.EmptyAttribute{},
+		}, []html.Node{
+			text("Tom & Jerry"),
+			html.NewElementNode("br", html.AttributeMap{}, nil),
+			text("end"),
+		}),
+		html.NewCommentNode("note"),
+	}, nodes)
+}
+
+func TestParseImplicitEndTags(t *testing.T) {
+	nodes := html.ParseString(`<ul><li>One<li>Two<ul><li>Nested</ul><li>Three</ul><p>First<p>Second<div>Block</div></b>`)
+
+	assert.Equal(t,
+		`<ul><li>One</li><li>Two<ul><li>Nested</li></ul></li><li>Three</li></ul><p>First</p><p>Second</p><div>Block</div>`,
+		html.PrintToString(nodes, html.PrintOptions{Minify: true}),
+	)
+}
+
+func TestParseRawText(t *testing.T) {
+	nodes := html.ParseString(`<script>if (a < b && c) { x("</p>") }</SCRIPT><textarea>&lt;b&gt;</textarea>`)
+
+	assert.Equal(t, []html.Node{
+		html.NewElementNode("script", html.AttributeMap{}, []html.Node{
+			text(`if (a < b && c) { x("</p>") }`),
+		}),
+		html.NewElementNode("textarea", html.AttributeMap{}, []html.Node{
+			text("<b>"),
+		}),
+	}, nodes)
+}
+
+func TestParseUnterminated(t *testing.T) {
+	for source, expected := range map[string]string{
+		`<p title="x`:    `<p title="x"></p>`,
+		`<b>a`:           `<b>a</b>`,
+		`<!-- comment`:   `<!-- comment -->`,
+		`<script>if (a`:  `<script>if (a</script>`,
+		`a<!`:            `a`,
+		`a<?`:            `a`,
+		`<!doctype html`: `<!DOCTYPE html>`,
+		`<b>a</b`:        `<b>a</b>`,
+	} {
+		assert.Equal(t, expected+"\n", html.PrintToString(html.ParseString(source), html.PrintOptions{}), source)
+	}
+
+	for _, source := range []string{"<!", "<?"} {
+		assert.Empty(t, html.ParseString(source), source)
+	}
+}
@@ -0,0 +1,224 @@
+package html
+
+import (
+	"fmt"
+	"io"
+	"strings"
+	"unicode"
+
+	"github.com/aziis98/textml/utils"
+)
+
+// PrintOptions for [Print]
+type PrintOptions struct {
+	// Indent is the indentation of nested block elements, two spaces if empty
+	Indent string
+
+	// NoIndent still places block elements on their own lines but doesn't indent them, [PrintOptions.Indent] is then ignored
+	NoIndent bool
+
+	// Minify writes everything on a single line, collapses whitespace and drops comments
+	Minify bool
+}
+
+// blockElements are placed on their own line by [Print], the other elements are inline and flow with the text around them
+var blockElements = map[string]bool{
+	"address": true, "article": true, "aside": true, "blockquote": true, "body": true, "details": true,
+	"dialog": true, "dd": true, "div": true, "dl": true, "dt": true, "fieldset": true, "figcaption": true,
+	"figure": true, "footer": true, "form": true, "h1": true, "h2": true, "h3": true, "h4": true,
+	"h5": true, "h6": true, "head": true, "header": true, "hgroup": true, "hr": true, "html": true,
+	"li": true, "link": true, "main": true, "meta": true, "nav": true, "ol": true, "p": true,
+	"pre": true, "script": true, "section": true, "style": true, "summary": true, "table": true,
+	"tbody": true, "td": true, "tfoot": true, "th": true, "thead": true, "title": true, "tr": true,
+	"ul": true, "base": true, "noscript": true, "template": true,
+}
+
+// preformattedElements keep their content exactly as is
+var preformattedElements = map[string]bool{
+	"pre": true, "textarea": true, "script": true, "style": true,
+}
+
+// IsBlockElement reports whether the tag name is a block element, these are placed on their own line by [Print].
+func IsBlockElement(tagName string) bool {
+	return blockElements[strings.ToLower(tagName)]
+}
+
+// Print writes the nodes with block elements on their own lines and indented, the content of inline elements and text flows on a single line with whitespace collapsed. The content of whitespace sensitive elements like <pre> and <textarea> is written unchanged.
+func Print(w io.Writer, nodes []Node, opts PrintOptions) error {
+	if opts.NoIndent {
+		opts.Indent = ""
+	} else if opts.Indent == "" {
+		opts.Indent = "  "
+	}
+
+	p := &prettyPrinter{opts: opts, sb: &strings.Builder{}}
+	p.printBlock(nodes, 0)
+
+	if !opts.Minify {
+		p.sb.WriteString("\n")
+	}
+
+	_, err := io.WriteString(w, strings.TrimLeft(p.sb.String(), "\n"))
+	return err
+}
+
+// PrintToString is like [Print] but returns a string.
+func PrintToString(nodes []Node, opts PrintOptions) string {
+	sb := &strings.Builder{}
+	Print(sb, nodes, opts)
+	return sb.String()
+}
+
+type prettyPrinter struct {
+	opts PrintOptions
+	sb   *strings.Builder
+}
+
+func (p *prettyPrinter) line(depth int, s string) {
+	if p.opts.Minify {
+		p.sb.WriteString(s)
+		return
+	}
+
+	p.sb.WriteString("\n" + strings.Repeat(p.opts.Indent, depth) + s)
+}
+
+func asElement(n Node) (*Element, bool) {
+	switch n := n.(type) {
+	case *Element:
+		return n, true
+	case Element:
+		return &n, true
+	}
+
+	return nil, false
+}
+
+// isBlockNode reports whether a node goes on its own line
+func isBlockNode(n Node) bool {
+	switch n.(type) {
+	case *Comment, Comment, *Doctype, Doctype:
+		return true
+	}
+
+	elem, ok := asElement(n)
+	return ok && IsBlockElement(elem.TagName)
+}
+
+func render(n Node) string {
+	sb := &strings.Builder{}
+	n.Write(sb)
+	return sb.String()
+}
+
+func (p *prettyPrinter) printBlock(nodes []Node, depth int) {
+	run := &strings.Builder{}
+	flush := func() {
+		if s := strings.TrimSpace(run.String()); s != "" {
+			p.line(depth, s)
+		}
+		run.Reset()
+	}
+
+	for _, n := range nodes {
+		if !isBlockNode(n) {
+			run.WriteString(p.inline(n))
+			continue
+		}
+
+		flush()
+		p.printBlockNode(n, depth)
+	}
+	flush()
+}
+
+func (p *prettyPrinter) printBlockNode(n Node, depth int) {
+	elem, ok := asElement(n)
+	if !ok {
+		switch n.(type) {
+		case *Comment, Comment:
+			if p.opts.Minify {
+				return
+			}
+		}
+
+		p.line(depth, render(n))
+		return
+	}
+
+	switch {
+	case IsVoidElement(elem.TagName) || preformattedElements[strings.ToLower(elem.TagName)]:
+		p.line(depth, render(elem))
+
+	case !hasBlockChildren(elem):
+		content := &strings.Builder{}
+		for _, child := range elem.Children {
+			content.WriteString(p.inline(child))
+		}
+
+		p.line(depth, openingTag(elem)+strings.TrimSpace(content.String())+closingTag(elem))
+
+	default:
+		p.line(depth, openingTag(elem))
+		p.printBlock(elem.Children, depth+1)
+		p.line(depth, closingTag(elem))
+	}
+}
+
+func hasBlockChildren(elem *Element) bool {
+	for _, child := range elem.Children {
+		if isBlockNode(child) {
+			return true
+		}
+	}
+
+	return false
+}
+
+// inline renders an inline node with whitespace collapsed, whitespace sensitive elements are written as is.
+func (p *prettyPrinter) inline(n Node) string {
+	switch n := n.(type) {
+	case *Text:
+		return utils.CollapseSpaces(render(n), unicode.IsSpace)
+	case Text:
+		return utils.CollapseSpaces(render(n), unicode.IsSpace)
+	case *Comment, Comment:
+		if p.opts.Minify {
+			return ""
+		}
+		return render(n)
+	}
+
+	elem, ok := asElement(n)
+	if !ok || IsVoidElement(elem.TagName) || preformattedElements[strings.ToLower(elem.TagName)] {
+		return render(n)
+	}
+
+	sb := &strings.Builder{}
+	sb.WriteString(openingTag(elem))
+	for _, child := range elem.Children {
+		if isBlockNode(child) {
+			// block elements nested in inline ones are kept on the same line
+			sb.WriteString(render(child))
+			continue
+		}
+
+		sb.WriteString(p.inline(child))
+	}
+	sb.WriteString(closingTag(elem))
+
+	return sb.String()
+}
+
+func openingTag(elem *Element) string {
+	sb := &strings.Builder{}
+	fmt.Fprintf(sb, "<%s", elem.TagName)
+	elem.writeAttributes(sb)
+	sb.WriteString(">")
+
+	return sb.String()
+}
+
+func closingTag(elem *Element) string {
+	return fmt.Sprintf("</%s>", elem.TagName)
+}
@@ -0,0 +1,82 @@
+package html_test
+
+import (
+	"testing"
+
+	"github.com/aziis98/textml/html"
+	"github.com/stretchr/testify/assert"
+)
+
+var prettyDocument = []html.Node{
+	html.NewDoctypeNode(),
+	el("html", nil,
+		el("head", nil, el("title", nil, text("Example"))),
+		el("body", nil,
+			text("\n  "),
+			el("h1", map[string]string{"id": "top"}, text("A   title")),
+			text("\n  "),
+			html.NewCommentNode("content"),
+			el("p", nil,
+				text("Some\n    "),
+				el("b", nil, text("bold")),
+				text(" and "),
+				el("a", map[string]string{"href": "#"}, el("i", nil, text("italic"))),
+				text(" text "),
+				el("br", nil),
+				text("on two lines."),
+			),
+			el("ul", nil,
+				el("li", nil, text("one")),
+				el("li", nil, text("two "), el("ul", nil, el("li", nil, text("nested")))),
+			),
+			el("pre", nil, text("func main() {\n    fmt.Println(\"hi\")\n}")),
+			el("p", nil, text("A "), el("textarea", nil, text("  keep\n  this  ")), text(" field")),
+		),
+	),
+}
+
+func TestPrint(t *testing.T) {
+	assert.Equal(t, `<!DOCTYPE html>
+<html>
+  <head>
+    <title>Example</title>
+  </head>
+  <body>
+    <h1 id="top">A title</h1>
+    <!-- content -->
+    <p>Some <b>bold</b> and <a href="#"><i>italic</i></a> text <br>on two lines.</p>
+    <ul>
+      <li>one</li>
+      <li>
+        two
+        <ul>
+          <li>nested</li>
+        </ul>
+      </li>
+    </ul>
+    <pre>func main() {
+    fmt.Println("hi")
+}</pre>
+    <p>A <textarea>  keep
+  this  </textarea> field</p>
+  </body>
+</html>
+`, html.PrintToString(prettyDocument, html.PrintOptions{}))
+}
+
+func TestPrintIndent(t *testing.T) {
+	nodes := []html.Node{el("div", nil, el("p", nil, text("text")))}
+
+	assert.Equal(t, "<div>\n\t<p>text</p>\n</div>\n", html.PrintToString(nodes, html.PrintOptions{Indent: "\t"}))
+	assert.Equal(t, "<div>\n<p>text</p>\n</div>\n", html.PrintToString(nodes, html.PrintOptions{NoIndent: true}))
+}
+
+func TestPrintMinify(t *testing.T) {
+	assert.Equal(t,
+		`<!DOCTYPE html><html><head><title>Example</title></head><body><h1 id="top">A title</h1><p>Some <b>bold</b> and <a href="#"><i>italic</i></a> text <br>on two lines.</p><ul><li>one</li><li>two<ul><li>nested</li></ul></li></ul><pre>func main() {
+    fmt.Println("hi")
+}</pre><p>A <textarea>  keep
+  this  </textarea> field</p></body></html>`,
+		html.PrintToString(prettyDocument, html.PrintOptions{Minify: true}),
+	)
+}
@@ -0,0 +1,441 @@
+// Package importer converts documents written in other formats to TextML.
+package importer
+
+import (
+	"io"
+	"sort"
+	"strings"
+	"unicode"
+
+	"github.com/aziis98/textml/ast"
+	"github.com/aziis98/textml/html"
+	"github.com/aziis98/textml/utils"
+)
+
+// HTMLOptions for [HTML]
+type HTMLOptions struct {
+	// Document maps common tags to the vocabulary of the runtime/document engine (headings to #title and #subtitle, <b> to #bold, <a> to #link, paragraphs to text separated by blank lines, <pre><code> code blocks to #code and <title> and <meta> to #metadata entries), the other tags keep the html.* vocabulary
+	Document bool
+
+	// Indent is the indentation of nested block elements, four spaces if empty
+	Indent string
+}
+
+// documentTags maps tags to the elements of the runtime/document engine with the same meaning
+var documentTags = map[string]string{
+	"h1": "title",
+	"h2": "subtitle",
+	"h3": "subsubtitle",
+	"h4": "subsubsubtitle",
+
+	"b":      "bold",
+	"strong": "bold",
+	"i":      "italic",
+	"em":     "italic",
+	"u":      "underline",
+	"s":      "strikethrough",
+	"del":    "strikethrough",
+	"strike": "strikethrough",
+
+	"code": "code",
+}
+
+// HTML reads an HTML page and converts it to TextML. Elements become #html.TAG{ CHILDREN }, or #html.TAG{ ATTRIBUTES }{ CHILDREN } when the element has attributes written as #NAME{ VALUE } entries, that is the vocabulary understood by transpile.Html. The <html> element and the doctype are dropped, comments are removed and whitespace is collapsed outside of <pre> and other whitespace sensitive elements. Top level text containing braces is wrapped in #html.p or #html.span, outside of elements these would be read as markup.
+func HTML(r io.Reader, opts HTMLOptions) (ast.Block, error) {
+	nodes, err := html.Parse(r)
+	if err != nil {
+		return nil, err
+	}
+
+	return HTMLNodes(nodes, opts), nil
+}
+
+// HTMLNodes is like [HTML] but converts already parsed nodes.
+func HTMLNodes(nodes []html.Node, opts HTMLOptions) ast.Block {
+	if opts.Indent == "" {
+		opts.Indent = "    "
+	}
+
+	c := &htmlConverter{opts: opts}
+	return c.convertChildren(c.unwrapRoot(nodes), 0, false, true)
+}
+
+type htmlConverter struct {
+	opts HTMLOptions
+}
+
+// unwrapRoot replaces the <html> element with its children, in document mode also <body> is unwrapped and <head> is replaced by a #metadata element.
+func (c *htmlConverter) unwrapRoot(nodes []html.Node) []html.Node {
+	result := []html.Node{}
+
+	for _, n := range nodes {
+		elem, ok := n.(*html.Element)
+		if !ok {
+			result = append(result, n)
+			continue
+		}
+
+		switch {
+		case elem.TagName == "html":
+			result = append(result, c.unwrapRoot(elem.Children)...)
+		case elem.TagName == "body" && c.opts.Document:
+			result = append(result, elem.Children...)
+		default:
+			result = append(result, n)
+		}
+	}
+
+	return result
+}
+
+// item is a child of a block being laid out, either a block element on its own line or a run of inline nodes
+type item struct {
+	nodes     ast.Block
+	block     bool
+	paragraph bool
+}
+
+// convertChildren converts the children of an element nested depth levels in the document, when some children are block elements each of them and each run of inline nodes between them goes on its own line. Otherwise with trim the spaces at the start and end are removed, browsers don't render them in block elements while in inline elements they separate words from the text around.
+func (c *htmlConverter) convertChildren(nodes []html.Node, depth int, preformatted, trim bool) ast.Block {
+	items := []item{}
+	run := ast.Block{}
+	flush := func() {
+		if len(run) > 0 {
+			items = append(items, item{nodes: run})
+			run = ast.Block{}
+		}
+	}
+
+	hasBlocks := false
+	for _, n := range nodes {
+		elem, ok := n.(*html.Element)
+		if ok && !preformatted && html.IsBlockElement(elem.TagName) {
+			flush()
+			hasBlocks = true
+			items = append(items, item{
+				nodes:     c.convertElement(elem, depth, false),
+				block:     true,
+				paragraph: c.opts.Document && elem.TagName == "p",
+			})
+			continue
+		}
+
+		run = append(run, c.convertInline(n, depth, preformatted)...)
+	}
+	flush()
+
+	if !hasBlocks {
+		if len(items) == 0 {
+			return ast.Block{}
+		}
+		if !trim {
+			return items[0].nodes
+		}
+
+		items[0].nodes = trimBlock(items[0].nodes)
+		if depth == 0 {
+			return wrapTopLevel(items[0])
+		}
+
+		return items[0].nodes
+	}
+
+	return c.layout(items, depth)
+}
+
+// layout puts each item on its own line indented by depth, paragraphs are separated by blank lines.
+func (c *htmlConverter) layout(items []item, depth int) ast.Block {
+	indent := strings.Repeat(c.opts.Indent, depth)
+
+	result := ast.Block{}
+	var previous *item
+	for i := range items {
+		it := &items[i]
+		if !it.block {
+			it.nodes = trimBlock(it.nodes)
+		}
+		if len(it.nodes) == 0 {
+			continue
+		}
+
+		separator := "\n"
+		if previous != nil && (depth == 0 || previous.paragraph || it.paragraph) {
+			separator = "\n\n"
+		}
+		if previous != nil || depth > 0 {
+			result = append(result, ast.T(separator+indent))
+		}
+
+		if depth == 0 {
+			result = append(result, wrapTopLevel(*it)...)
+		} else {
+			result = append(result, it.nodes...)
+		}
+		previous = it
+	}
+
+	if depth > 0 {
+		// the printer adds a space before closing braces that the lexer then drops, see also marshal.go
+		closing := "\n" + strings.Repeat(c.opts.Indent, depth-1)
+		result = append(result, ast.T(strings.TrimSuffix(closing, " ")))
+	}
+
+	return mergeText(result)
+}
+
+// wrapTopLevel wraps an item of the top level in an html element if its text contains braces, outside of elements these would be read as markup. Paragraphs become #html.p and the other items #html.span.
+func wrapTopLevel(it item) ast.Block {
+	if ast.BraceDepth(it.nodes, 1) == 1 {
+		return it.nodes
+	}
+
+	if it.paragraph {
+		return ast.Block{ast.EN("html.p", it.nodes)}
+	}
+
+	return ast.Block{ast.EN("html.span", it.nodes)}
+}
+
+// convertInline converts a node flowing with the text around it.
+func (c *htmlConverter) convertInline(n html.Node, depth int, preformatted bool) ast.Block {
+	switch n := n.(type) {
+	case *html.Text:
+		if preformatted {
+			return ast.Block{ast.T(n.Value)}
+		}
+
+		return ast.Block{ast.T(utils.CollapseSpaces(n.Value, isCollapsible))}
+
+	case *html.Element:
+		return c.convertElement(n, depth, preformatted)
+	}
+
+	// comments and doctypes are dropped
+	return ast.Block{}
+}
+
+// convertElement converts an element nested depth levels in the document, the whitespace of preformatted elements and of their descendants is kept.
+func (c *htmlConverter) convertElement(elem *html.Element, depth int, preformatted bool) ast.Block {
+	tag := elem.TagName
+	preformatted = preformatted || isPreformatted(tag)
+
+	children := c.convertChildren(elem.Children, depth+1, preformatted, html.IsBlockElement(tag) && !preformatted)
+
+	if c.opts.Document {
+		if result, ok := c.convertDocumentElement(elem, children, depth); ok {
+			return result
+		}
+	}
+
+	// tags that can't be written as an element name, like <svg:rect> or <my.element>, only keep their content
+	if !ast.IsValidName(tag) || strings.Contains(tag, ".") {
+		return children
+	}
+
+	name := "html." + tag
+
+	attributes := c.convertAttributes(elem.Attributes)
+	if len(attributes) == 0 {
+		return ast.Block{ast.EN(name, children)}
+	}
+
+	return ast.Block{ast.EN(name, attributes, children)}
+}
+
+// convertDocumentElement converts the element to the runtime/document vocabulary if there is a corresponding element, elements of the vocabulary can't have attributes so tags like <code class="x"> keep the html.* vocabulary. Code blocks like <pre><code class="language-go"> become #code{ #format{ go } ... }.
+func (c *htmlConverter) convertDocumentElement(elem *html.Element, children ast.Block, depth int) (ast.Block, bool) {
+	if name, ok := documentTags[elem.TagName]; ok && len(elem.Attributes) == 0 {
+		return ast.Block{ast.EN(name, children)}, true
+	}
+
+	switch elem.TagName {
+	case "p":
+		return children, true
+
+	case "pre":
+		code, language, ok := preformattedCode(elem)
+		if !ok {
+			return nil, false
+		}
+
+		return ast.Block{ast.EN("code", c.codeBlock(code, language, depth))}, true
+
+	case "a":
+		href, ok := elem.Attributes["href"].(*html.Attribute)
+		if !ok {
+			return children, true
+		}
+
+		return ast.Block{ast.EN("link", children, ast.B(ast.T(href.Value)))}, true
+
+	case "head":
+		entries := []*ast.ElementNode{}
+		for _, n := range elem.Children {
+			child, ok := n.(*html.Element)
+			if !ok {
+				continue
+			}
+
+			switch child.TagName {
+			case "title":
+				entries = append(entries, ast.Attr("title", strings.TrimSpace(textOf(child))))
+			case "meta":
+				name, ok1 := child.Attributes["name"].(*html.Attribute)
+				content, ok2 := child.Attributes["content"].(*html.Attribute)
+				if ok1 && ok2 && name.Value != "" && ast.IsValidName(name.Value) {
+					entries = append(entries, ast.Attr(name.Value, content.Value))
+				}
+			}
+		}
+
+		if len(entries) == 0 {
+			return ast.Block{}, true
+		}
+
+		items := []item{}
+		for _, entry := range entries {
+			items = append(items, item{nodes: ast.Block{entry}, block: true})
+		}
+
+		return ast.Block{ast.EN("metadata", c.layout(items, 1))}, true
+	}
+
+	return nil, false
+}
+
+// preformattedCode returns the text and the language of a code block, that is a <pre> element holding only a <code> element with no attributes other than a "language-NAME" class. Other preformatted text keeps its whitespace as an #html.pre element, the content of #code is dedented by the transpilers.
+func preformattedCode(pre *html.Element) (code, language string, ok bool) {
+	if len(pre.Attributes) > 0 || len(pre.Children) != 1 {
+		return "", "", false
+	}
+
+	elem, isElement := pre.Children[0].(*html.Element)
+	if !isElement || elem.TagName != "code" {
+		return "", "", false
+	}
+
+	for name := range elem.Attributes {
+		if name != "class" {
+			return "", "", false
+		}
+	}
+	if classes := elem.Classes(); len(classes) > 0 {
+		if len(classes) > 1 || !strings.HasPrefix(classes[0], "language-") {
+			return "", "", false
+		}
+
+		language = strings.TrimPrefix(classes[0], "language-")
+	}
+
+	return textOf(elem), language, true
+}
+
+// codeBlock returns the content of a #code element for a code block, the lines start on the line after the opening brace and are indented as the nested blocks so the transpilers remove this indentation.
+func (c *htmlConverter) codeBlock(code, language string, depth int) ast.Block {
+	indent := strings.Repeat(c.opts.Indent, depth+1)
+
+	result := ast.Block{}
+	if language != "" {
+		result = append(result, ast.T("\n"+indent), ast.EN("format", ast.B(ast.T(language))))
+	}
+
+	lines := strings.Split(strings.TrimSuffix(code, "\n"), "\n")
+	for i, line := range lines {
+		if strings.TrimSpace(line) != "" {
+			lines[i] = indent + line
+		}
+	}
+
+	// see layout for the space before the closing brace
+	closing := "\n" + strings.Repeat(c.opts.Indent, depth)
+	result = append(result, ast.T("\n"+strings.Join(lines, "\n")+strings.TrimSuffix(closing, " ")))
+
+	return mergeText(result)
+}
+
+// convertAttributes returns the #NAME{ VALUE } entries for the attributes sorted by name, attributes with names that are not valid element names are dropped.
+func (c *htmlConverter) convertAttributes(attributes html.AttributeMap) ast.Block {
+	names := []string{}
+	for name := range attributes {
+		if name != "" && ast.IsValidName(name) {
+			names = append(names, name)
+		}
+	}
+	sort.Strings(names)
+
+	result := ast.Block{}
+	for i, name := range names {
+		if i > 0 {
+			result = append(result, ast.T(" "))
+		}
+
+		switch attr := attributes[name].(type) {
+		case *html.Attribute:
+			result = append(result, ast.Attr(name, attr.Value))
+		default:
+			result = append(result, ast.EN(name, ast.Block{}))
+		}
+	}
+
+	return result
+}
+
+func isPreformatted(tag string) bool {
+	switch tag {
+	case "pre", "textarea", "script", "style":
+		return true
+	}
+
+	return false
+}
+
+// textOf returns the text content of an element
+func textOf(elem *html.Element) string {
+	sb := &strings.Builder{}
+	for _, n := range elem.Children {
+		switch n := n.(type) {
+		case *html.Text:
+			sb.WriteString(n.Value)
+		case *html.Element:
+			sb.WriteString(textOf(n))
+		}
+	}
+
+	return sb.String()
+}
+
+// isCollapsible reports whether a rune is whitespace collapsed by browsers, this excludes non breaking spaces
+func isCollapsible(r rune) bool {
+	return unicode.IsSpace(r) && r != '\u00a0'
+}
+
+// trimBlock removes the leading and trailing spaces of a block
+func trimBlock(block ast.Block) ast.Block {
+	block = mergeText(block)
+
+	if len(block) > 0 {
+		if text, ok := block[0].(*ast.TextNode); ok {
+			block[0] = ast.T(strings.TrimLeftFunc(text.Text, isCollapsible))
+		}
+	}
+	if len(block) > 0 {
+		if text, ok := block[len(block)-1].(*ast.TextNode); ok {
+			block[len(block)-1] = ast.T(strings.TrimRightFunc(text.Text, isCollapsible))
+		}
+	}
+
+	result := ast.Block{}
+	for _, n := range block {
+		if text, ok := n.(*ast.TextNode); ok && text.Text == "" {
+			continue
+		}
+
+		result = append(result, n)
+	}
+
+	return result
+}
+
+func mergeText(block ast.Block) ast.Block {
+	return ast.Normalize(block, ast.MergeText)
+}
@@ -0,0 +1,122 @@
+package importer_test
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/aziis98/textml"
+	"github.com/aziis98/textml/ast"
+	"github.com/aziis98/textml/importer"
+	"github.com/aziis98/textml/runtime/transpile"
+	"github.com/stretchr/testify/assert"
+)
+
+var page = `<!DOCTYPE html>
+<html>
+<head>
+  <title>Tom &amp; Jerry</title>
+  <meta name="description" content="A short page">
+</head>
+<body>
+  <h1>Hello <em>world</em></h1>
+  <p>Some <b>bold</b> text and
+     a <a href="https://example.org/?a=1&amp;b=2">link</a>.</p>
+  <ul class="list"><li>One<li>Two <img src="a.png" alt=""></ul>
+  <pre>  keep
+   this }</pre>
+  <!-- dropped -->
+</body>
+</html>
+`
+
+func convert(t *testing.T, source string, opts importer.HTMLOptions) string {
+	block, err := importer.HTML(strings.NewReader(source), opts)
+	assert.Nil(t, err)
+
+	sb := &strings.Builder{}
+	assert.Nil(t, ast.Print(sb, block))
+
+	return sb.String()
+}
+
+func TestHTML(t *testing.T) {
+	assert.Equal(t, strings.TrimSpace(`
+#html.head{
+    #html.title{ Tom & Jerry }
+    #html.meta{ #content{ A short page } #name{ description } }{}
+}
+
+#html.body{
+    #html.h1{ Hello #html.em{ world } }
+    #html.p{ Some #html.b{ bold } text and a #html.a{ #href{ https://example.org/?a=1&b=2 } }{ link }. }
+    #html.ul{ #class{ list } }{
+        #html.li{ One }
+        #html.li{ Two #html.img{ #alt{} #src{ a.png } }{} }
+    }
+    #html.pre{{   keep
+   this } }}
+}
+`), convert(t, page, importer.HTMLOptions{}))
+}
+
+func TestHTMLDocument(t *testing.T) {
+	assert.Equal(t, strings.TrimSpace(`
+#metadata{
+    #title{ Tom & Jerry }
+    #description{ A short page }
+}
+
+#title{ Hello #italic{ world } }
+
+Some #bold{ bold } text and a #link{ link }{ https://example.org/?a=1&b=2 }.
+
+#html.ul{ #class{ list } }{
+    #html.li{ One }
+    #html.li{ Two #html.img{ #alt{} #src{ a.png } }{} }
+}
+
+#html.pre{{   keep
+   this } }}
+`), convert(t, page, importer.HTMLOptions{Document: true}))
+}
+
+func TestHTMLRoundTrip(t *testing.T) {
+	source := convert(t, page, importer.HTMLOptions{})
+
+	block, err := textml.ParseDocument(strings.NewReader(source))
+	assert.Nil(t, err)
+
+	result := &strings.Builder{}
+	assert.Nil(t, (&transpile.Html{Inline: true}).Transpile(result, block))
+	assert.Equal(t,
+		`<!DOCTYPE html><html><head><title>Tom &amp; Jerry</title><meta content="A short page" name="description"></head>`+
+			`<body><h1>Hello <em>world</em></h1><p>Some <b>bold</b> text and a <a href="https://example.org/?a=1&amp;b=2">link</a>.</p>`+
+			`<ul class="list"><li>One</li><li>Two <img alt="" src="a.png"></li></ul><pre>  keep
+   this }</pre></body></html>`,
+		result.String(),
+	)
+}
+
+func TestHTMLUnknownElements(t *testing.T) {
+	source := `<form><fieldset><legend>Choice</legend><my-el data-x="1">A</my-el></fieldset></form>` +
+		`<noscript>No scripts</noscript><svg width="1"><circle r="1"></circle></svg><x:y>kept</x:y>`
+
+	imported := convert(t, source, importer.HTMLOptions{})
+
+	block, err := textml.ParseDocument(strings.NewReader(imported))
+	assert.Nil(t, err)
+
+	result := &strings.Builder{}
+	assert.Nil(t, (&transpile.Html{Inline: true}).Transpile(result, block))
+	assert.Equal(t,
+		`<!DOCTYPE html><html><form><fieldset><legend>Choice</legend><my-el data-x="1">A</my-el></fieldset></form>`+
+			`<noscript>No scripts</noscript><svg width="1"><circle r="1"></circle></svg>kept</html>`,
+		result.String(),
+	)
+}
+
+func TestHTMLBraces(t *testing.T) {
+	assert.Equal(t, "#html.p{{ if (x) { y } }}\n\n#html.span{{ a } b }}",
+		convert(t, "<p>if (x) { y }</p>a } b", importer.HTMLOptions{Document: true}))
+	assert.Equal(t, "#html.span{{ if (x) { y } }}", convert(t, "if (x) { y }", importer.HTMLOptions{}))
+}
@@ -0,0 +1,811 @@
+package importer
+
+import (
+	stdhtml "html"
+	"io"
+	"regexp"
+	"strconv"
+	"strings"
+	"unicode"
+	"unicode/utf8"
+
+	"github.com/aziis98/textml/ast"
+	"github.com/aziis98/textml/html"
+)
+
+// MarkdownOptions for [Markdown]
+type MarkdownOptions struct {
+	// Indent is the indentation of nested blocks like lists and block quotes, four spaces if empty
+	Indent string
+}
+
+// Markdown reads a Markdown document and converts it to the vocabulary of the runtime/document engine. This supports a subset of CommonMark: ATX and setext headings, paragraphs, emphasis, links, images, code spans, fenced and indented code blocks, lists, block quotes and thematic breaks, together with ~~strikethrough~~ from GitHub Flavored Markdown. A YAML front matter delimited by "---" lines becomes a #metadata element, only "key: value" entries, nested mappings and lists of scalars (joined by commas) are supported.
+//
+// Headings become #title, #subtitle, #subsubtitle and #subsubsubtitle, emphasis becomes #italic and #bold and links become #link{ TEXT }{ URL }. Code blocks become #code{ #format{ LANGUAGE } ... }, lists, block quotes and the other elements without a counterpart in the document vocabulary are written as #html.TAG elements like #html.ul{ #html.li{ ... } }. Lists and quotes in this form are also understood by the html, markdown, latex and text formats of the transpile package.
+func Markdown(r io.Reader, opts MarkdownOptions) (ast.Block, error) {
+	source, err := io.ReadAll(r)
+	if err != nil {
+		return nil, err
+	}
+
+	if opts.Indent == "" {
+		opts.Indent = "    "
+	}
+
+	lines := strings.Split(strings.ReplaceAll(string(source), "\r\n", "\n"), "\n")
+	frontMatter, lines := splitFrontMatter(lines)
+
+	c := &htmlConverter{opts: HTMLOptions{Document: true, Indent: opts.Indent}}
+	block := c.convertChildren(parseMarkdownBlocks(lines), 0, false, true)
+
+	if frontMatter == nil {
+		return block, nil
+	}
+
+	metadata := ast.EN("metadata", c.layout(c.frontMatterEntries(frontMatter, 1), 1))
+	if len(block) == 0 {
+		return ast.Block{metadata}, nil
+	}
+
+	return append(ast.Block{metadata, ast.T("\n\n")}, block...), nil
+}
+
+//
+// Front matter
+//
+
+// splitFrontMatter returns the lines of the front matter, or nil if there is none, and the remaining lines.
+func splitFrontMatter(lines []string) ([]string, []string) {
+	if len(lines) == 0 || strings.TrimRight(lines[0], " ") != "---" {
+		return nil, lines
+	}
+
+	for i := 1; i < len(lines); i++ {
+		if line := strings.TrimRight(lines[i], " "); line == "---" || line == "..." {
+			return lines[1:i], lines[i+1:]
+		}
+	}
+
+	return nil, lines
+}
+
+// frontMatterEntries converts the "key: value" lines of a YAML mapping to entries laid out at the given depth, the lines of nested mappings and lists are those with a greater indentation than their key.
+func (c *htmlConverter) frontMatterEntries(lines []string, depth int) []item {
+	items := []item{}
+
+	for i := 0; i < len(lines); i++ {
+		line := lines[i]
+		if isBlank(line) || strings.HasPrefix(strings.TrimSpace(line), "#") {
+			continue
+		}
+
+		indent := indentation(line)
+		key, value, ok := strings.Cut(strings.TrimSpace(line), ":")
+		key = unquoteYAML(strings.TrimSpace(key))
+		value = strings.TrimSpace(value)
+
+		// the lines of a nested value
+		nested := []string{}
+		for i+1 < len(lines) && (isBlank(lines[i+1]) || indentation(lines[i+1]) > indent || strings.HasPrefix(strings.TrimSpace(lines[i+1]), "- ")) {
+			i++
+			nested = append(nested, lines[i])
+		}
+
+		if !ok || key == "" || !ast.IsValidName(key) {
+			continue
+		}
+
+		var entry *ast.ElementNode
+		switch {
+		case value != "":
+			entry = ast.Attr(key, yamlScalar(value))
+
+		case isYAMLList(nested):
+			values := []string{}
+			for _, line := range nested {
+				if item := strings.TrimSpace(line); item != "" {
+					values = append(values, unquoteYAML(strings.TrimSpace(strings.TrimPrefix(item, "-"))))
+				}
+			}
+
+			entry = ast.Attr(key, strings.Join(values, ", "))
+
+		default:
+			entry = ast.EN(key, ast.Block{})
+			if entries := c.frontMatterEntries(dedentLines(nested), depth+1); len(entries) > 0 {
+				entry.Arguments[0] = c.layout(entries, depth+1)
+			}
+		}
+
+		items = append(items, item{nodes: ast.Block{entry}, block: true})
+	}
+
+	return items
+}
+
+func isYAMLList(lines []string) bool {
+	for _, line := range lines {
+		if !isBlank(line) {
+			return strings.HasPrefix(strings.TrimSpace(line), "- ")
+		}
+	}
+
+	return false
+}
+
+// yamlScalar returns the value of a scalar, flow sequences like "[a, b]" are joined by commas.
+func yamlScalar(value string) string {
+	if strings.HasPrefix(value, "[") && strings.HasSuffix(value, "]") {
+		values := []string{}
+		for _, v := range strings.Split(value[1:len(value)-1], ",") {
+			if v = strings.TrimSpace(v); v != "" {
+				values = append(values, unquoteYAML(v))
+			}
+		}
+
+		return strings.Join(values, ", ")
+	}
+
+	return unquoteYAML(value)
+}
+
+func unquoteYAML(s string) string {
+	if len(s) >= 2 && s[0] == '"' && s[len(s)-1] == '"' {
+		if unquoted, err := strconv.Unquote(s); err == nil {
+			return unquoted
+		}
+	}
+	if len(s) >= 2 && s[0] == '\'' && s[len(s)-1] == '\'' {
+		return strings.ReplaceAll(s[1:len(s)-1], "''", "'")
+	}
+
+	return s
+}
+
+//
+// Blocks
+//
+
+var (
+	regexATXHeading    = regexp.MustCompile(`^ {0,3}(#{1,6})(?:[ \t]+(.*?))?(?:[ \t]+#+)?[ \t]*$`)
+	regexThematicBreak = regexp.MustCompile(`^ {0,3}(?:(?:-[ \t]*){3,}|(?:\*[ \t]*){3,}|(?:_[ \t]*){3,})$`)
+	regexFence         = regexp.MustCompile("^( {0,3})(`{3,}|~{3,})[ \t]*([^`]*)$")
+	regexBlockQuote    = regexp.MustCompile(`^ {0,3}> ?`)
+	regexListItem      = regexp.MustCompile(`^( {0,3})([-*+]|\d{1,9}[.)])([ \t]+|$)`)
+	regexSetextLine    = regexp.MustCompile(`^ {0,3}(=+|-+)[ \t]*$`)
+)
+
+func isBlank(line string) bool {
+	return strings.TrimSpace(line) == ""
+}
+
+// indentation returns the number of leading spaces of a line, tabs count as four spaces.
+func indentation(line string) int {
+	n := 0
+	for _, r := range line {
+		switch r {
+		case ' ':
+			n++
+		case '\t':
+			n += 4
+		default:
+			return n
+		}
+	}
+
+	return n
+}
+
+// removeIndentation removes up to n columns of leading whitespace.
+func removeIndentation(line string, n int) string {
+	for n > 0 && line != "" {
+		switch line[0] {
+		case ' ':
+			n--
+		case '\t':
+			n -= 4
+		default:
+			return line
+		}
+
+		line = line[1:]
+	}
+
+	return line
+}
+
+func dedentLines(lines []string) []string {
+	indent := -1
+	for _, line := range lines {
+		if !isBlank(line) && (indent < 0 || indentation(line) < indent) {
+			indent = indentation(line)
+		}
+	}
+
+	result := []string{}
+	for _, line := range lines {
+		result = append(result, removeIndentation(line, indent))
+	}
+
+	return result
+}
+
+// interruptsParagraph reports whether the line starts a block that ends a paragraph.
+func interruptsParagraph(line string) bool {
+	if regexATXHeading.MatchString(line) || regexThematicBreak.MatchString(line) || regexFence.MatchString(line) || regexBlockQuote.MatchString(line) {
+		return true
+	}
+
+	// only lists starting with 1 and with some content interrupt paragraphs
+	if m := regexListItem.FindStringSubmatch(line); m != nil && !isBlank(line[len(m[0]):]) {
+		marker := m[2]
+		return !unicode.IsDigit(rune(marker[0])) || strings.TrimLeft(marker[:len(marker)-1], "0") == "1"
+	}
+
+	return false
+}
+
+// parseMarkdownBlocks parses the block structure of a document to HTML nodes.
+func parseMarkdownBlocks(lines []string) []html.Node {
+	nodes := []html.Node{}
+
+	for i := 0; i < len(lines); {
+		line := lines[i]
+
+		switch {
+		case isBlank(line):
+			i++
+
+		case regexFence.MatchString(line):
+			m := regexFence.FindStringSubmatch(line)
+			indent, fence, info := len(m[1]), m[2], strings.TrimSpace(m[3])
+
+			content := []string{}
+			for i++; i < len(lines); i++ {
+				closing := strings.TrimSpace(lines[i])
+				if indentation(lines[i]) < 4 && strings.HasPrefix(closing, fence) && strings.Trim(closing, fence[:1]) == "" {
+					i++
+					break
+				}
+
+				content = append(content, removeIndentation(lines[i], indent))
+			}
+
+			language, _, _ := strings.Cut(info, " ")
+			nodes = append(nodes, codeBlock(content, stdhtml.UnescapeString(language)))
+
+		case indentation(line) >= 4:
+			content := []string{}
+			for ; i < len(lines) && (isBlank(lines[i]) || indentation(lines[i]) >= 4); i++ {
+				content = append(content, removeIndentation(lines[i], 4))
+			}
+			for len(content) > 0 && isBlank(content[len(content)-1]) {
+				content = content[:len(content)-1]
+			}
+
+			nodes = append(nodes, codeBlock(content, ""))
+
+		case regexATXHeading.MatchString(line):
+			m := regexATXHeading.FindStringSubmatch(line)
+			nodes = append(nodes, html.NewElementNode("h"+strconv.Itoa(len(m[1])), nil, parseMarkdownInline(m[2])))
+			i++
+
+		case regexThematicBreak.MatchString(line):
+			nodes = append(nodes, html.NewElementNode("hr", nil, nil))
+			i++
+
+		case regexBlockQuote.MatchString(line):
+			content := []string{}
+			for ; i < len(lines); i++ {
+				if m := regexBlockQuote.FindString(lines[i]); m != "" {
+					content = append(content, lines[i][len(m):])
+					continue
+				}
+
+				// lazy continuation lines of a paragraph
+				if isBlank(lines[i]) || interruptsParagraph(lines[i]) || len(content) == 0 || isBlank(content[len(content)-1]) {
+					break
+				}
+				content = append(content, lines[i])
+			}
+
+			nodes = append(nodes, html.NewElementNode("blockquote", nil, parseMarkdownBlocks(content)))
+
+		case regexListItem.MatchString(line):
+			var list html.Node
+			list, i = parseMarkdownList(lines, i)
+			nodes = append(nodes, list)
+
+		default:
+			paragraph := []string{}
+			heading := ""
+			for ; i < len(lines) && !isBlank(lines[i]); i++ {
+				if len(paragraph) > 0 && regexSetextLine.MatchString(lines[i]) {
+					heading = map[byte]string{'=': "h1", '-': "h2"}[strings.TrimSpace(lines[i])[0]]
+					i++
+					break
+				}
+				if len(paragraph) > 0 && interruptsParagraph(lines[i]) {
+					break
+				}
+
+				paragraph = append(paragraph, strings.TrimLeft(lines[i], " \t"))
+			}
+
+			tag := "p"
+			if heading != "" {
+				tag = heading
+			}
+
+			nodes = append(nodes, html.NewElementNode(tag, nil, parseMarkdownInline(strings.Join(paragraph, "\n"))))
+		}
+	}
+
+	return nodes
+}
+
+func codeBlock(lines []string, language string) html.Node {
+	attributes := html.AttributeMap{}
+	if language != "" {
+		attributes["class"] = &html.Attribute{Value: "language-" + language}
+	}
+
+	content := strings.Join(lines, "\n")
+	if len(lines) > 0 {
+		content += "\n"
+	}
+
+	return html.NewElementNode("pre", nil, []html.Node{
+		html.NewElementNode("code", attributes, []html.Node{html.NewTextNode(content)}),
+	})
+}
+
+// parseMarkdownList parses the list starting at line i and returns the index of the line after it.
+func parseMarkdownList(lines []string, i int) (html.Node, int) {
+	first := regexListItem.FindStringSubmatch(lines[i])
+	ordered := unicode.IsDigit(rune(first[2][0]))
+	delimiter := first[2][len(first[2])-1:]
+
+	items := [][]string{}
+	loose := false
+
+	// sameList returns the match of an item of the list at line i
+	sameList := func(i int) []string {
+		if i >= len(lines) {
+			return nil
+		}
+
+		m := regexListItem.FindStringSubmatch(lines[i])
+		if m == nil || unicode.IsDigit(rune(m[2][0])) != ordered || m[2][len(m[2])-1:] != delimiter {
+			return nil
+		}
+
+		return m
+	}
+
+	for i < len(lines) {
+		m := sameList(i)
+		if m == nil {
+			break
+		}
+
+		// the content of the item starts after the marker and at most four spaces
+		contentIndent := len(m[0])
+		if spaces := len(m[3]); spaces > 4 || isBlank(lines[i][len(m[0]):]) {
+			contentIndent = len(m[1]) + len(m[2]) + 1
+		}
+
+		content := []string{strings.TrimLeft(lines[i][len(m[0]):], " \t")}
+		for i++; i < len(lines); i++ {
+			line := lines[i]
+			if isBlank(line) {
+				content = append(content, "")
+				continue
+			}
+			if indentation(line) >= contentIndent {
+				content = append(content, removeIndentation(line, contentIndent))
+				continue
+			}
+
+			// lazy continuation lines of a paragraph
+			if !isBlank(content[len(content)-1]) && !interruptsParagraph(line) && !regexListItem.MatchString(line) {
+				content = append(content, strings.TrimLeft(line, " \t"))
+				continue
+			}
+
+			break
+		}
+
+		// blank lines between items or between the blocks of an item make the list loose
+		trailing := 0
+		for len(content) > 0 && isBlank(content[len(content)-1]) {
+			content = content[:len(content)-1]
+			trailing++
+		}
+		if trailing > 0 && sameList(i) != nil {
+			loose = true
+		}
+		for _, line := range content {
+			if isBlank(line) {
+				loose = true
+			}
+		}
+
+		items = append(items, content)
+	}
+
+	children := []html.Node{}
+	for _, content := range items {
+		blocks := parseMarkdownBlocks(content)
+		if !loose {
+			blocks = unwrapParagraphs(blocks)
+		}
+
+		children = append(children, html.NewElementNode("li", nil, blocks))
+	}
+
+	attributes := html.AttributeMap{}
+	tag := "ul"
+	if ordered {
+		tag = "ol"
+
+		start := strings.TrimLeft(first[2][:len(first[2])-1], "0")
+		if start != "1" {
+			if start == "" {
+				start = "0"
+			}
+			attributes["start"] = &html.Attribute{Value: start}
+		}
+	}
+
+	return html.NewElementNode(tag, attributes, children), i
+}
+
+// unwrapParagraphs replaces paragraphs with their content, this is used for items of tight lists.
+func unwrapParagraphs(nodes []html.Node) []html.Node {
+	result := []html.Node{}
+	for _, n := range nodes {
+		if elem, ok := n.(*html.Element); ok && elem.TagName == "p" {
+			result = append(result, elem.Children...)
+			continue
+		}
+
+		result = append(result, n)
+	}
+
+	return result
+}
+
+//
+// Inlines
+//
+
+var regexAutolink = regexp.MustCompile(`^<([a-zA-Z][a-zA-Z0-9+.\-]{1,31}:[^\s<>]*|[a-zA-Z0-9.!#$%&'*+/=?^_{|}~\-]+@[a-zA-Z0-9](?:[a-zA-Z0-9\-.]*[a-zA-Z0-9])?)>`)
+
+// parseMarkdownInline parses the inline content of a paragraph or heading.
+func parseMarkdownInline(s string) []html.Node {
+	p := &inlineParser{}
+	p.parse(s)
+	p.flush()
+
+	return p.nodes
+}
+
+type inlineParser struct {
+	nodes []html.Node
+	text  strings.Builder
+}
+
+func (p *inlineParser) flush() {
+	if p.text.Len() > 0 {
+		p.nodes = append(p.nodes, html.NewTextNode(p.text.String()))
+		p.text.Reset()
+	}
+}
+
+func (p *inlineParser) append(n html.Node) {
+	p.flush()
+	p.nodes = append(p.nodes, n)
+}
+
+func isPunctuation(r rune) bool {
+	return unicode.IsPunct(r) || unicode.IsSymbol(r)
+}
+
+// isEscapable reports whether a backslash before the character escapes it, this is the case for ASCII punctuation
+func isEscapable(c byte) bool {
+	return c < utf8.RuneSelf && isPunctuation(rune(c))
+}
+
+func (p *inlineParser) parse(s string) {
+	for i := 0; i < len(s); {
+		c := s[i]
+
+		switch {
+		case c == '\\' && i+1 < len(s) && s[i+1] == '\n':
+			// the newline is kept as for breaks made by trailing spaces
+			p.append(html.NewElementNode("br", nil, nil))
+			i++
+
+		case c == '\\' && i+1 < len(s) && isEscapable(s[i+1]):
+			p.text.WriteByte(s[i+1])
+			i += 2
+
+		case c == '\n':
+			// two or more spaces at the end of a line are a hard line break
+			text := p.text.String()
+			if trimmed := strings.TrimRight(text, " "); len(text)-len(trimmed) >= 2 {
+				p.text.Reset()
+				p.text.WriteString(trimmed)
+				p.append(html.NewElementNode("br", nil, nil))
+			}
+			p.text.WriteByte('\n')
+			i++
+
+		case c == '`':
+			if n, ok := p.parseCodeSpan(s[i:]); ok {
+				i += n
+				continue
+			}
+
+			run := len(s[i:]) - len(strings.TrimLeft(s[i:], "`"))
+			p.text.WriteString(s[i : i+run])
+			i += run
+
+		case c == '<' && regexAutolink.MatchString(s[i:]):
+			m := regexAutolink.FindStringSubmatch(s[i:])
+			href := m[1]
+			if !strings.Contains(href, ":") {
+				href = "mailto:" + href
+			}
+
+			p.append(html.NewElementNode("a", html.AttributeMap{"href": &html.Attribute{Value: href}}, []html.Node{html.NewTextNode(m[1])}))
+			i += len(m[0])
+
+		case c == '[' || c == '!' && strings.HasPrefix(s[i:], "!["):
+			if n, ok := p.parseLink(s[i:]); ok {
+				i += n
+				continue
+			}
+
+			p.text.WriteByte(c)
+			i++
+
+		case c == '*' || c == '_' || c == '~':
+			if n, ok := p.parseEmphasis(s, i); ok {
+				i += n
+				continue
+			}
+
+			run := len(s[i:]) - len(strings.TrimLeft(s[i:], string(c)))
+			p.text.WriteString(s[i : i+run])
+			i += run
+
+		case c == '&':
+			end := strings.IndexByte(s[i:], ';')
+			if end > 0 && end < 32 {
+				if decoded := stdhtml.UnescapeString(s[i : i+end+1]); decoded != s[i:i+end+1] {
+					p.text.WriteString(decoded)
+					i += end + 1
+					continue
+				}
+			}
+
+			p.text.WriteByte(c)
+			i++
+
+		default:
+			p.text.WriteByte(c)
+			i++
+		}
+	}
+}
+
+// parseCodeSpan parses a code span at the start of s and returns its length.
+func (p *inlineParser) parseCodeSpan(s string) (int, bool) {
+	run := len(s) - len(strings.TrimLeft(s, "`"))
+	fence := s[:run]
+
+	for offset := run; offset < len(s); {
+		k := strings.Index(s[offset:], fence)
+		if k < 0 {
+			return 0, false
+		}
+
+		start := offset + k
+		end := start + run
+		if end < len(s) && s[end] == '`' || s[start-1] == '`' {
+			// a longer run of backticks
+			offset = end + len(s[end:]) - len(strings.TrimLeft(s[end:], "`"))
+			continue
+		}
+
+		content := strings.ReplaceAll(s[run:start], "\n", " ")
+		if len(content) >= 2 && content[0] == ' ' && content[len(content)-1] == ' ' && strings.TrimSpace(content) != "" {
+			content = content[1 : len(content)-1]
+		}
+
+		p.append(html.NewElementNode("code", nil, []html.Node{html.NewTextNode(content)}))
+		return end, true
+	}
+
+	return 0, false
+}
+
+// parseLink parses a link or an image at the start of s and returns its length, only inline links like [TEXT](URL "TITLE") are supported.
+func (p *inlineParser) parseLink(s string) (int, bool) {
+	image := strings.HasPrefix(s, "!")
+	start := 1
+	if image {
+		start = 2
+	}
+
+	// the closing bracket, skipping nested brackets and code spans
+	depth, end := 1, -1
+	for i := start; i < len(s) && end < 0; i++ {
+		switch s[i] {
+		case '\\':
+			i++
+		case '`':
+			run := len(s[i:]) - len(strings.TrimLeft(s[i:], "`"))
+			if k := strings.Index(s[i+run:], s[i:i+run]); k >= 0 {
+				i += run + k + run - 1
+			}
+		case '[':
+			depth++
+		case ']':
+			depth--
+			if depth == 0 {
+				end = i
+			}
+		}
+	}
+	if end < 0 || end+1 >= len(s) || s[end+1] != '(' {
+		return 0, false
+	}
+
+	// the closing parenthesis, destinations can contain balanced parentheses
+	closing := -1
+	for i, parens := end+2, 0; i < len(s) && closing < 0; i++ {
+		switch s[i] {
+		case '\\':
+			i++
+		case '(':
+			parens++
+		case ')':
+			if parens == 0 {
+				closing = i
+			}
+			parens--
+		}
+	}
+	if closing < 0 {
+		return 0, false
+	}
+	inside := strings.TrimSpace(s[end+2 : closing])
+
+	destination, title := inside, ""
+	if strings.HasPrefix(inside, "<") {
+		k := strings.IndexByte(inside, '>')
+		if k < 0 {
+			return 0, false
+		}
+
+		destination, title = inside[1:k], strings.TrimSpace(inside[k+1:])
+	} else if k := strings.IndexAny(inside, " \t\n"); k >= 0 {
+		destination, title = inside[:k], strings.TrimSpace(inside[k:])
+	}
+
+	if title != "" {
+		if len(title) < 2 || !strings.ContainsRune(`"')`, rune(title[len(title)-1])) {
+			return 0, false
+		}
+		title = title[1 : len(title)-1]
+	}
+
+	attributes := html.AttributeMap{}
+	if title != "" {
+		attributes["title"] = &html.Attribute{Value: unescapeMarkdown(title)}
+	}
+
+	label := s[start:end]
+	if image {
+		attributes["src"] = &html.Attribute{Value: unescapeMarkdown(destination)}
+		attributes["alt"] = &html.Attribute{Value: textOf(html.NewElementNode("", nil, parseMarkdownInline(label)))}
+		p.append(html.NewElementNode("img", attributes, nil))
+	} else {
+		attributes["href"] = &html.Attribute{Value: unescapeMarkdown(destination)}
+		p.append(html.NewElementNode("a", attributes, parseMarkdownInline(label)))
+	}
+
+	return closing + 1, true
+}
+
+// unescapeMarkdown removes backslash escapes and decodes entities
+func unescapeMarkdown(s string) string {
+	sb := &strings.Builder{}
+	for i := 0; i < len(s); i++ {
+		if s[i] == '\\' && i+1 < len(s) && isEscapable(s[i+1]) {
+			i++
+		}
+		sb.WriteByte(s[i])
+	}
+
+	return stdhtml.UnescapeString(sb.String())
+}
+
+// delimiterRun returns the length of the run of the delimiter at index i and whether it can open and close emphasis, following the flanking rules of CommonMark.
+func delimiterRun(s string, i int) (n int, canOpen, canClose bool) {
+	c := s[i]
+	n = len(s[i:]) - len(strings.TrimLeft(s[i:], string(c)))
+
+	before, after := ' ', ' '
+	if i > 0 {
+		before, _ = utf8.DecodeLastRuneInString(s[:i])
+	}
+	if i+n < len(s) {
+		after, _ = utf8.DecodeRuneInString(s[i+n:])
+	}
+
+	leftFlanking := !unicode.IsSpace(after) && (!isPunctuation(after) || unicode.IsSpace(before) || isPunctuation(before))
+	rightFlanking := !unicode.IsSpace(before) && (!isPunctuation(before) || unicode.IsSpace(after) || isPunctuation(after))
+
+	if c == '_' {
+		// underscores inside words like snake_case are not emphasis
+		return n, leftFlanking && (!rightFlanking || isPunctuation(before)), rightFlanking && (!leftFlanking || isPunctuation(after))
+	}
+
+	return n, leftFlanking, rightFlanking
+}
+
+// parseEmphasis parses emphasis opened by the delimiter run at index i and returns the length of the whole span, the closing run must have the same length as the opening one.
+func (p *inlineParser) parseEmphasis(s string, i int) (int, bool) {
+	n, canOpen, _ := delimiterRun(s, i)
+	c := s[i]
+	if !canOpen || n > 3 || c == '~' && n != 2 {
+		return 0, false
+	}
+
+	for k := i + n; k < len(s); k++ {
+		switch s[k] {
+		case '\\':
+			k++
+			continue
+		case '`':
+			// delimiters in code spans don't count
+			run := len(s[k:]) - len(strings.TrimLeft(s[k:], "`"))
+			if end := strings.Index(s[k+run:], s[k:k+run]); end >= 0 {
+				k += run + end + run - 1
+			}
+			continue
+		case c:
+		default:
+			continue
+		}
+
+		m, _, canClose := delimiterRun(s, k)
+		if !canClose || m != n {
+			k += m - 1
+			continue
+		}
+
+		children := parseMarkdownInline(s[i+n : k])
+
+		var node html.Node
+		switch {
+		case c == '~':
+			node = html.NewElementNode("del", nil, children)
+		case n == 1:
+			node = html.NewElementNode("em", nil, children)
+		case n == 2:
+			node = html.NewElementNode("strong", nil, children)
+		default:
+			node = html.NewElementNode("strong", nil, []html.Node{html.NewElementNode("em", nil, children)})
+		}
+
+		p.append(node)
+		return k + m - i, true
+	}
+
+	return 0, false
+}
@@ -0,0 +1,169 @@
+package importer_test
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/aziis98/textml"
+	"github.com/aziis98/textml/ast"
+	"github.com/aziis98/textml/html"
+	"github.com/aziis98/textml/importer"
+	"github.com/aziis98/textml/runtime/document"
+	"github.com/aziis98/textml/runtime/transpile"
+	"github.com/stretchr/testify/assert"
+)
+
+func convertMarkdown(t *testing.T, source string) string {
+	block, err := importer.Markdown(strings.NewReader(source), importer.MarkdownOptions{})
+	assert.Nil(t, err)
+
+	sb := &strings.Builder{}
+	assert.Nil(t, ast.Print(sb, block))
+
+	return sb.String()
+}
+
+var post = strings.TrimLeft(`
+---
+title: "An example"
+tags: [example, tag-1]
+authors:
+  - Alice
+  - 'Bob'
+extra:
+  a: 1
+---
+
+# Hello *world*
+
+Some **bold**, _italic_, ***both*** and `+"`code {x}`"+` text
+with a [link](https://example.org/a_(b) "Title") and snake_case_name.
+
+- one
+- two
+  - nested
+
+> quoted
+continued
+
+`+"```go"+`
+fmt.Println("}")
+`+"```"+`
+`, "\n")
+
+func TestMarkdown(t *testing.T) {
+	assert.Equal(t, strings.TrimSpace(`
+#metadata{
+    #title{ An example }
+    #tags{ example, tag-1 }
+    #authors{ Alice, Bob }
+    #extra{
+        #a{ 1 }
+    }
+}
+
+#title{ Hello #italic{ world } }
+
+Some #bold{ bold }, #italic{ italic }, #bold{ #italic{ both } } and #code{{ code {x} }} text with a #link{ link }{ https://example.org/a_(b) } and snake_case_name.
+
+#html.ul{
+    #html.li{ one }
+    #html.li{
+        two
+        #html.ul{
+            #html.li{ nested }
+        }
+    }
+}
+
+#html.blockquote{
+    quoted continued
+}
+
+#code{{
+    #format{{ go }}
+    fmt.Println("}")
+}}
+`), convertMarkdown(t, post))
+}
+
+func TestMarkdownBlocks(t *testing.T) {
+	cases := map[string]string{
+		"Title\n=====\n\nSub\n---":         "#title{ Title }\n\n#subtitle{ Sub }",
+		"##### Small ##":                   "#html.h5{ Small }",
+		"a\n\n***\n\nb":                    "a\n\n#html.hr{}\n\nb",
+		"    code\n      more\n":           "#code{\n    code\n      more\n}",
+		"3. c\n4. d":                       "#html.ol{ #start{ 3 } }{\n    #html.li{ c }\n    #html.li{ d }\n}",
+		"1. a\n\n   b\n2. c":               "#html.ol{\n    #html.li{\n        a\n\n        b\n    }\n    #html.li{\n        c\n    }\n}",
+		"line  \nbreak\\\nagain":           "line#html.br{} break#html.br{} again",
+		`\*not\* <mail@example.org> &amp;`: "*not* #link{ mail@example.org }{ mailto:mail@example.org } &",
+		"~~old~~ ![alt *text*](a.png)":     "#strikethrough{ old } #html.img{ #alt{ alt text } #src{ a.png } }{}",
+		"`` a ` b `` and `unclosed":        "#code{ a ` b } and `unclosed",
+		"**not closed and *this*":          "**not closed and #italic{ this }",
+	}
+
+	for source, expected := range cases {
+		assert.Equal(t, expected, convertMarkdown(t, source), source)
+	}
+}
+
+func TestMarkdownRender(t *testing.T) {
+	block, err := textml.ParseDocument(strings.NewReader(convertMarkdown(t, post)))
+	assert.Nil(t, err)
+
+	metadata, nodes, err := (&document.Engine{}).Render(block)
+	assert.Nil(t, err)
+	assert.Equal(t, "An example", metadata["title"])
+	assert.Equal(t, map[string]any{"a": "1"}, metadata["extra"])
+	assert.Contains(t, html.PrintToString(nodes, html.PrintOptions{Minify: true}),
+		`<pre><code class="language-go">fmt.Println("}")</code></pre>`,
+	)
+}
+
+func TestMarkdownBraces(t *testing.T) {
+	assert.Equal(t, strings.TrimSpace(`
+#html.p{{ if (x) { y } }}
+
+#html.p{{ Some #code{{ code }} and #html.p{ text } }}
+
+#html.p{{ a #bold{{ b }}{ c } }}
+`), convertMarkdown(t, "if (x) { y }\n\nSome `code` and #html.p{ text }\n\na **b**{ c }\n"))
+}
+
+func TestMarkdownTranspile(t *testing.T) {
+	block, err := textml.ParseDocument(strings.NewReader(convertMarkdown(t, post)))
+	assert.Nil(t, err)
+
+	expected := map[string][]string{
+		"html.inline": {
+			`<title>An example</title>`,
+			`<h1>Hello <i>world</i></h1>`,
+			`<li>two<ul><li>nested</li></ul></li>`,
+			`<blockquote>quoted continued</blockquote>`,
+			`<pre><code class="language-go">fmt.Println("}")</code></pre>`,
+		},
+		"markdown": {
+			"title: An example\n",
+			"# Hello *world*\n",
+			"<ul><li>one</li><li>two<ul><li>nested</li></ul></li></ul>\n",
+			"```go\nfmt.Println(\"}\")\n```\n",
+		},
+		"latex": {
+			`\title{An example}`,
+			"\\begin{itemize}\n\\item one\n\\item two\n\n\\begin{itemize}\n\\item nested\n\\end{itemize}\n\\end{itemize}",
+			"\\begin{quote}\nquoted continued\n\\end{quote}",
+			"\\begin{verbatim}\nfmt.Println(\"}\")\n\\end{verbatim}",
+		},
+	}
+
+	for format, parts := range expected {
+		tr, err := transpile.New(format, nil)
+		assert.Nil(t, err)
+
+		sb := &strings.Builder{}
+		assert.Nil(t, tr.Transpile(sb, block), format)
+		for _, part := range parts {
+			assert.Contains(t, sb.String(), part, format)
+		}
+	}
+}
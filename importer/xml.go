@@ -0,0 +1,216 @@
+package importer
+
+import (
+	"encoding/xml"
+	"fmt"
+	"io"
+	"strings"
+
+	"github.com/aziis98/textml/ast"
+)
+
+// XMLOptions for [XML]
+type XMLOptions struct {
+	// Arg is the name of the elements holding arguments, "arg" if empty
+	Arg string
+}
+
+// xmlElement is an element of the tree read by [XML], children are strings or elements
+type xmlElement struct {
+	name     xml.Name
+	attrs    []xml.Attr
+	children []any
+}
+
+// XML reads an XML document in the format written by transpile.Xml and converts the content of its root element back to TextML. Prefixed names like <html:div> become #html.div, an element whose children are only <arg> elements (and whitespace) gets them as arguments and other elements get their content as a single argument, so documents written with the compact option are also read back.
+//
+// Attributes of elements from other tools become a first argument of #NAME{ VALUE } entries as done by [HTML], comments and processing instructions are dropped.
+func XML(r io.Reader, opts XMLOptions) (ast.Block, error) {
+	if opts.Arg == "" {
+		opts.Arg = "arg"
+	}
+
+	d := xml.NewDecoder(r)
+
+	var root *xmlElement
+	stack := []*xmlElement{}
+
+	for {
+		tok, err := d.RawToken()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return nil, err
+		}
+
+		switch tok := tok.(type) {
+		case xml.StartElement:
+			tok = tok.Copy()
+			elem := &xmlElement{name: tok.Name, attrs: tok.Attr}
+
+			if len(stack) == 0 {
+				if root != nil {
+					return nil, fmt.Errorf("offset %d: the document has more than one root element", d.InputOffset())
+				}
+
+				root = elem
+			} else {
+				parent := stack[len(stack)-1]
+				parent.children = append(parent.children, elem)
+			}
+
+			stack = append(stack, elem)
+
+		case xml.EndElement:
+			if len(stack) == 0 || stack[len(stack)-1].name != tok.Name {
+				return nil, fmt.Errorf("offset %d: unexpected closing tag </%s>", d.InputOffset(), xmlName(tok.Name))
+			}
+
+			stack = stack[:len(stack)-1]
+
+		case xml.CharData:
+			if len(stack) == 0 {
+				if strings.TrimSpace(string(tok)) != "" {
+					return nil, fmt.Errorf("offset %d: text outside of the root element", d.InputOffset())
+				}
+				continue
+			}
+
+			// text split by comments or CDATA sections is joined back
+			parent := stack[len(stack)-1]
+			if n := len(parent.children); n > 0 {
+				if text, ok := parent.children[n-1].(string); ok {
+					parent.children[n-1] = text + string(tok)
+					continue
+				}
+			}
+
+			parent.children = append(parent.children, string(tok))
+		}
+	}
+
+	if len(stack) > 0 {
+		return nil, fmt.Errorf("unclosed element <%s>", xmlName(stack[len(stack)-1].name))
+	}
+	if root == nil {
+		return nil, fmt.Errorf("the document has no root element")
+	}
+
+	return convertXMLChildren(root.children, opts)
+}
+
+// xmlName returns the name as written in the document
+func xmlName(name xml.Name) string {
+	if name.Space == "" {
+		return name.Local
+	}
+
+	return name.Space + ":" + name.Local
+}
+
+// textmlName converts a name to an element name, the prefix becomes the namespace.
+func textmlName(name xml.Name) (string, error) {
+	result := name.Local
+	if name.Space != "" {
+		result = name.Space + "." + name.Local
+	}
+
+	if !ast.IsValidName(result) {
+		return "", fmt.Errorf("the XML name %q can't be used as an element name", xmlName(name))
+	}
+
+	return result, nil
+}
+
+func convertXMLChildren(children []any, opts XMLOptions) (ast.Block, error) {
+	block := ast.Block{}
+
+	for _, child := range children {
+		switch child := child.(type) {
+		case string:
+			block = append(block, ast.T(child))
+		case *xmlElement:
+			elem, err := convertXMLElement(child, opts)
+			if err != nil {
+				return nil, err
+			}
+
+			block = append(block, elem)
+		}
+	}
+
+	return block, nil
+}
+
+func convertXMLElement(elem *xmlElement, opts XMLOptions) (*ast.ElementNode, error) {
+	name, err := textmlName(elem.name)
+	if err != nil {
+		return nil, err
+	}
+
+	args := []ast.Block{}
+
+	attrs := ast.Block{}
+	for _, attr := range elem.attrs {
+		if attr.Name.Space == "xmlns" || attr.Name.Space == "" && attr.Name.Local == "xmlns" {
+			continue
+		}
+
+		attrName, err := textmlName(attr.Name)
+		if err != nil {
+			return nil, err
+		}
+
+		attrs = append(attrs, ast.Attr(attrName, attr.Value))
+	}
+	if len(attrs) > 0 {
+		args = append(args, attrs)
+	}
+
+	switch {
+	case isXMLArguments(elem.children, opts):
+		for _, child := range elem.children {
+			if arg, ok := child.(*xmlElement); ok {
+				block, err := convertXMLChildren(arg.children, opts)
+				if err != nil {
+					return nil, err
+				}
+
+				args = append(args, block)
+			}
+		}
+
+	case len(elem.children) > 0 || len(attrs) > 0:
+		block, err := convertXMLChildren(elem.children, opts)
+		if err != nil {
+			return nil, err
+		}
+
+		args = append(args, block)
+	}
+
+	return ast.EN(name, args...), nil
+}
+
+// isXMLArguments reports whether the children are argument elements separated by whitespace.
+func isXMLArguments(children []any, opts XMLOptions) bool {
+	found := false
+
+	for _, child := range children {
+		switch child := child.(type) {
+		case string:
+			if strings.TrimSpace(child) != "" {
+				return false
+			}
+		case *xmlElement:
+			if child.name.Space != "" || child.name.Local != opts.Arg {
+				return false
+			}
+
+			found = true
+		}
+	}
+
+	return found
+}
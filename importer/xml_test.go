@@ -0,0 +1,83 @@
+package importer_test
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/aziis98/textml"
+	"github.com/aziis98/textml/ast"
+	"github.com/aziis98/textml/importer"
+	"github.com/aziis98/textml/runtime/transpile"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestXMLRoundTrip(t *testing.T) {
+	doc, err := textml.ParseDocument(strings.NewReader(`#metadata{
+    #title{ Tom & Jerry }
+}
+
+Some #bold{ <bold> } text, a #link{ link }{ https://example.org/?a=1&b=2 } and #html.p{ #html.em{ x } }.
+
+#code{{
+    if (a < b) { return; }
+}}
+#arg{ not an argument } #x{}{ } #y{ #arg{ a } }`))
+	assert.Nil(t, err)
+
+	doc = append(doc, ast.EN("empty"), ast.T("\r\n"))
+
+	for _, opts := range []transpile.Options{nil, {"compact": "true"}, {"compact": "true", "arg": "a"}} {
+		tr, err := transpile.New("xml", opts)
+		assert.Nil(t, err)
+
+		sb := &strings.Builder{}
+		assert.Nil(t, tr.Transpile(sb, doc))
+
+		result, err := importer.XML(strings.NewReader(sb.String()), importer.XMLOptions{Arg: opts["arg"]})
+		assert.Nil(t, err)
+		assert.True(t, ast.Normalize(doc, ast.MergeText).Equal(result), "options: %v\nexpected: %v\nactual:   %v", opts, doc, result)
+	}
+}
+
+func TestXML(t *testing.T) {
+	result, err := importer.XML(strings.NewReader(`<?xml version="1.0"?>
+<!-- generated -->
+<book xmlns="urn:x" xmlns:dc="http://purl.org/dc/elements/1.1/">
+    <dc:title lang="en">A <![CDATA[<Story>]]> Title</dc:title><!-- x -->
+    <chapter>
+        <arg>One</arg>
+        <arg/>
+    </chapter>
+    <br/>
+</book>
+`), importer.XMLOptions{})
+	assert.Nil(t, err)
+	assert.Equal(t, ast.Block{
+		ast.T("\n    "),
+		ast.EN("dc.title", ast.B(ast.Attr("lang", "en")), ast.B(ast.T("A <Story> Title"))),
+		ast.T("\n    "),
+		ast.EN("chapter", ast.B(ast.T("One")), ast.B()),
+		ast.T("\n    "),
+		ast.EN("br"),
+		ast.T("\n"),
+	}.String(), result.String())
+}
+
+func TestXMLErrors(t *testing.T) {
+	for source, message := range map[string]string{
+		"":                "the document has no root element",
+		"<a></a><b></b>":  "the document has more than one root element",
+		"<a><b></a>":      "unexpected closing tag </a>",
+		"<a>":             "unclosed element <a>",
+		"text <a></a>":    "text outside of the root element",
+		"<a><b:c:d/></a>": "",
+		"<a><x&y/></a>":   "",
+		"<a><x·y/></a>":   `the XML name "x·y" can't be used as an element name`,
+	} {
+		_, err := importer.XML(strings.NewReader(source), importer.XMLOptions{})
+		assert.Error(t, err, "source: %q", source)
+		if message != "" {
+			assert.ErrorContains(t, err, message, "source: %q", source)
+		}
+	}
+}
@@ -331,7 +331,7 @@ func lexText(l *lexer) {
 
 			depth := l.bracesStack.Top()
 			if braceCount == depth {
-				if l.bufferAt(bracesStart-1) == ' ' {
+				if bracesStart > l.bufferOffset() && l.bufferAt(bracesStart-1) == ' ' {
 					l.move(bracesStart - 1)
 					l.emit(TextToken)
 
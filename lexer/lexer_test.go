@@ -138,3 +138,20 @@ func TestLexer6(t *testing.T) {
 	}, tokens)
 	assert.Nil(t, err)
 }
+
+func TestLexerEmptyArgument(t *testing.T) {
+	s := strings.NewReader(`#br{}#hr{ }`)
+
+	tokens, err := lexer.New(s).AllTokens()
+
+	assert.Nil(t, err)
+	assert.Equal(t, []*lexer.Token{
+		{lexer.ElementToken, "#br", lexer.TokenInfo{0, 0}},
+		{lexer.BraceOpenToken, "{", lexer.TokenInfo{0, 3}},
+		{lexer.BraceCloseToken, "}", lexer.TokenInfo{0, 4}},
+		{lexer.ElementToken, "#hr", lexer.TokenInfo{0, 5}},
+		{lexer.BraceOpenToken, "{", lexer.TokenInfo{0, 8}},
+		{lexer.BraceCloseToken, "}", lexer.TokenInfo{0, 10}},
+		{lexer.EOFToken, "", lexer.TokenInfo{0, 11}},
+	}, tokens)
+}
@@ -0,0 +1,274 @@
+package textml
+
+import (
+	"bytes"
+	"encoding"
+	"fmt"
+	"reflect"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/aziis98/textml/ast"
+	"github.com/aziis98/textml/utils"
+)
+
+// Marshaler is implemented by types that can encode themselves as the argument of an element.
+type Marshaler interface {
+	MarshalTML() (ast.Block, error)
+}
+
+// Marshal encodes a struct or a map as a dictionary shaped block, this is the inverse of [Unmarshal] and uses the same `tml` tags.
+//
+// Each field becomes a `#KEY{ VALUE }` entry, slices become repeated entries and nested structs and maps become nested dictionaries. Nil pointers, nil interfaces and fields tagged with `omitempty` holding a zero value are skipped. The returned block also contains the newlines and indentation between entries so that printing it with [ast.Print] gives a readable document.
+func Marshal(v any) (ast.Block, error) {
+	e := &encoder{indent: "    "}
+
+	rv := indirect(reflect.ValueOf(v))
+	if !rv.IsValid() || (rv.Kind() != reflect.Struct && rv.Kind() != reflect.Map) {
+		return nil, fmt.Errorf("textml: Marshal expects a struct or a map, got %T", v)
+	}
+
+	return e.encodeEntries(rv, 0)
+}
+
+// MarshalSource is like [Marshal] but returns the TextML source of the encoded document.
+func MarshalSource(v any) ([]byte, error) {
+	block, err := Marshal(v)
+	if err != nil {
+		return nil, err
+	}
+
+	buf := &bytes.Buffer{}
+	if err := ast.Print(buf, block); err != nil {
+		return nil, fmt.Errorf("textml: %w", err)
+	}
+
+	return buf.Bytes(), nil
+}
+
+var (
+	marshalerType     = reflect.TypeOf((*Marshaler)(nil)).Elem()
+	textMarshalerType = reflect.TypeOf((*encoding.TextMarshaler)(nil)).Elem()
+)
+
+type encoder struct {
+	indent string
+	path   []string
+}
+
+func (e *encoder) errorf(format string, args ...any) error {
+	return &MarshalError{
+		Path: strings.Join(e.path, "."),
+		Err:  fmt.Errorf(format, args...),
+	}
+}
+
+// MarshalError describes a value that could not be encoded, Path is the dotted list of element names leading to the value.
+type MarshalError struct {
+	Path string
+	Err  error
+}
+
+func (e *MarshalError) Error() string {
+	if e.Path == "" {
+		return fmt.Sprintf("textml: %v", e.Err)
+	}
+
+	return fmt.Sprintf("textml: #%s: %v", e.Path, e.Err)
+}
+
+func (e *MarshalError) Unwrap() error {
+	return e.Err
+}
+
+// indirect follows pointers and interfaces, the returned value is invalid for nil values.
+func indirect(v reflect.Value) reflect.Value {
+	for v.IsValid() && (v.Kind() == reflect.Pointer || v.Kind() == reflect.Interface) {
+		if v.IsNil() {
+			return reflect.Value{}
+		}
+		v = v.Elem()
+	}
+
+	return v
+}
+
+type entry struct {
+	name  string
+	value reflect.Value
+}
+
+// encodeEntries encodes the fields of a struct or the keys of a map (in sorted order) as a sequence of entries at the given nesting level.
+func (e *encoder) encodeEntries(v reflect.Value, level int) (ast.Block, error) {
+	entries := []entry{}
+
+	switch v.Kind() {
+	case reflect.Struct:
+		for _, f := range structFields(v.Type()) {
+			fv := v.FieldByIndex(f.index)
+			if f.omitEmpty && fv.IsZero() {
+				continue
+			}
+
+			entries = append(entries, entry{f.name, fv})
+		}
+
+	case reflect.Map:
+		if v.Type().Key().Kind() != reflect.String {
+			return nil, e.errorf("cannot encode map with key of type %v", v.Type().Key())
+		}
+
+		keys := v.MapKeys()
+		sort.Slice(keys, func(i, j int) bool { return keys[i].String() < keys[j].String() })
+
+		for _, key := range keys {
+			entries = append(entries, entry{key.String(), v.MapIndex(key)})
+		}
+	}
+
+	block := ast.Block{}
+	for _, entry := range entries {
+		elements, err := e.encodeEntry(entry.name, entry.value, level)
+		if err != nil {
+			return nil, err
+		}
+
+		for _, elem := range elements {
+			if level > 0 || len(block) > 0 {
+				block = append(block, &ast.TextNode{Text: "\n" + strings.Repeat(e.indent, level)})
+			}
+
+			block = append(block, elem)
+		}
+	}
+
+	if len(block) > 0 {
+		// the lexer drops the space before closing braces, this keeps the block equal to the parsed source
+		closing := "\n" + strings.Repeat(e.indent, utils.Max(level-1, 0))
+		block = append(block, &ast.TextNode{Text: strings.TrimSuffix(closing, " ")})
+	}
+
+	return block, nil
+}
+
+// encodeEntry returns the elements for a single entry, slices give an element for each item.
+func (e *encoder) encodeEntry(name string, v reflect.Value, level int) ([]ast.Node, error) {
+	e.path = append(e.path, name)
+	defer func() { e.path = e.path[:len(e.path)-1] }()
+
+	if !ast.IsValidName(name) || name == "" {
+		return nil, e.errorf("invalid element name %q", name)
+	}
+
+	v = indirect(v)
+	if !v.IsValid() {
+		return nil, nil
+	}
+
+	if v.Kind() == reflect.Slice && !isEncodedAsValue(v) {
+		elements := []ast.Node{}
+		for i := 0; i < v.Len(); i++ {
+			item := indirect(v.Index(i))
+			if !item.IsValid() {
+				continue
+			}
+			if item.Kind() == reflect.Slice && !isEncodedAsValue(item) {
+				return nil, e.errorf("cannot encode nested slice of type %v", v.Type())
+			}
+
+			arg, err := e.encodeValue(item, level+1)
+			if err != nil {
+				return nil, err
+			}
+
+			elements = append(elements, &ast.ElementNode{Name: name, Arguments: []ast.Block{arg}})
+		}
+
+		return elements, nil
+	}
+
+	arg, err := e.encodeValue(v, level+1)
+	if err != nil {
+		return nil, err
+	}
+
+	return []ast.Node{&ast.ElementNode{Name: name, Arguments: []ast.Block{arg}}}, nil
+}
+
+// isEncodedAsValue reports whether a slice is encoded by a marshaler instead of being expanded to repeated entries.
+func isEncodedAsValue(v reflect.Value) bool {
+	t := v.Type()
+	return t.Implements(marshalerType) || t.Implements(textMarshalerType) || t.Elem().Kind() == reflect.Uint8
+}
+
+// encodeValue encodes the argument block of an entry.
+func (e *encoder) encodeValue(v reflect.Value, level int) (ast.Block, error) {
+	if v.Type().Implements(marshalerType) {
+		block, err := v.Interface().(Marshaler).MarshalTML()
+		if err != nil {
+			return nil, e.errorf("%w", err)
+		}
+
+		return block, nil
+	}
+
+	switch v.Type() {
+	case timeType:
+		t := v.Interface().(time.Time)
+
+		if t.Location() == time.UTC && t.Equal(t.Truncate(24*time.Hour)) {
+			return textBlock(t.Format("2006-01-02")), nil
+		}
+
+		return textBlock(t.Format(time.RFC3339Nano)), nil
+
+	case durationType:
+		return textBlock(time.Duration(v.Int()).String()), nil
+	}
+
+	if v.Type().Implements(textMarshalerType) {
+		text, err := v.Interface().(encoding.TextMarshaler).MarshalText()
+		if err != nil {
+			return nil, e.errorf("%w", err)
+		}
+
+		return textBlock(string(text)), nil
+	}
+
+	switch v.Kind() {
+	case reflect.Struct, reflect.Map:
+		return e.encodeEntries(v, level)
+
+	case reflect.String:
+		return textBlock(v.String()), nil
+
+	case reflect.Bool:
+		return textBlock(strconv.FormatBool(v.Bool())), nil
+
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		return textBlock(strconv.FormatInt(v.Int(), 10)), nil
+
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64, reflect.Uintptr:
+		return textBlock(strconv.FormatUint(v.Uint(), 10)), nil
+
+	case reflect.Float32, reflect.Float64:
+		return textBlock(strconv.FormatFloat(v.Float(), 'g', -1, v.Type().Bits())), nil
+
+	case reflect.Slice:
+		if v.Type().Elem().Kind() == reflect.Uint8 {
+			return textBlock(string(v.Bytes())), nil
+		}
+	}
+
+	return nil, e.errorf("cannot encode value of type %v", v.Type())
+}
+
+func textBlock(s string) ast.Block {
+	if s == "" {
+		return ast.Block{}
+	}
+
+	return ast.Block{&ast.TextNode{Text: s}}
+}
@@ -0,0 +1,82 @@
+package textml_test
+
+import (
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/aziis98/textml"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestMarshal(t *testing.T) {
+	summary := "A short summary"
+
+	c := config{
+		Title:   "Example",
+		Draft:   true,
+		Weight:  10,
+		Date:    time.Date(2022, 8, 10, 0, 0, 0, 0, time.UTC),
+		Timeout: 90 * time.Second,
+		Tags:    []string{"example", "other"},
+		Authors: []author{
+			{Name: "John", Email: "john@example.org"},
+		},
+		Extra: map[string]any{
+			"b": map[string]any{"c": "2"},
+			"a": []any{"1", "3"},
+		},
+		Summary: &summary,
+	}
+
+	source, err := textml.MarshalSource(c)
+	assert.Nil(t, err)
+	assert.Equal(t, strings.TrimLeft(`
+#title{ Example }
+#draft{ true }
+#weight{ 10 }
+#ratio{ 0 }
+#date{ 2022-08-10 }
+#timeout{ 1m30s }
+#version{ 0.0 }
+#tag{ example }
+#tag{ other }
+#author{
+    #name{ John }
+    #email{ john@example.org }
+}
+#extra{
+    #a{ 1 }
+    #a{ 3 }
+    #b{
+        #c{ 2 }
+    }
+}
+#Summary{ A short summary }
+`, "\n"), string(source))
+
+	doc, err := textml.ParseDocument(strings.NewReader(string(source)))
+	assert.Nil(t, err)
+
+	var decoded config
+	err = textml.Unmarshal(doc, &decoded)
+	assert.Nil(t, err)
+	assert.Equal(t, c, decoded)
+}
+
+func TestMarshalBraces(t *testing.T) {
+	source, err := textml.MarshalSource(map[string]string{
+		"code":  "if (x) { return #bold{ y } }",
+		"plain": "no braces",
+	})
+	assert.Nil(t, err)
+	assert.Equal(t, "#code{{ if (x) { return #bold{ y } } }}\n#plain{ no braces }\n", string(source))
+
+	doc, err := textml.ParseDocument(strings.NewReader(string(source)))
+	assert.Nil(t, err)
+
+	var decoded map[string]string
+	err = textml.Unmarshal(doc, &decoded)
+	assert.Nil(t, err)
+	assert.Equal(t, "if (x) { return #bold{ y } }", decoded["code"])
+}
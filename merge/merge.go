@@ -0,0 +1,218 @@
+// Package merge combines TextML documents, typically a base document with one or more overlays.
+//
+// Documents are merged by element name. When the overlay holds only elements (and whitespace) each of its elements is merged into the base block: elements that are missing from the base are added after the last base element, elements appearing once in both are merged argument by argument (so nested dictionaries like #metadata are merged deeply) and elements repeated in either document follow a [Strategy]. An overlay block containing text replaces the base block, as does an overlay dictionary merged into a block made only of text.
+package merge
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/aziis98/textml/ast"
+)
+
+// Strategy tells how to merge elements with a repeated name.
+type Strategy int
+
+const (
+	// Replace removes the base elements with the name and puts the overlay ones in place of the first of them
+	Replace Strategy = iota
+	// Append adds the overlay elements after the last base element with the same name
+	Append
+)
+
+func (s Strategy) String() string {
+	switch s {
+	case Replace:
+		return "replace"
+	case Append:
+		return "append"
+	default:
+		return fmt.Sprintf("Strategy(%d)", int(s))
+	}
+}
+
+// ParseStrategy returns the strategy with the given name, "replace" or "append".
+func ParseStrategy(name string) (Strategy, error) {
+	switch name {
+	case "replace":
+		return Replace, nil
+	case "append":
+		return Append, nil
+	default:
+		return 0, fmt.Errorf(`invalid merge strategy %q, expected "replace" or "append"`, name)
+	}
+}
+
+// Options for [Merge]
+type Options struct {
+	// Repeated is the strategy for elements with a repeated name
+	Repeated Strategy
+
+	// Strategies overrides the strategy by element name, these also apply to elements appearing only once (with Append the overlay element is added instead of being merged)
+	Strategies map[string]Strategy
+}
+
+func (o Options) strategy(name string) (Strategy, bool) {
+	if s, ok := o.Strategies[name]; ok {
+		return s, true
+	}
+
+	return o.Repeated, false
+}
+
+// Merge returns the result of merging the overlay into the base document, neither argument is modified.
+func Merge(base, overlay ast.Block, opts Options) ast.Block {
+	return opts.mergeBlocks(base.Clone(), overlay)
+}
+
+// MergeAll merges each overlay in order into the base document.
+func MergeAll(opts Options, base ast.Block, overlays ...ast.Block) ast.Block {
+	result := base.Clone()
+	for _, overlay := range overlays {
+		result = opts.mergeBlocks(result, overlay)
+	}
+
+	return result
+}
+
+// mergeBlocks merges the overlay into base, that is owned by the caller and can be modified.
+func (o Options) mergeBlocks(base, overlay ast.Block) ast.Block {
+	if !overlay.IsDictionary() || base.FirstElement() == nil {
+		return overlay.Clone()
+	}
+
+	// overlay elements grouped by name in order of first appearance
+	names := []string{}
+	groups := map[string][]*ast.ElementNode{}
+	for _, n := range overlay {
+		if elem, ok := n.(*ast.ElementNode); ok {
+			if _, ok := groups[elem.Name]; !ok {
+				names = append(names, elem.Name)
+			}
+
+			groups[elem.Name] = append(groups[elem.Name], elem)
+		}
+	}
+
+	for _, name := range names {
+		entries := groups[name]
+		indices := indicesOf(base, name)
+
+		strategy, explicit := o.strategy(name)
+		repeated := len(indices) > 1 || len(entries) > 1
+
+		switch {
+		case len(indices) == 0:
+			base = insertEntries(base, lastElement(base), entries)
+
+		case !repeated && !explicit:
+			base[indices[0]] = o.mergeElements(base[indices[0]].(*ast.ElementNode), entries[0])
+
+		case strategy == Append:
+			base = insertEntries(base, indices[len(indices)-1], entries)
+
+		default:
+			size := len(base)
+			base[indices[0]] = ast.CloneNode(entries[0])
+			base = insertEntries(base, indices[0], entries[1:])
+
+			// the other base elements are removed from the last one, their indices are shifted by the inserted nodes
+			shift := len(base) - size
+			for k := len(indices) - 1; k >= 1; k-- {
+				base = removeEntry(base, indices[k]+shift)
+			}
+		}
+	}
+
+	return base
+}
+
+// mergeElements merges two elements with the same name argument by argument, an overlay element with a different number of arguments replaces the base one.
+func (o Options) mergeElements(base, overlay *ast.ElementNode) *ast.ElementNode {
+	if len(base.Arguments) != len(overlay.Arguments) {
+		return ast.CloneNode(overlay).(*ast.ElementNode)
+	}
+
+	for k := range base.Arguments {
+		base.Arguments[k] = o.mergeBlocks(base.Arguments[k], overlay.Arguments[k])
+	}
+
+	return base
+}
+
+func indicesOf(block ast.Block, name string) []int {
+	indices := []int{}
+	for i, n := range block {
+		if elem, ok := n.(*ast.ElementNode); ok && elem.Name == name {
+			indices = append(indices, i)
+		}
+	}
+
+	return indices
+}
+
+func lastElement(block ast.Block) int {
+	for i := len(block) - 1; i >= 0; i-- {
+		if _, ok := block[i].(*ast.ElementNode); ok {
+			return i
+		}
+	}
+
+	return len(block) - 1
+}
+
+// separatorBefore returns the whitespace before the node at index i.
+func separatorBefore(block ast.Block, i int) string {
+	if i > 0 {
+		if text, ok := block[i-1].(*ast.TextNode); ok && strings.TrimSpace(text.Text) == "" {
+			return text.Text
+		}
+	}
+
+	return ""
+}
+
+// separatorOf returns the whitespace used before the element at index i or before any other element of the block (a space if there is none), this is reused when adding entries to keep the layout of the document.
+func separatorOf(block ast.Block, i int) string {
+	if separator := separatorBefore(block, i); separator != "" {
+		return separator
+	}
+
+	for k, n := range block {
+		if _, ok := n.(*ast.ElementNode); ok {
+			if separator := separatorBefore(block, k); separator != "" {
+				return separator
+			}
+		}
+	}
+
+	return " "
+}
+
+// insertEntries adds clones of the entries after the node at index i, each preceded by the separator of that node.
+func insertEntries(block ast.Block, i int, entries []*ast.ElementNode) ast.Block {
+	if len(entries) == 0 {
+		return block
+	}
+
+	separator := separatorOf(block, i)
+
+	nodes := ast.Block{}
+	for _, entry := range entries {
+		nodes = append(nodes, ast.T(separator), ast.CloneNode(entry))
+	}
+
+	result := append(ast.Block{}, block[:i+1]...)
+	result = append(result, nodes...)
+	return append(result, block[i+1:]...)
+}
+
+// removeEntry removes the element at index i together with the whitespace before it.
+func removeEntry(block ast.Block, i int) ast.Block {
+	from := i
+	if separatorBefore(block, i) != "" {
+		from--
+	}
+
+	return append(block[:from], block[i+1:]...)
+}
@@ -0,0 +1,111 @@
+package merge_test
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/aziis98/textml"
+	"github.com/aziis98/textml/ast"
+	"github.com/aziis98/textml/merge"
+	"github.com/stretchr/testify/assert"
+)
+
+func parse(t *testing.T, source string) ast.Block {
+	t.Helper()
+
+	block, err := textml.ParseDocument(strings.NewReader(source))
+	assert.Nil(t, err)
+	return block
+}
+
+const base = `#metadata{
+    #title{ Example }
+    #author{ #name{ John } #email{ john@example.org } }
+    #tag{ example }
+    #tag{ draft }
+}
+#server{ #host{ localhost } #port{ 8080 } }`
+
+func TestMergeDeep(t *testing.T) {
+	overlay := parse(t, `#metadata{
+    #title{ Production }
+    #author{ #email{ admin@example.org } }
+    #date{ 2022-10-01 }
+}
+#server{ #host{ example.org } }
+#debug{ false }`)
+
+	assert.Equal(t, `#metadata{
+    #title{ Production }
+    #author{ #name{ John } #email{ admin@example.org } }
+    #tag{ example }
+    #tag{ draft }
+    #date{ 2022-10-01 }
+}
+#server{ #host{ example.org } #port{ 8080 } }
+#debug{ false }`, merge.Merge(parse(t, base), overlay, merge.Options{}).String())
+}
+
+func TestMergeRepeated(t *testing.T) {
+	overlay := parse(t, "#metadata{ #tag{ release } #tag{ stable } }")
+
+	replaced := merge.Merge(parse(t, base), overlay, merge.Options{})
+	assert.Equal(t, `#metadata{
+    #title{ Example }
+    #author{ #name{ John } #email{ john@example.org } }
+    #tag{ release }
+    #tag{ stable }
+}
+#server{ #host{ localhost } #port{ 8080 } }`, replaced.String())
+
+	appended := merge.Merge(parse(t, base), overlay, merge.Options{Repeated: merge.Append})
+	assert.Equal(t, `#metadata{
+    #title{ Example }
+    #author{ #name{ John } #email{ john@example.org } }
+    #tag{ example }
+    #tag{ draft }
+    #tag{ release }
+    #tag{ stable }
+}
+#server{ #host{ localhost } #port{ 8080 } }`, appended.String())
+}
+
+func TestMergeStrategies(t *testing.T) {
+	opts := merge.Options{Strategies: map[string]merge.Strategy{
+		"author": merge.Replace,
+		"item":   merge.Append,
+	}}
+
+	result := merge.Merge(
+		parse(t, "#author{ #name{ John } #email{ john@example.org } }\n#item{ one }"),
+		parse(t, "#author{ #name{ Jane } }\n#item{ two }"),
+		opts,
+	)
+
+	assert.Equal(t, "#author{ #name{ Jane } }\n#item{ one }\n#item{ two }", result.String())
+}
+
+func TestMergeContent(t *testing.T) {
+	doc := parse(t, "#metadata{ #title{ Draft } }\n\nSome #bold{ content } here.")
+
+	// dictionaries are merged also into documents with text
+	result := merge.Merge(doc, parse(t, "#metadata{ #title{ Final } }"), merge.Options{})
+	assert.Equal(t, "#metadata{ #title{ Final } }\n\nSome #bold{ content } here.", result.String())
+
+	// text replaces the whole block
+	result = merge.Merge(doc, parse(t, "New content"), merge.Options{})
+	assert.Equal(t, "New content", result.String())
+
+	// the base document is not modified
+	assert.Equal(t, "#metadata{ #title{ Draft } }\n\nSome #bold{ content } here.", doc.String())
+}
+
+func TestMergeAll(t *testing.T) {
+	result := merge.MergeAll(merge.Options{},
+		parse(t, "#a{ 1 }\n#b{ 2 }"),
+		parse(t, "#b{ 3 }"),
+		parse(t, "#c{ 4 }"),
+	)
+
+	assert.Equal(t, "#a{ 1 }\n#b{ 3 }\n#c{ 4 }", result.String())
+}
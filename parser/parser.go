@@ -42,7 +42,7 @@ func Parse(ts []*lexer.Token) (*Block, error) {
 		switch t.Type {
 		case lexer.TextToken:
 			ts = ts[1:]
-			children = append(children, &TextNode{Text: t.Value})
+			children = append(children, &TextNode{t, t.Value})
 		case lexer.ElementToken:
 			var elt Node
 			var err error
@@ -2,6 +2,7 @@ package document
 
 import (
 	"fmt"
+	"strings"
 
 	"github.com/aziis98/textml/ast"
 	"github.com/aziis98/textml/html"
@@ -24,22 +25,31 @@ func parseDictEntries(block ast.Block) (map[string]any, error) {
 	return m, nil
 }
 
+// linkTargetText is used for link targets, these can't contain spaces
+var linkTargetText = ast.TextOptions{Recursive: true, NormalizeSpace: true}
+
 func parseDictValue(block ast.Block) (any, error) {
-	if elem := block.FirstElement(); elem != nil {
+	if block.IsDictionary() {
 		return parseDictEntries(block)
 	} else {
-		return block.TextContent(), nil
+		return block.Text(ast.ValueText), nil
 	}
 }
 
+// RenderFunc renders an element of a namespace.
+type RenderFunc func(e *Engine, el *ast.ElementNode) ([]html.Node, error)
+
 // Engine is a Markdown like format that transpiles TextML to HTML
-type Engine struct{}
+type Engine struct {
+	// Namespaces holds the renderers of namespaced elements by namespace, elements in the "html" namespace like #html.div{ ... } are rendered as HTML elements unless this has an entry for "html".
+	Namespaces map[string]RenderFunc
+}
 
 type Metadata map[string]any
 
 func checkArgCount(elem *ast.ElementNode, count int) error {
 	if len(elem.Arguments) != count {
-		return fmt.Errorf(`invalid argument count, expected %d but got %d`, count, len(elem.Arguments))
+		return fmt.Errorf(`%v: invalid argument count for #%s, expected %d but got %d`, elem.Pos, elem.Name, count, len(elem.Arguments))
 	}
 
 	return nil
@@ -55,11 +65,69 @@ var directTranslationMap = map[string]string{
 	"italic":        "i",
 	"underline":     "u",
 	"strikethrough": "s",
+}
+
+// codeText is used for the content of #code elements, a #format{ LANGUAGE } element only sets the language of the code block
+var codeText = ast.TextOptions{
+	Recursive: true,
+	Overrides: map[string]func(*ast.ElementNode, ast.TextOptions) string{
+		"format": func(*ast.ElementNode, ast.TextOptions) string { return "" },
+	},
+}
+
+// renderCode renders #code{ CODE } as <code>, code with many lines or with a #format{ LANGUAGE } element is a code block written as <pre><code> with a "language-LANGUAGE" class and without the indentation common to its lines.
+func (t *Engine) renderCode(el *ast.ElementNode) ([]html.Node, error) {
+	language := ""
+	for _, n := range el.Arguments[0] {
+		if format, ok := n.(*ast.ElementNode); ok && format.Name == "format" && len(format.Arguments) > 0 {
+			language = format.Arguments[0].Text(linkTargetText)
+		}
+	}
+
+	code := el.Arguments[0].Text(codeText)
+	if language == "" && !strings.Contains(strings.TrimSpace(code), "\n") {
+		children, err := t.RenderBlock(el.Arguments[0])
+		if err != nil {
+			return nil, err
+		}
+
+		return []html.Node{html.NewElementNode("code", nil, children)}, nil
+	}
+
+	// the first line is the text after the opening brace, this isn't dedented
+	lines := strings.Split(ast.Dedent(ast.Block{ast.T(code)}).Text(ast.TextOptions{}), "\n")
+	lines[0] = strings.TrimSpace(lines[0])
+	for len(lines) > 0 && strings.TrimSpace(lines[0]) == "" {
+		lines = lines[1:]
+	}
+	for len(lines) > 0 && strings.TrimSpace(lines[len(lines)-1]) == "" {
+		lines = lines[:len(lines)-1]
+	}
+
+	attributes := html.AttributeMap{}
+	if language != "" {
+		attributes["class"] = &html.Attribute{Value: "language-" + language}
+	}
 
-	"code": "code",
+	return []html.Node{
+		html.NewElementNode("pre", nil, []html.Node{
+			html.NewElementNode("code", attributes, []html.Node{html.NewTextNode(strings.Join(lines, "\n"))}),
+		}),
+	}, nil
 }
 
 func (t *Engine) RenderElement(el *ast.ElementNode) ([]html.Node, error) {
+	if namespace := el.Namespace(); namespace != "" {
+		if render, ok := t.Namespaces[namespace]; ok {
+			return render(t, el)
+		}
+		if namespace == "html" {
+			return renderHTMLElement(t, el)
+		}
+
+		return nil, fmt.Errorf("%v: unknown namespace %q of element #%s", el.Pos, namespace, el.Name)
+	}
+
 	// Direct translations
 	if tagName, found := directTranslationMap[el.Name]; found {
 		if err := checkArgCount(el, 1); err != nil {
@@ -79,6 +147,13 @@ func (t *Engine) RenderElement(el *ast.ElementNode) ([]html.Node, error) {
 	nodes := []html.Node{}
 
 	switch el.Name {
+	case "code":
+		if err := checkArgCount(el, 1); err != nil {
+			return nil, err
+		}
+
+		return t.renderCode(el)
+
 	case "link":
 		if err := checkArgCount(el, 2); err != nil {
 			return nil, err
@@ -89,7 +164,7 @@ func (t *Engine) RenderElement(el *ast.ElementNode) ([]html.Node, error) {
 			return nil, err
 		}
 
-		linkTarget := el.Arguments[1].TextContent()
+		linkTarget := el.Arguments[1].Text(linkTargetText)
 
 		return []html.Node{
 			html.NewElementNode(
@@ -105,6 +180,44 @@ func (t *Engine) RenderElement(el *ast.ElementNode) ([]html.Node, error) {
 	return nodes, nil
 }
 
+// renderHTMLElement renders #html.TAG{ CHILDREN } or #html.TAG{ ATTRIBUTES }{ CHILDREN } where attributes are written as #NAME{ VALUE } entries.
+func renderHTMLElement(t *Engine, el *ast.ElementNode) ([]html.Node, error) {
+	if len(el.Arguments) != 1 && len(el.Arguments) != 2 {
+		return nil, fmt.Errorf(`%v: invalid argument count for #%s, expected 1 or 2 but got %d`, el.Pos, el.Name, len(el.Arguments))
+	}
+
+	attributes := html.AttributeMap{}
+	if len(el.Arguments) == 2 {
+		for _, n := range el.Arguments[0] {
+			attr, ok := n.(*ast.ElementNode)
+			if !ok {
+				continue
+			}
+
+			// attributes without a value like #disabled{} or #disabled are written as empty
+			value := ""
+			switch len(attr.Arguments) {
+			case 0:
+			case 1:
+				value = attr.Arguments[0].Text(ast.ValueText)
+			default:
+				return nil, fmt.Errorf(`%v: invalid argument count for attribute #%s, expected 0 or 1 but got %d`, attr.Pos, attr.Name, len(attr.Arguments))
+			}
+
+			attributes[attr.Name] = &html.Attribute{Value: value}
+		}
+	}
+
+	children, err := t.RenderBlock(el.Arguments[len(el.Arguments)-1])
+	if err != nil {
+		return nil, err
+	}
+
+	return []html.Node{
+		html.NewElementNode(el.LocalName(), attributes, children),
+	}, nil
+}
+
 func (t *Engine) RenderBlock(block ast.Block) ([]html.Node, error) {
 	// TODO: Add automatic paragraph splitting after "\n\n", this requires distinguishing between inline and block elements...
 	nodes := []html.Node{}
@@ -129,6 +242,13 @@ func (t *Engine) RenderBlock(block ast.Block) ([]html.Node, error) {
 }
 
 func (t *Engine) Render(block ast.Block) (Metadata, []html.Node, error) {
+	block, err := ast.ResolveNamespaces(block)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	block = ast.Normalize(block, ast.MergeText)
+
 	documentMetadata := Metadata{}
 	nodes := []html.Node{}
 
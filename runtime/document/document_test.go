@@ -6,6 +6,7 @@ import (
 	"testing"
 
 	"github.com/aziis98/textml"
+	"github.com/aziis98/textml/ast"
 	"github.com/aziis98/textml/html"
 	"github.com/aziis98/textml/runtime/document"
 	"github.com/stretchr/testify/assert"
@@ -74,3 +75,119 @@ func Test1(t *testing.T) {
 		simplifyLines(htmlString),
 	)
 }
+
+func TestMetadataAndLinkText(t *testing.T) {
+	doc, err := textml.ParseDocument(strings.NewReader(`
+#metadata {
+	#title { An #italic{ example } title }
+	#author { #name { John } }
+}
+
+#link{ foo #bold{ bar } }{
+	https://example.org
+}
+`))
+	assert.Nil(t, err)
+
+	engine := &document.Engine{}
+
+	metadata, nodes, err := engine.Render(doc)
+	assert.Nil(t, err)
+	assert.Equal(t,
+		document.Metadata{
+			"title":  "An example title",
+			"author": map[string]any{"name": "John"},
+		},
+		metadata,
+	)
+	assert.Equal(t,
+		`<a href="https://example.org">foo <b>bar</b></a>`,
+		strings.TrimSpace(html.RenderToString(nodes)),
+	)
+}
+
+func TestNamespaces(t *testing.T) {
+	doc, err := textml.ParseDocument(strings.NewReader(`#namespace{ h }{ html }
+#title{ Mixed }
+#h.div{ #class{ note } }{ A #bold{ note } with #chart.bar{ 1, 2, 3 } }`))
+	assert.Nil(t, err)
+
+	engine := &document.Engine{
+		Namespaces: map[string]document.RenderFunc{
+			"chart": func(e *document.Engine, el *ast.ElementNode) ([]html.Node, error) {
+				return []html.Node{html.NewTextNode("[" + el.LocalName() + " chart]")}, nil
+			},
+		},
+	}
+
+	_, nodes, err := engine.Render(doc)
+	assert.Nil(t, err)
+	assert.Equal(t,
+		"<h1>Mixed</h1>\n"+`<div class="note">A <b>note</b> with [bar chart]</div>`,
+		strings.TrimSpace(html.RenderToString(nodes)),
+	)
+
+	_, _, err = (&document.Engine{}).Render(doc)
+	assert.EqualError(t, err, `3:48: unknown namespace "chart" of element #chart.bar`)
+}
+
+func TestCodeBlocks(t *testing.T) {
+	doc, err := textml.ParseDocument(strings.NewReader(`
+Some #code{ inline } code
+
+#code{{
+    #format{{ go }}
+    if a < b {
+        return
+    }
+}}`))
+	assert.Nil(t, err)
+
+	_, nodes, err := (&document.Engine{}).Render(doc)
+	assert.Nil(t, err)
+	assert.Equal(t,
+		"Some <code>inline</code> code\n\n"+`<pre><code class="language-go">if a &lt; b {`+"\n    return\n}</code></pre>",
+		strings.TrimSpace(html.RenderToString(nodes)),
+	)
+}
+
+func TestHTMLElementErrors(t *testing.T) {
+	// elements without arguments can't be written in TextML but come from the JSON format or code
+	input := ast.EN("html.input", ast.B(ast.EN("disabled"), ast.EN("type", ast.B(ast.T("text")))), ast.B())
+
+	_, nodes, err := (&document.Engine{}).Render(ast.Block{input})
+	assert.Nil(t, err)
+	assert.Equal(t, `<input disabled="" type="text">`, strings.TrimSpace(html.RenderToString(nodes)))
+
+	_, _, err = (&document.Engine{}).Render(ast.Block{ast.EN("html.div")})
+	assert.EqualError(t, err, "-: invalid argument count for #html.div, expected 1 or 2 but got 0")
+
+	for source, message := range map[string]string{
+		"\n#html.p{ #class{ a }{ b } }{}": "2:10: invalid argument count for attribute #class, expected 0 or 1 but got 2",
+		"#bold{ a }{ b }":                 "1:1: invalid argument count for #bold, expected 1 but got 2",
+	} {
+		doc, err := textml.ParseDocument(strings.NewReader(source))
+		assert.Nil(t, err)
+
+		_, _, err = (&document.Engine{}).Render(doc)
+		assert.EqualError(t, err, message, source)
+	}
+}
+
+func TestPostProcessing(t *testing.T) {
+	doc, err := textml.ParseDocument(strings.NewReader(`#html.table{ #html.tr{ #html.td{ 1 } } }`))
+	assert.Nil(t, err)
+
+	_, nodes, err := (&document.Engine{}).Render(doc)
+	assert.Nil(t, err)
+
+	// the example of the README
+	for _, table := range html.FindAll(nodes, html.ByTag("table")) {
+		nodes = html.Wrap(nodes, table, html.NewElementNode("div", html.AttributeMap{"class": &html.Attribute{Value: "scroll"}}, nil))
+	}
+
+	assert.Equal(t,
+		`<div class="scroll"><table><tr><td>1</td></tr></table></div>`,
+		strings.TrimSpace(html.RenderToString(nodes)),
+	)
+}
@@ -5,7 +5,6 @@ import (
 	"fmt"
 	"os"
 	"reflect"
-	"regexp"
 	"strconv"
 	"strings"
 	"unicode"
@@ -113,32 +112,6 @@ var commandCharMap = map[string]string{
 	"newline": "\n",
 }
 
-var regexLineWithIndent = regexp.MustCompile(` *\n\s*`)
-
-func inlineBlock(block ast.Block) ast.Block {
-	result := ast.Block{}
-	for _, node := range block {
-		switch node := node.(type) {
-		case *ast.ElementNode:
-			arguments := []ast.Block{}
-
-			for _, arg := range node.Arguments {
-				arguments = append(arguments, inlineBlock(arg))
-			}
-
-			result = append(result, &ast.ElementNode{
-				Name:      node.Name,
-				Arguments: arguments,
-			})
-		case *ast.TextNode:
-			result = append(result, &ast.TextNode{
-				Text: regexLineWithIndent.ReplaceAllString(node.Text, ""),
-			})
-		}
-	}
-	return result
-}
-
 func (e *Engine) evaluateElement(elem *ast.ElementNode) (any, error) {
 	switch elem.Name {
 	case "import":
@@ -317,7 +290,7 @@ func (e *Engine) evaluateElement(elem *ast.ElementNode) (any, error) {
 			return nil, errInvalidElement(elem)
 		}
 
-		inlinedAst := inlineBlock(elem.Arguments[0])
+		inlinedAst := ast.JoinLines(elem.Arguments[0])
 
 		return e.evaluateBlock(inlinedAst)
 
@@ -3,7 +3,6 @@ package transpile
 import (
 	"encoding/json"
 	"io"
-	"log"
 
 	"github.com/alecthomas/repr"
 	"github.com/aziis98/textml/ast"
@@ -18,6 +17,19 @@ func (Repr) Transpile(w io.Writer, block ast.Block) error {
 
 type Json struct{ Inline bool }
 
+func newJson(opts Options, inline bool) (Transpiler, error) {
+	if err := opts.Check("inline"); err != nil {
+		return nil, err
+	}
+
+	inline, err := opts.Bool("inline", inline)
+	if err != nil {
+		return nil, err
+	}
+
+	return &Json{Inline: inline}, nil
+}
+
 func (t *Json) Transpile(w io.Writer, block ast.Block) error {
 	enc := json.NewEncoder(w)
 
@@ -25,9 +37,22 @@ func (t *Json) Transpile(w io.Writer, block ast.Block) error {
 		enc.SetIndent("", "    ")
 	}
 
-	if err := enc.Encode(block); err != nil {
-		log.Fatal(err)
+	return enc.Encode(ast.NewJSONDocument(block))
+}
+
+// Read decodes a document in the JSON format produced by [Json.Transpile].
+func (t *Json) Read(r io.Reader) (ast.Block, error) {
+	var doc ast.JSONDocument
+	if err := json.NewDecoder(r).Decode(&doc); err != nil {
+		return nil, err
 	}
 
-	return nil
+	return doc.Nodes, nil
+}
+
+// Tml prints the document back as TextML source.
+type Tml struct{}
+
+func (Tml) Transpile(w io.Writer, block ast.Block) error {
+	return ast.Print(w, block)
 }
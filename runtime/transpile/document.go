@@ -0,0 +1,277 @@
+package transpile
+
+import (
+	"bytes"
+	"fmt"
+	"math"
+	"strconv"
+	"strings"
+
+	"github.com/aziis98/textml/ast"
+)
+
+//
+// Helpers for the formats rendering the vocabulary of runtime/document
+//
+
+// linkTargetText is used for link targets and code languages, these can't contain spaces
+var linkTargetText = ast.TextOptions{Recursive: true, NormalizeSpace: true}
+
+// codeText is used for the content of #code elements, a #format{ LANGUAGE } element only sets the language of the code block
+var codeText = ast.TextOptions{
+	Recursive: true,
+	Overrides: map[string]func(*ast.ElementNode, ast.TextOptions) string{
+		"format": func(*ast.ElementNode, ast.TextOptions) string { return "" },
+	},
+}
+
+// documentHeadings maps heading elements to their level
+var documentHeadings = map[string]int{
+	"title":          1,
+	"subtitle":       2,
+	"subsubtitle":    3,
+	"subsubsubtitle": 4,
+}
+
+func checkArity(elem *ast.ElementNode, count int) error {
+	if len(elem.Arguments) != count {
+		return fmt.Errorf("%v: invalid argument count for #%s, expected %d but got %d", elem.Pos, elem.Name, count, len(elem.Arguments))
+	}
+
+	return nil
+}
+
+// splitMetadata separates the entries of the top level #metadata elements from the rest of the document.
+func splitMetadata(block ast.Block) (metadata, content ast.Block, err error) {
+	metadata, content = ast.Block{}, ast.Block{}
+
+	for _, n := range block {
+		if elem, ok := n.(*ast.ElementNode); ok && elem.Name == "metadata" {
+			if err := checkArity(elem, 1); err != nil {
+				return nil, nil, err
+			}
+
+			entries := elem.Arguments[0]
+			if strings.TrimSpace(entries.Text(ast.TextOptions{})) != "" {
+				return nil, nil, fmt.Errorf("%v: #metadata must contain only #KEY{ VALUE } entries", elem.Pos)
+			}
+
+			metadata = append(metadata, entries...)
+			continue
+		}
+
+		content = append(content, n)
+	}
+
+	return metadata, content, nil
+}
+
+// checkParagraphs returns an error if the content of an inline formatting element or of a link label has a blank line, this would end the paragraph in the middle of the element.
+func checkParagraphs(elem *ast.ElementNode, content string) error {
+	if regexBlankLine.MatchString(content) {
+		return fmt.Errorf("%v: #%s can't contain a paragraph break", elem.Pos, elem.Name)
+	}
+
+	return nil
+}
+
+// codeContent returns the language given by a #format{ LANGUAGE } element and the text of the content of a #code element.
+func codeContent(block ast.Block) (language, code string) {
+	for _, n := range block {
+		if elem, ok := n.(*ast.ElementNode); ok && elem.Name == "format" && len(elem.Arguments) > 0 {
+			language = elem.Arguments[0].Text(linkTargetText)
+		}
+	}
+
+	return language, block.Text(codeText)
+}
+
+// isCodeBlock reports whether a #code element is rendered as a block, that is when its code has many lines or a language is given.
+func isCodeBlock(language, code string) bool {
+	return language != "" || strings.Contains(strings.TrimSpace(code), "\n")
+}
+
+// listItems returns the content of the #html.li items of an #html.ul or #html.ol list, the other nodes of the list are items on their own except for blank text.
+func listItems(children ast.Block) []ast.Block {
+	items := []ast.Block{}
+	for _, n := range children {
+		if elem, ok := n.(*ast.ElementNode); ok && elem.Name == "html.li" {
+			if len(elem.Arguments) > 0 {
+				items = append(items, elem.Arguments[len(elem.Arguments)-1])
+			}
+			continue
+		}
+		if text, ok := n.(*ast.TextNode); ok && strings.TrimSpace(text.Text) == "" {
+			continue
+		}
+
+		items = append(items, ast.Block{n})
+	}
+
+	return items
+}
+
+// dedentCode removes the blank lines around the code and the indentation common to its lines using [ast.Dedent], so a first line written right after the opening brace doesn't count.
+func dedentCode(code string) string {
+	dedented := ast.Dedent(ast.Block{ast.T(code)})
+	lines := strings.Split(dedented.Text(ast.TextOptions{}), "\n")
+
+	isBlank := func(line string) bool { return strings.TrimSpace(line) == "" }
+	for len(lines) > 0 && isBlank(lines[len(lines)-1]) {
+		lines = lines[:len(lines)-1]
+	}
+	if len(lines) > 0 {
+		lines[0] = strings.TrimSpace(lines[0])
+	}
+	for len(lines) > 0 && lines[0] == "" {
+		lines = lines[1:]
+	}
+
+	for i, line := range lines {
+		if isBlank(line) {
+			lines[i] = ""
+		}
+	}
+
+	return strings.Join(lines, "\n")
+}
+
+// paragraphBuffer collects the output of text formats where blank lines separate paragraphs, the text of the document is written by lines with the indentation removed.
+type paragraphBuffer struct {
+	bytes.Buffer
+
+	// lineStart tells whether an empty buffer is at the start of a line, this is false for buffers used to render the content of inline elements
+	lineStart bool
+}
+
+func (b *paragraphBuffer) atLineStart() bool {
+	if b.Len() == 0 {
+		return b.lineStart
+	}
+
+	return bytes.HasSuffix(b.Bytes(), []byte("\n"))
+}
+
+// trimTrailing removes the characters in the cutset from the end of the buffer.
+func (b *paragraphBuffer) trimTrailing(cutset string) {
+	b.Truncate(len(bytes.TrimRight(b.Bytes(), cutset)))
+}
+
+// blankLine ends the current paragraph, this is used before and after headings and code blocks.
+func (b *paragraphBuffer) blankLine() {
+	b.trimTrailing(" \t\n")
+	if b.Len() > 0 {
+		b.WriteString("\n\n")
+	}
+}
+
+// writeText writes text escaped line by line, indentation and spaces at the end of lines are removed and runs of blank lines are collapsed to a single one. The escape function also tells whether the line is at the start of a line.
+func (b *paragraphBuffer) writeText(s string, escape func(line string, lineStart bool) string) {
+	for i, line := range strings.Split(s, "\n") {
+		if i > 0 {
+			b.trimTrailing(" \t")
+
+			if (b.Len() > 0 || !b.lineStart) && !bytes.HasSuffix(b.Bytes(), []byte("\n\n")) {
+				b.WriteString("\n")
+			}
+		}
+
+		lineStart := b.atLineStart()
+		if lineStart {
+			line = strings.TrimLeft(line, " \t")
+		}
+
+		b.WriteString(escape(line, lineStart))
+	}
+}
+
+//
+// Dictionaries
+//
+
+// isTypedValue reports whether the data formats write the text of a value as a boolean or a number instead of a string. These are "true", "false", integers fitting in 64 bits and numbers with a decimal point written in their shortest form like 1.0 or 0.25, the text of other values like 1.10, 6.02e23, 0x1F or 01234 would not be read back unchanged.
+func isTypedValue(s string) bool {
+	if s == "true" || s == "false" {
+		return true
+	}
+
+	if n, err := strconv.ParseInt(s, 10, 64); err == nil {
+		return strconv.FormatInt(n, 10) == s
+	}
+
+	f, err := strconv.ParseFloat(s, 64)
+	if err != nil || math.IsInf(f, 0) || math.IsNaN(f) || !strings.Contains(s, ".") {
+		return false
+	}
+
+	shortest := strconv.FormatFloat(f, 'f', -1, 64)
+	if !strings.Contains(shortest, ".") {
+		shortest += ".0"
+	}
+
+	return shortest == s
+}
+
+// scalarValue returns the trimmed text of a value that is not a dictionary, like [textml.Unmarshal] does for text values, so entries written over many lines like #title{ ... } don't keep the surrounding newlines and indentation.
+func scalarValue(value ast.Block) string {
+	return strings.TrimSpace(value.Text(ast.ValueText))
+}
+
+// dictEntry is an entry of a dictionary shaped block, repeated elements are grouped in a single entry with many values
+type dictEntry struct {
+	Name   string
+	Values []ast.Block
+}
+
+// dictEntries groups the elements of a dictionary by name in order of first appearance, only the first argument of each element is used.
+func dictEntries(block ast.Block) []*dictEntry {
+	entries := []*dictEntry{}
+	byName := map[string]*dictEntry{}
+
+	for _, n := range block {
+		elem, ok := n.(*ast.ElementNode)
+		if !ok {
+			continue
+		}
+
+		value := ast.Block{}
+		if len(elem.Arguments) > 0 {
+			value = elem.Arguments[0]
+		}
+
+		entry, ok := byName[elem.Name]
+		if !ok {
+			entry = &dictEntry{Name: elem.Name}
+			byName[elem.Name] = entry
+			entries = append(entries, entry)
+		}
+
+		entry.Values = append(entry.Values, value)
+	}
+
+	return entries
+}
+
+// checkDictionary returns an error if the block is not a dictionary of #KEY{ VALUE } entries, values are checked recursively when they are dictionaries too.
+func checkDictionary(block ast.Block, format string) error {
+	for _, n := range block {
+		switch n := n.(type) {
+		case *ast.TextNode:
+			if text := strings.TrimSpace(n.Text); text != "" {
+				return fmt.Errorf("%v: unexpected text %q, the %s format expects a dictionary of #KEY{ VALUE } entries", n.Pos, text, format)
+			}
+		case *ast.ElementNode:
+			if len(n.Arguments) != 1 {
+				return fmt.Errorf("%v: entry #%s must have a single argument but has %d", n.Pos, n.Name, len(n.Arguments))
+			}
+
+			if n.Arguments[0].IsDictionary() {
+				if err := checkDictionary(n.Arguments[0], format); err != nil {
+					return err
+				}
+			}
+		}
+	}
+
+	return nil
+}
@@ -2,168 +2,257 @@ package transpile
 
 import (
 	"fmt"
-	"html"
+	"io"
 	"strings"
 
 	"github.com/aziis98/textml/ast"
+	"github.com/aziis98/textml/html"
 )
 
+// Html converts elements of the "html" namespace to the corresponding HTML elements, the elements of the runtime/document vocabulary like #title, #bold, #code and #link become their HTML equivalents so the two can be mixed.
 type Html struct {
 	Inline bool
 }
 
-var htmlElements = map[string]string{
-	"html.head":    "head",
-	"html.title":   "title",
-	"html.body":    "body",
-	"html.main":    "main",
-	"html.section": "section",
-	"html.p":       "p",
-	"html.h1":      "h1",
-	"html.h2":      "h2",
-	"html.h3":      "h3",
-	"html.h4":      "h4",
-	"html.h5":      "h5",
-	"html.h6":      "h6",
-	"html.ul":      "ul",
-	"html.ol":      "ol",
-	"html.li":      "li",
-	"html.strong":  "strong",
-	"html.em":      "em",
-	"html.b":       "b",
-	"html.i":       "i",
-	"html.u":       "u",
-	"html.code":    "code",
-	"html.pre":     "pre",
-	"html.div":     "div",
-	"html.span":    "span",
-	"html.img":     "img",
-	"html.figure":  "figure",
-}
-
-func (h *Html) writeElement(elem, s string) string {
-	args := []any{elem, html.EscapeString(strings.TrimSpace(s)), elem}
-
-	if h.Inline {
-		return fmt.Sprintf("<%s>%s</%s>", args...)
+func newHtml(opts Options, inline bool) (Transpiler, error) {
+	if err := opts.Check("inline"); err != nil {
+		return nil, err
 	}
-	return fmt.Sprintf("<%s>\n%s\n</%s>\n", args...)
-}
 
-func (h *Html) writeElementOpening(elem string, attrs map[string]string) string {
-	f := "<%s%s>\n"
-	if h.Inline {
-		f = "<%s%s>"
-	}
-
-	attrString := ""
-	for k, v := range attrs {
-		attrString += fmt.Sprintf(` %s="%s"`, k, v)
+	inline, err := opts.Bool("inline", inline)
+	if err != nil {
+		return nil, err
 	}
 
-	return fmt.Sprintf(f, elem, attrString)
+	return &Html{Inline: inline}, nil
 }
 
-func (h *Html) writeElementClosing(elem string) string {
-	f := "</%s>\n"
-	if h.Inline {
-		f = "</%s>"
+// isHTMLTagName reports whether a local name can be written as an HTML tag, these start with an ASCII letter like both the standard elements and custom elements like <my-element>.
+func isHTMLTagName(name string) bool {
+	if name == "" || !('a' <= name[0] && name[0] <= 'z' || 'A' <= name[0] && name[0] <= 'Z') {
+		return false
 	}
 
-	return fmt.Sprintf(f, elem)
+	return ast.IsValidName(name)
 }
 
-func (h *Html) TranspileElement(node *ast.ElementNode) (string, error) {
-	element, ok := htmlElements[node.Name]
-	if !ok {
-		return "", fmt.Errorf("invalid html element with name %q", node.Name)
+func (h *Html) printOptions() html.PrintOptions {
+	return html.PrintOptions{Minify: h.Inline}
+}
+
+// ElementNode converts an element of the "html" namespace to an HTML element, with two arguments the first one holds the attributes as #NAME{ VALUE } entries.
+func (h *Html) ElementNode(node *ast.ElementNode) (html.Node, error) {
+	element := node.LocalName()
+	if node.Namespace() != "html" || !isHTMLTagName(element) {
+		return nil, fmt.Errorf("%v: invalid html element with name %q", node.Pos, node.Name)
 	}
 
 	args := node.Arguments
-	if len(args) > 2 {
-		return "", fmt.Errorf(`invalid number of arguments for element "%q"`, element)
+	if len(args) != 1 && len(args) != 2 {
+		return nil, fmt.Errorf("%v: invalid argument count for #%s, expected 1 or 2 but got %d", node.Pos, node.Name, len(args))
 	}
 
-	htmlAttributes := map[string]string{}
+	attributes := html.AttributeMap{}
 
 	if len(args) == 2 {
 		var attrs ast.Block
 		attrs, args = args[0], args[1:]
 
 		for _, n := range attrs {
-			if elm, ok := n.(*ast.ElementNode); ok {
-				key := elm.Name
-
-				value := ""
-				if len(elm.Arguments) > 0 {
-					value = elm.Arguments[0].TextContent()
-				}
+			elm, ok := n.(*ast.ElementNode)
+			if !ok {
+				continue
+			}
+			if len(elm.Arguments) > 1 {
+				return nil, fmt.Errorf("%v: invalid argument count for attribute #%s, expected 0 or 1 but got %d", elm.Pos, elm.Name, len(elm.Arguments))
+			}
 
-				htmlAttributes[key] = value
+			value := ""
+			if len(elm.Arguments) > 0 {
+				value = elm.Arguments[0].Text(ast.ValueText)
 			}
+
+			attributes[elm.Name] = &html.Attribute{Value: value}
 		}
 	}
 
-	htmlBlock, err := h.TranspileBlock(args[0])
+	children, err := h.BlockNodes(args[0])
 	if err != nil {
-		return "", err
+		return nil, err
+	}
+
+	return html.NewElementNode(element, attributes, children), nil
+}
+
+// htmlFormatting maps the inline formatting elements of runtime/document to HTML tags
+var htmlFormatting = map[string]string{
+	"bold":          "b",
+	"italic":        "i",
+	"underline":     "u",
+	"strikethrough": "s",
+}
+
+// documentNode converts an element of the runtime/document vocabulary to HTML, code blocks become <pre><code> elements with the language as a "language-NAME" class.
+func (h *Html) documentNode(node *ast.ElementNode) (html.Node, error) {
+	tag, ok := htmlFormatting[node.Name]
+	if level, heading := documentHeadings[node.Name]; heading {
+		tag, ok = fmt.Sprintf("h%d", level), true
 	}
 
-	sb := &strings.Builder{}
+	if ok {
+		if err := checkArity(node, 1); err != nil {
+			return nil, err
+		}
 
-	fmt.Fprintf(sb, h.writeElementOpening(element, htmlAttributes))
-	fmt.Fprintf(sb, htmlBlock)
-	if !h.Inline {
-		fmt.Fprintln(sb)
+		children, err := h.BlockNodes(node.Arguments[0])
+		if err != nil {
+			return nil, err
+		}
+
+		return html.NewElementNode(tag, nil, children), nil
 	}
-	fmt.Fprintf(sb, h.writeElementClosing(element))
 
-	return sb.String(), nil
+	switch node.Name {
+	case "code":
+		if err := checkArity(node, 1); err != nil {
+			return nil, err
+		}
+
+		language, code := codeContent(node.Arguments[0])
+		if !isCodeBlock(language, code) {
+			return html.NewElementNode("code", nil, []html.Node{html.NewTextNode(strings.TrimSpace(code))}), nil
+		}
+
+		attributes := html.AttributeMap{}
+		if language != "" {
+			attributes["class"] = &html.Attribute{Value: "language-" + language}
+		}
+
+		return html.NewElementNode("pre", nil, []html.Node{
+			html.NewElementNode("code", attributes, []html.Node{html.NewTextNode(dedentCode(code))}),
+		}), nil
+
+	case "link":
+		if err := checkArity(node, 2); err != nil {
+			return nil, err
+		}
+
+		children, err := h.BlockNodes(node.Arguments[0])
+		if err != nil {
+			return nil, err
+		}
+
+		return html.NewElementNode("a", html.AttributeMap{
+			"href": &html.Attribute{Value: node.Arguments[1].Text(linkTargetText)},
+		}, children), nil
+	}
+
+	return nil, fmt.Errorf("%v: element #%s is not supported by the html format", node.Pos, node.Name)
 }
 
-func (h *Html) TranspileBlock(b ast.Block) (string, error) {
-	sb := &strings.Builder{}
+// BlockNodes converts a block to HTML nodes.
+func (h *Html) BlockNodes(b ast.Block) ([]html.Node, error) {
+	nodes := []html.Node{}
 
 	for _, node := range b {
 		switch node := node.(type) {
 		case *ast.ElementNode:
-			htmlElem, err := h.TranspileElement(node)
+			convert := h.ElementNode
+			if node.Namespace() != "html" {
+				convert = h.documentNode
+			}
+
+			htmlElem, err := convert(node)
 			if err != nil {
-				return "", err
+				return nil, err
 			}
 
-			fmt.Fprintln(sb, htmlElem)
+			nodes = append(nodes, htmlElem)
 
 		case *ast.TextNode:
-			if len(strings.TrimSpace(node.Text)) > 0 {
-				fmt.Fprintln(sb, html.EscapeString(strings.TrimSpace(node.Text)))
-			}
+			nodes = append(nodes, html.NewTextNode(node.Text))
 
 		default:
 			panic("invalid node type")
 		}
 	}
 
-	if !h.Inline {
-		fmt.Fprintln(sb)
+	return nodes, nil
+}
+
+func (h *Html) TranspileElement(node *ast.ElementNode) (string, error) {
+	htmlElem, err := h.ElementNode(node)
+	if err != nil {
+		return "", err
 	}
 
-	return sb.String(), nil
+	return html.PrintToString([]html.Node{htmlElem}, h.printOptions()), nil
 }
 
-func (h *Html) Transpile(b ast.Block) (string, error) {
-
-	htmlBlock, err := h.TranspileBlock(b)
+func (h *Html) TranspileBlock(b ast.Block) (string, error) {
+	nodes, err := h.BlockNodes(b)
 	if err != nil {
 		return "", err
 	}
 
-	sb := &strings.Builder{}
+	return html.PrintToString(nodes, h.printOptions()), nil
+}
+
+// headNodes converts the #metadata entries to the content of the <head> element, the title becomes the <title> of the page and the other text entries become <meta> elements.
+func headNodes(metadata ast.Block) []html.Node {
+	nodes := []html.Node{}
+
+	for _, entry := range dictEntries(metadata) {
+		values := []string{}
+		for _, value := range entry.Values {
+			if !value.IsDictionary() {
+				values = append(values, scalarValue(value))
+			}
+		}
+		if len(values) == 0 {
+			continue
+		}
 
-	fmt.Fprintln(sb, "<!DOCTYPE html>")
-	fmt.Fprintln(sb, "<html>")
-	fmt.Fprintln(sb, htmlBlock)
-	fmt.Fprintln(sb, "</html>")
+		// like the document runtime the last title wins
+		if entry.Name == "title" {
+			nodes = append(nodes, html.NewElementNode("title", nil, []html.Node{html.NewTextNode(values[len(values)-1])}))
+			continue
+		}
+
+		nodes = append(nodes, html.NewElementNode("meta", html.AttributeMap{
+			"name":    &html.Attribute{Value: entry.Name},
+			"content": &html.Attribute{Value: strings.Join(values, ", ")},
+		}, nil))
+	}
+
+	return nodes
+}
+
+// Transpile writes an HTML page with the document as the content of the <html> element, the entries of the top level #metadata elements go in the <head>.
+func (h *Html) Transpile(w io.Writer, b ast.Block) error {
+	b, err := ast.ResolveNamespaces(b)
+	if err != nil {
+		return err
+	}
+
+	b = ast.Normalize(b, ast.MergeText)
+
+	metadata, content, err := splitMetadata(b)
+	if err != nil {
+		return err
+	}
+
+	nodes, err := h.BlockNodes(content)
+	if err != nil {
+		return err
+	}
+
+	if head := headNodes(metadata); len(head) > 0 {
+		nodes = append([]html.Node{html.NewElementNode("head", nil, head)}, nodes...)
+	}
 
-	return sb.String(), nil
+	return html.Print(w, []html.Node{
+		html.NewDoctypeNode(),
+		html.NewElementNode("html", nil, nodes),
+	}, h.printOptions())
 }
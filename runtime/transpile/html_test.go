@@ -0,0 +1,90 @@
+package transpile_test
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/aziis98/textml"
+	"github.com/aziis98/textml/ast"
+	"github.com/aziis98/textml/html"
+	"github.com/aziis98/textml/runtime/document"
+	"github.com/aziis98/textml/runtime/transpile"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestHtmlErrors(t *testing.T) {
+	h := &transpile.Html{Inline: true}
+
+	for source, message := range map[string]string{
+		"#html.p{ #class{ a }{ b } }{}": "1:10: invalid argument count for attribute #class, expected 0 or 1 but got 2",
+		"\n#html.p{ a }{ b }{ c }":      "2:1: invalid argument count for #html.p, expected 1 or 2 but got 3",
+		"#html.1x{ a }":                 `1:1: invalid html element with name "html.1x"`,
+	} {
+		doc, err := textml.ParseDocument(strings.NewReader(source))
+		assert.Nil(t, err)
+
+		err = h.Transpile(&strings.Builder{}, doc)
+		assert.EqualError(t, err, message, source)
+	}
+
+	// elements without arguments come from the JSON format or code
+	_, err := h.TranspileElement(ast.EN("html.div"))
+	assert.EqualError(t, err, "-: invalid argument count for #html.div, expected 1 or 2 but got 0")
+
+	result, err := h.TranspileElement(ast.EN("html.input", ast.B(ast.EN("disabled")), ast.B()))
+	assert.Nil(t, err)
+	assert.Equal(t, `<input disabled="">`, result)
+}
+
+func TestHtmlDocumentElements(t *testing.T) {
+	doc, err := textml.ParseDocument(strings.NewReader(`
+#title{ A #italic{ title } }
+
+#html.p{ #bold{ Bold }, #underline{ under }, #strikethrough{ deleted }, #code{ x < y } and a #link{ link }{ https://example.org }. }
+
+#code{{
+    #format{{ go }}
+    if a < b {}
+}}`))
+	assert.Nil(t, err)
+
+	result, err := (&transpile.Html{Inline: true}).TranspileBlock(doc)
+	assert.Nil(t, err)
+	assert.Equal(t, `<h1>A <i>title</i></h1>`+
+		`<p><b>Bold</b>, <u>under</u>, <s>deleted</s>, <code>x &lt; y</code> and a <a href="https://example.org">link</a>.</p>`+
+		`<pre><code class="language-go">if a &lt; b {}</code></pre>`, result)
+
+	_, err = (&transpile.Html{Inline: true}).TranspileBlock(ast.B(ast.EN("unknown", ast.B())))
+	assert.EqualError(t, err, "-: element #unknown is not supported by the html format")
+}
+
+func TestHtmlMetadata(t *testing.T) {
+	doc, err := textml.ParseDocument(strings.NewReader(`
+#metadata{
+    #title{ An #italic{ example } }
+    #author{ Alice }
+    #author{ Bob }
+    #extra{ #a{ 1 } }
+}
+
+Text`))
+	assert.Nil(t, err)
+
+	sb := &strings.Builder{}
+	assert.Nil(t, (&transpile.Html{Inline: true}).Transpile(sb, doc))
+	assert.Equal(t, `<!DOCTYPE html><html><head><title>An example</title><meta content="Alice, Bob" name="author"></head>Text</html>`, sb.String())
+}
+
+func TestHtmlAttributeText(t *testing.T) {
+	doc, err := textml.ParseDocument(strings.NewReader(`#html.abbr{ #title{ An #italic{ example } } }{ ex }`))
+	assert.Nil(t, err)
+
+	sb := &strings.Builder{}
+	assert.Nil(t, (&transpile.Html{Inline: true}).Transpile(sb, doc))
+	assert.Contains(t, sb.String(), `<abbr title="An example">ex</abbr>`)
+
+	// the document engine reads the same attribute value
+	_, nodes, err := (&document.Engine{}).Render(doc)
+	assert.Nil(t, err)
+	assert.Equal(t, `<abbr title="An example">ex</abbr>`, strings.TrimSpace(html.RenderToString(nodes)))
+}
@@ -0,0 +1,476 @@
+package transpile
+
+import (
+	"fmt"
+	"io"
+	"os"
+	"regexp"
+	"strconv"
+	"strings"
+	"text/template"
+
+	"github.com/aziis98/textml/ast"
+)
+
+// DefaultLatexTemplate is the template used by [Latex] when none is given, templates use "<<" and ">>" as delimiters to not clash with the braces of LaTeX and are executed with a [LatexDocument].
+const DefaultLatexTemplate = `\documentclass<<with .ClassOptions>>[<<.>>]<<end>>{<<.Class>>}
+
+\usepackage[T1]{fontenc}
+\usepackage[utf8]{inputenc}
+\usepackage[normalem]{ulem}
+<<- if .Listings>>
+\usepackage{listings}
+<<- end>>
+\usepackage{hyperref}
+<<with .Title>>
+\title{<<.>>}
+<<- end>>
+<<- with .Author>>
+\author{<<.>>}
+<<- end>>
+<<- with .Date>>
+\date{<<.>>}
+<<- end>>
+
+\begin{document}
+<<if .Title>>
+\maketitle
+<<end>>
+<<.Body>>
+\end{document}
+`
+
+// LatexDocument holds the values available to the template of [Latex], values coming from the document are already escaped.
+type LatexDocument struct {
+	Class        string
+	ClassOptions string
+
+	// Listings tells whether code blocks use the listings package
+	Listings bool
+
+	// Title, Author and Date come from the #metadata of the document, many #author entries are joined with \and
+	Title  string
+	Author string
+	Date   string
+
+	// Metadata holds the text of all the #metadata entries that are not dictionaries, repeated entries are joined with commas
+	Metadata map[string]string
+
+	// Body is the content of the document
+	Body string
+}
+
+// Latex converts documents using the vocabulary of runtime/document to LaTeX, the title, author and date in the #metadata go in the preamble. Lists and quotes come from the "html" namespace like #html.ul{ #html.li{ ... } } and become itemize, enumerate and quote environments, the other html elements are not supported.
+type Latex struct {
+	// Class is the document class, "article" when empty
+	Class string
+
+	// ClassOptions are the options of the document class, like "a4paper,11pt"
+	ClassOptions string
+
+	// Template is the source of the template of the whole document, [DefaultLatexTemplate] when empty
+	Template string
+
+	// Listings writes code blocks as lstlisting environments instead of verbatim ones
+	Listings bool
+}
+
+func newLatex(opts Options) (Transpiler, error) {
+	if err := opts.Check("class", "class-options", "template", "code"); err != nil {
+		return nil, err
+	}
+
+	t := &Latex{
+		Class:        opts.String("class", "article"),
+		ClassOptions: opts.String("class-options", ""),
+	}
+
+	switch code := opts.String("code", "verbatim"); code {
+	case "verbatim":
+	case "listings":
+		t.Listings = true
+	default:
+		return nil, fmt.Errorf("invalid value %q for option %q, expected verbatim or listings", code, "code")
+	}
+
+	if path, ok := opts["template"]; ok {
+		source, err := os.ReadFile(path)
+		if err != nil {
+			return nil, err
+		}
+
+		t.Template = string(source)
+	}
+
+	return t, nil
+}
+
+// latexSections maps heading levels to sectioning commands
+var latexSections = map[int]string{
+	1: "section",
+	2: "subsection",
+	3: "subsubsection",
+	4: "paragraph",
+}
+
+// latexCommands maps inline formatting elements to commands, underline and strikethrough come from the ulem package
+var latexCommands = map[string]string{
+	"bold":          "textbf",
+	"italic":        "emph",
+	"underline":     "uline",
+	"strikethrough": "sout",
+}
+
+// Transpile writes the document as a LaTeX source file.
+func (t *Latex) Transpile(w io.Writer, block ast.Block) error {
+	source := t.Template
+	if source == "" {
+		source = DefaultLatexTemplate
+	}
+
+	tmpl, err := template.New("latex").Delims("<<", ">>").Parse(source)
+	if err != nil {
+		return err
+	}
+
+	block, err = ast.ResolveNamespaces(block)
+	if err != nil {
+		return err
+	}
+
+	block = ast.Normalize(block, ast.MergeText)
+
+	metadata, content, err := splitMetadata(block)
+	if err != nil {
+		return err
+	}
+
+	doc := &LatexDocument{
+		Class:        t.Class,
+		ClassOptions: t.ClassOptions,
+		Listings:     t.Listings,
+		Metadata:     map[string]string{},
+	}
+	if doc.Class == "" {
+		doc.Class = "article"
+	}
+
+	r := &latexRenderer{listings: t.Listings}
+
+	for _, entry := range dictEntries(metadata) {
+		values := []string{}
+		for _, value := range entry.Values {
+			if !value.IsDictionary() {
+				values = append(values, escapeLatex(scalarValue(value), false))
+			}
+		}
+		if len(values) > 0 {
+			doc.Metadata[entry.Name] = strings.Join(values, ", ")
+		}
+
+		var field *string
+		separator := ""
+
+		switch entry.Name {
+		case "title":
+			field = &doc.Title
+		case "author":
+			field, separator = &doc.Author, ` \and `
+		case "date":
+			field = &doc.Date
+		default:
+			continue
+		}
+
+		rendered := []string{}
+		for _, value := range entry.Values {
+			s, err := r.inlineContent(value)
+			if err != nil {
+				return err
+			}
+
+			rendered = append(rendered, strings.TrimSpace(s))
+		}
+
+		// like the document runtime the last entry wins, except for authors
+		if separator == "" {
+			rendered = rendered[len(rendered)-1:]
+		}
+
+		*field = strings.Join(rendered, separator)
+	}
+
+	r.buf.lineStart = true
+	if err := r.renderBlock(content); err != nil {
+		return err
+	}
+
+	doc.Body = strings.TrimRight(r.buf.String(), " \t\n") + "\n"
+
+	return tmpl.Execute(w, doc)
+}
+
+// latexRenderer writes LaTeX to a buffer, the content of commands is rendered by nested renderers.
+type latexRenderer struct {
+	buf paragraphBuffer
+
+	// inline is set inside commands where sections and code blocks are not allowed
+	inline bool
+
+	listings bool
+
+	// enumerate is the nesting depth of enumerate environments, these have a counter for each level
+	enumerate int
+}
+
+// inlineContent renders a block with a nested renderer in inline mode.
+func (r *latexRenderer) inlineContent(block ast.Block) (string, error) {
+	nested := &latexRenderer{inline: true, listings: r.listings, enumerate: r.enumerate}
+	if err := nested.renderBlock(block); err != nil {
+		return "", err
+	}
+
+	return nested.buf.String(), nil
+}
+
+func (r *latexRenderer) renderBlock(block ast.Block) error {
+	for _, n := range block {
+		switch n := n.(type) {
+		case *ast.TextNode:
+			r.buf.writeText(n.Text, escapeLatex)
+		case *ast.ElementNode:
+			if err := r.renderElement(n); err != nil {
+				return err
+			}
+		default:
+			panic("illegal state")
+		}
+	}
+
+	return nil
+}
+
+func (r *latexRenderer) renderElement(elem *ast.ElementNode) error {
+	if elem.Namespace() == "html" {
+		return r.renderHTML(elem)
+	}
+
+	if level, ok := documentHeadings[elem.Name]; ok {
+		if err := checkArity(elem, 1); err != nil {
+			return err
+		}
+		if r.inline {
+			return fmt.Errorf("%v: heading #%s can't be nested in inline formatting", elem.Pos, elem.Name)
+		}
+
+		content, err := r.inlineContent(elem.Arguments[0])
+		if err != nil {
+			return err
+		}
+
+		r.buf.blankLine()
+		r.buf.WriteString(`\` + latexSections[level] + "{" + strings.TrimSpace(strings.ReplaceAll(content, "\n", " ")) + "}")
+		r.buf.blankLine()
+
+		return nil
+	}
+
+	if command, ok := latexCommands[elem.Name]; ok {
+		if err := checkArity(elem, 1); err != nil {
+			return err
+		}
+
+		content, err := r.inlineContent(elem.Arguments[0])
+		if err != nil {
+			return err
+		}
+		if err := checkParagraphs(elem, content); err != nil {
+			return err
+		}
+
+		r.buf.WriteString(`\` + command + "{" + strings.TrimSpace(content) + "}")
+		return nil
+	}
+
+	switch elem.Name {
+	case "code":
+		if err := checkArity(elem, 1); err != nil {
+			return err
+		}
+
+		return r.renderCode(elem)
+
+	case "link":
+		if err := checkArity(elem, 2); err != nil {
+			return err
+		}
+
+		label, err := r.inlineContent(elem.Arguments[0])
+		if err != nil {
+			return err
+		}
+		if err := checkParagraphs(elem, label); err != nil {
+			return err
+		}
+
+		r.buf.WriteString(`\href{` + latexURL(elem.Arguments[1].Text(linkTargetText)) + "}{" + strings.TrimSpace(label) + "}")
+		return nil
+	}
+
+	return fmt.Errorf("%v: element #%s is not supported by the latex format", elem.Pos, elem.Name)
+}
+
+// latexEnvironments maps the lists and quotes of the "html" namespace to environments
+var latexEnvironments = map[string]string{
+	"ul":         "itemize",
+	"ol":         "enumerate",
+	"blockquote": "quote",
+}
+
+// latexCounters are the counters of nested enumerate environments by depth
+var latexCounters = []string{"enumi", "enumii", "enumiii", "enumiv"}
+
+// blockContent renders a block with a nested renderer for the content of an environment.
+func (r *latexRenderer) blockContent(block ast.Block, enumerate int) (string, error) {
+	nested := &latexRenderer{buf: paragraphBuffer{lineStart: true}, listings: r.listings, enumerate: enumerate}
+	if err := nested.renderBlock(block); err != nil {
+		return "", err
+	}
+
+	return strings.TrimSpace(nested.buf.String()), nil
+}
+
+// renderHTML writes the lists and quotes of the "html" namespace as environments, the start attribute of ordered lists sets the first number.
+func (r *latexRenderer) renderHTML(elem *ast.ElementNode) error {
+	environment, ok := latexEnvironments[elem.LocalName()]
+	if !ok {
+		return fmt.Errorf("%v: element #%s is not supported by the latex format", elem.Pos, elem.Name)
+	}
+	if len(elem.Arguments) != 1 && len(elem.Arguments) != 2 {
+		return fmt.Errorf("%v: invalid argument count for #%s, expected 1 or 2 but got %d", elem.Pos, elem.Name, len(elem.Arguments))
+	}
+	if r.inline {
+		return fmt.Errorf("%v: #%s can't be nested in inline formatting", elem.Pos, elem.Name)
+	}
+
+	attributes, children := ast.Block{}, elem.Arguments[len(elem.Arguments)-1]
+	if len(elem.Arguments) == 2 {
+		attributes = elem.Arguments[0]
+	}
+
+	body := []string{}
+
+	switch environment {
+	case "quote":
+		content, err := r.blockContent(children, r.enumerate)
+		if err != nil {
+			return err
+		}
+		if content != "" {
+			body = append(body, content)
+		}
+
+	default:
+		enumerate := r.enumerate
+		if environment == "enumerate" {
+			enumerate++
+			if enumerate > len(latexCounters) {
+				return fmt.Errorf("%v: enumerate environments can't be nested more than %d levels", elem.Pos, len(latexCounters))
+			}
+
+			if start, err := strconv.Atoi(htmlAttribute(attributes, "start")); err == nil && start != 1 {
+				body = append(body, fmt.Sprintf(`\setcounter{%s}{%d}`, latexCounters[enumerate-1], start-1))
+			}
+		}
+
+		for _, item := range listItems(children) {
+			content, err := r.blockContent(item, enumerate)
+			if err != nil {
+				return err
+			}
+
+			body = append(body, strings.TrimSpace(`\item `+content))
+		}
+	}
+
+	r.buf.blankLine()
+	r.buf.WriteString(`\begin{` + environment + "}\n")
+	for _, line := range body {
+		r.buf.WriteString(line + "\n")
+	}
+	r.buf.WriteString(`\end{` + environment + "}")
+	r.buf.blankLine()
+
+	return nil
+}
+
+// regexLatexLanguage matches the language names written in the options of lstlisting, other characters like "]" or "," would end the option
+var regexLatexLanguage = regexp.MustCompile(`^[A-Za-z0-9+#-]+$`)
+
+// renderCode writes single line code with \texttt and the rest as a verbatim or lstlisting environment.
+func (r *latexRenderer) renderCode(elem *ast.ElementNode) error {
+	language, code := codeContent(elem.Arguments[0])
+	if r.inline || !isCodeBlock(language, code) {
+		r.buf.WriteString(`\texttt{` + escapeLatex(strings.TrimSpace(strings.ReplaceAll(code, "\n", " ")), false) + "}")
+		return nil
+	}
+
+	environment, options := "verbatim", ""
+	if r.listings {
+		environment = "lstlisting"
+		if language != "" {
+			if !regexLatexLanguage.MatchString(language) {
+				return fmt.Errorf("%v: invalid code language %q, expected letters, digits, \"+\", \"#\" or \"-\"", elem.Pos, language)
+			}
+
+			options = "[language=" + language + "]"
+		}
+	}
+
+	end := `\end{` + environment + "}"
+	if strings.Contains(code, end) {
+		return fmt.Errorf("%v: code blocks can't contain %q", elem.Pos, end)
+	}
+
+	r.buf.blankLine()
+	r.buf.WriteString(`\begin{` + environment + "}" + options + "\n")
+	if code := dedentCode(code); code != "" {
+		r.buf.WriteString(code + "\n")
+	}
+	r.buf.WriteString(end)
+	r.buf.blankLine()
+
+	return nil
+}
+
+// latexEscapes replaces the special characters of LaTeX with commands printing them
+var latexEscapes = strings.NewReplacer(
+	`\`, `\textbackslash{}`,
+	"{", `\{`,
+	"}", `\}`,
+	"$", `\$`,
+	"&", `\&`,
+	"#", `\#`,
+	"%", `\%`,
+	"_", `\_`,
+	"~", `\textasciitilde{}`,
+	"^", `\textasciicircum{}`,
+)
+
+// escapeLatex escapes the special characters of a line of text, unlike Markdown there is no syntax specific to the start of a line.
+func escapeLatex(line string, lineStart bool) string {
+	return latexEscapes.Replace(line)
+}
+
+// latexURLEscapes escapes URLs for \href, "#" and "%" are escaped as hyperref expects while backslashes and braces are percent encoded
+var latexURLEscapes = strings.NewReplacer(
+	"#", `\#`,
+	"%", `\%`,
+	`\`, "%5C",
+	"{", "%7B",
+	"}", "%7D",
+)
+
+func latexURL(url string) string {
+	return latexURLEscapes.Replace(url)
+}
@@ -0,0 +1,170 @@
+package transpile_test
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/aziis98/textml"
+	"github.com/aziis98/textml/runtime/transpile"
+	"github.com/stretchr/testify/assert"
+)
+
+func transpileLatex(t *testing.T, source string, opts transpile.Options) (string, error) {
+	t.Helper()
+
+	doc, err := textml.ParseDocument(strings.NewReader(source))
+	assert.Nil(t, err)
+
+	tr, err := transpile.New("latex", opts)
+	assert.Nil(t, err)
+
+	sb := &strings.Builder{}
+	err = tr.Transpile(sb, doc)
+	return sb.String(), err
+}
+
+func TestLatex(t *testing.T) {
+	result, err := transpileLatex(t, `
+#metadata {
+    #title { Example #italic{ Title } }
+    #author { Alice }
+    #author { Bob & Carol }
+    #date { 2022-08-15 }
+}
+
+#title { Introduction }
+
+Some #bold{ bold }, #italic{ italic }, #underline{ underlined } and #strikethrough{ deleted } text
+with #code{ inline code } and a #link{ link }{ https://example.org/#top }.
+
+#code {{
+    int main() {
+        return 0;
+    }
+}}
+`, nil)
+	assert.Nil(t, err)
+	assert.Equal(t, `\documentclass{article}
+
+\usepackage[T1]{fontenc}
+\usepackage[utf8]{inputenc}
+\usepackage[normalem]{ulem}
+\usepackage{hyperref}
+
+\title{Example \emph{Title}}
+\author{Alice \and Bob \& Carol}
+\date{2022-08-15}
+
+\begin{document}
+
+\maketitle
+
+\section{Introduction}
+
+Some \textbf{bold}, \emph{italic}, \uline{underlined} and \sout{deleted} text
+with \texttt{inline code} and a \href{https://example.org/\#top}{link}.
+
+\begin{verbatim}
+int main() {
+    return 0;
+}
+\end{verbatim}
+
+\end{document}
+`, result)
+}
+
+func TestLatexEscape(t *testing.T) {
+	result, err := transpileLatex(t, `#subtitle{ 100% of $5 } a_b ~ ^ \ & #code{{ {x} }}`, transpile.Options{"template": writeTemplate(t, "<<.Body>>")})
+	assert.Nil(t, err)
+	assert.Equal(t, `\subsection{100\% of \$5}
+
+a\_b \textasciitilde{} \textasciicircum{} \textbackslash{} \& \texttt{\{x\}}
+`, result)
+}
+
+func writeTemplate(t *testing.T, source string) string {
+	t.Helper()
+
+	path := filepath.Join(t.TempDir(), "template.tex")
+	assert.Nil(t, os.WriteFile(path, []byte(source), 0644))
+
+	return path
+}
+
+func TestLatexOptions(t *testing.T) {
+	source := "#metadata{ #title{ T } #version{ 1.0 } }\n#code{{ #format{{ Go }} x := 1 }}"
+
+	result, err := transpileLatex(t, source, transpile.Options{"class": "report", "class-options": "a4paper", "code": "listings"})
+	assert.Nil(t, err)
+	assert.Contains(t, result, `\documentclass[a4paper]{report}`)
+	assert.Contains(t, result, `\usepackage{listings}`)
+	assert.Contains(t, result, "\\begin{lstlisting}[language=Go]\nx := 1\n\\end{lstlisting}")
+
+	template := writeTemplate(t, `\documentclass{<<.Class>>} % <<.Title>> v<<.Metadata.version>>`+"\n<<.Body>>")
+	result, err = transpileLatex(t, source, transpile.Options{"template": template})
+	assert.Nil(t, err)
+	assert.Equal(t, "\\documentclass{article} % T v1.0\n\\begin{verbatim}\nx := 1\n\\end{verbatim}\n", result)
+
+	result, err = transpileLatex(t, "#code{{ #format{{ C++ }} x++ }}", transpile.Options{"code": "listings"})
+	assert.Nil(t, err)
+	assert.Contains(t, result, `\begin{lstlisting}[language=C++]`)
+
+	_, err = transpileLatex(t, "\n#code{{ #format{{ Go],escapechar=| }} x := 1 }}", transpile.Options{"code": "listings"})
+	assert.EqualError(t, err, `2:1: invalid code language "Go],escapechar=|", expected letters, digits, "+", "#" or "-"`)
+
+	_, err = transpile.New("latex", transpile.Options{"code": "minted"})
+	assert.EqualError(t, err, `format "latex": invalid value "minted" for option "code", expected verbatim or listings`)
+}
+
+func TestLatexLists(t *testing.T) {
+	result, err := transpileLatex(t, `
+#html.ul{
+    #html.li{ one #bold{ b } }
+    #html.li{
+        two
+        #html.ol{ #start{ 3 } }{
+            #html.li{ three }
+        }
+    }
+}
+
+#html.blockquote{ A #italic{ quote } }`, transpile.Options{"template": writeTemplate(t, "<<.Body>>")})
+	assert.Nil(t, err)
+	assert.Equal(t, `\begin{itemize}
+\item one \textbf{b}
+\item two
+
+\begin{enumerate}
+\setcounter{enumi}{2}
+\item three
+\end{enumerate}
+\end{itemize}
+
+\begin{quote}
+A \emph{quote}
+\end{quote}
+`, result)
+}
+
+func TestLatexErrors(t *testing.T) {
+	_, err := transpileLatex(t, "#html.div{ x }", nil)
+	assert.ErrorContains(t, err, "element #html.div is not supported by the latex format")
+
+	_, err = transpileLatex(t, "#bold{ #html.ul{ #html.li{ x } } }", nil)
+	assert.EqualError(t, err, "1:8: #html.ul can't be nested in inline formatting")
+
+	_, err = transpileLatex(t, "#italic{ #subtitle{ x } }", nil)
+	assert.ErrorContains(t, err, "heading #subtitle can't be nested in inline formatting")
+
+	_, err = transpileLatex(t, "#bold{ a\n\n b }", nil)
+	assert.EqualError(t, err, "1:1: #bold can't contain a paragraph break")
+
+	_, err = transpileLatex(t, "#link{ a\n\n b }{ https://example.org }", nil)
+	assert.EqualError(t, err, "1:1: #link can't contain a paragraph break")
+
+	_, err = transpileLatex(t, "#code{{\n    a\n    \\end{verbatim}\n}}", nil)
+	assert.ErrorContains(t, err, `code blocks can't contain "\\end{verbatim}"`)
+}
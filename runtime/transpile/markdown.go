@@ -0,0 +1,345 @@
+package transpile
+
+import (
+	"bytes"
+	"fmt"
+	"io"
+	"regexp"
+	"strings"
+	"unicode"
+	"unicode/utf8"
+
+	"github.com/aziis98/textml/ast"
+	"github.com/aziis98/textml/html"
+	"github.com/aziis98/textml/utils"
+)
+
+// Markdown converts documents using the vocabulary of runtime/document to CommonMark with the GFM strikethrough extension, the #metadata element becomes a YAML front matter.
+type Markdown struct{}
+
+func newMarkdown(opts Options) (Transpiler, error) {
+	if err := opts.Check(); err != nil {
+		return nil, err
+	}
+
+	return &Markdown{}, nil
+}
+
+// markdownDelimiters maps inline formatting elements to the markup placed around their content, Markdown has no underline so inline HTML is used
+var markdownDelimiters = map[string][2]string{
+	"bold":          {"**", "**"},
+	"italic":        {"*", "*"},
+	"strikethrough": {"~~", "~~"},
+	"underline":     {"<u>", "</u>"},
+}
+
+// Transpile writes the document as Markdown, top level #metadata elements are collected in the YAML front matter at the start.
+func (m *Markdown) Transpile(w io.Writer, block ast.Block) error {
+	block, err := ast.ResolveNamespaces(block)
+	if err != nil {
+		return err
+	}
+
+	block = ast.Normalize(block, ast.MergeText)
+
+	metadata, content, err := splitMetadata(block)
+	if err != nil {
+		return err
+	}
+
+	r := &markdownRenderer{buf: paragraphBuffer{lineStart: true}}
+	if err := r.renderBlock(content); err != nil {
+		return err
+	}
+
+	out := &bytes.Buffer{}
+	if metadata.FirstElement() != nil {
+		out.WriteString("---\n")
+		if err := writeYAMLMapping(out, metadata, ""); err != nil {
+			return err
+		}
+		out.WriteString("---\n")
+	}
+
+	if body := bytes.TrimRight(r.buf.Bytes(), " \t\n"); len(body) > 0 {
+		if out.Len() > 0 {
+			out.WriteString("\n")
+		}
+		out.Write(body)
+		out.WriteString("\n")
+	}
+
+	_, err = out.WriteTo(w)
+	return err
+}
+
+// markdownRenderer writes Markdown to a buffer, inline formatting renders its content with a nested renderer to place the delimiters around it.
+type markdownRenderer struct {
+	buf paragraphBuffer
+
+	// inline is set inside formatting elements where headings and code blocks are not allowed
+	inline bool
+}
+
+// inlineContent renders a block with a nested renderer in inline mode.
+func (r *markdownRenderer) inlineContent(block ast.Block) (string, error) {
+	nested := &markdownRenderer{inline: true}
+	if err := nested.renderBlock(block); err != nil {
+		return "", err
+	}
+
+	return nested.buf.String(), nil
+}
+
+func (r *markdownRenderer) renderBlock(block ast.Block) error {
+	for _, n := range block {
+		switch n := n.(type) {
+		case *ast.TextNode:
+			r.buf.writeText(n.Text, escapeMarkdown)
+		case *ast.ElementNode:
+			if err := r.renderElement(n); err != nil {
+				return err
+			}
+		default:
+			panic("illegal state")
+		}
+	}
+
+	return nil
+}
+
+func (r *markdownRenderer) renderElement(elem *ast.ElementNode) error {
+	if elem.Namespace() == "html" {
+		return r.renderHTML(elem)
+	}
+
+	if level, ok := documentHeadings[elem.Name]; ok {
+		if err := checkArity(elem, 1); err != nil {
+			return err
+		}
+		if r.inline {
+			return fmt.Errorf("%v: heading #%s can't be nested in inline formatting", elem.Pos, elem.Name)
+		}
+
+		content, err := r.inlineContent(elem.Arguments[0])
+		if err != nil {
+			return err
+		}
+
+		content = strings.TrimSpace(strings.ReplaceAll(content, "\n", " "))
+		if strings.HasSuffix(content, "#") && !strings.HasSuffix(content, `\#`) {
+			// a trailing "#" would be read as the optional closing sequence
+			content = content[:len(content)-1] + `\#`
+		}
+
+		r.buf.blankLine()
+		r.buf.WriteString(strings.Repeat("#", level))
+		if content != "" {
+			r.buf.WriteString(" " + content)
+		}
+		r.buf.blankLine()
+
+		return nil
+	}
+
+	if delimiters, ok := markdownDelimiters[elem.Name]; ok {
+		if err := checkArity(elem, 1); err != nil {
+			return err
+		}
+
+		content, err := r.inlineContent(elem.Arguments[0])
+		if err != nil {
+			return err
+		}
+		if err := checkParagraphs(elem, content); err != nil {
+			return err
+		}
+
+		r.writeDelimited(delimiters[0], content, delimiters[1])
+		return nil
+	}
+
+	switch elem.Name {
+	case "code":
+		if err := checkArity(elem, 1); err != nil {
+			return err
+		}
+
+		r.renderCode(elem.Arguments[0])
+		return nil
+
+	case "link":
+		if err := checkArity(elem, 2); err != nil {
+			return err
+		}
+
+		label, err := r.inlineContent(elem.Arguments[0])
+		if err != nil {
+			return err
+		}
+		if err := checkParagraphs(elem, label); err != nil {
+			return err
+		}
+
+		r.writeDelimited("[", label, "]("+markdownLinkDestination(elem.Arguments[1].Text(linkTargetText))+")")
+		return nil
+	}
+
+	return fmt.Errorf("%v: element #%s is not supported by the markdown format", elem.Pos, elem.Name)
+}
+
+// writeDelimited writes the content between the delimiters, the spaces around the content are moved outside as emphasis can't start or end with a space. Empty content is dropped unless the closing delimiter is part of a link.
+func (r *markdownRenderer) writeDelimited(open, content, close string) {
+	trimmed := strings.TrimSpace(content)
+	if trimmed == "" && open != "[" {
+		r.buf.WriteString(content)
+		return
+	}
+
+	leading := content[:strings.Index(content, trimmed)]
+	trailing := content[len(leading)+len(trimmed):]
+
+	r.buf.WriteString(leading)
+	r.buf.WriteString(open + trimmed + close)
+	r.buf.WriteString(trailing)
+}
+
+// renderCode writes single line code as a code span and the rest as a fenced code block, a #format{ LANGUAGE } element in the content also gives a code block with the language as info string.
+func (r *markdownRenderer) renderCode(block ast.Block) {
+	language, code := codeContent(block)
+	if r.inline || !isCodeBlock(language, code) {
+		r.writeCodeSpan(strings.ReplaceAll(code, "\n", " "))
+		return
+	}
+
+	// backtick fences can't have backticks in the info string
+	fenceChar := "`"
+	if strings.Contains(language, "`") {
+		fenceChar = "~"
+	}
+	fence := strings.Repeat(fenceChar, utils.Max(3, longestRun(code, fenceChar[0])+1))
+
+	r.buf.blankLine()
+	r.buf.WriteString(fence + language + "\n")
+	if code := dedentCode(code); code != "" {
+		r.buf.WriteString(code + "\n")
+	}
+	r.buf.WriteString(fence)
+	r.buf.blankLine()
+}
+
+// writeCodeSpan writes code between enough backticks to contain its backticks, spaces are added when the code starts or ends with a backtick or with a space that would be stripped.
+func (r *markdownRenderer) writeCodeSpan(code string) {
+	if code == "" {
+		return
+	}
+
+	fence := strings.Repeat("`", longestRun(code, '`')+1)
+	if strings.HasPrefix(code, "`") || strings.HasSuffix(code, "`") ||
+		(strings.HasPrefix(code, " ") && strings.HasSuffix(code, " ") && strings.TrimSpace(code) != "") {
+		code = " " + code + " "
+	}
+
+	r.buf.WriteString(fence + code + fence)
+}
+
+// renderHTML writes an element of the "html" namespace as HTML, block elements are placed in their own paragraph to form an HTML block. Markdown isn't read inside HTML blocks so the document elements in the subtree are written as HTML too, like #bold{ ... } as <b>...</b>.
+func (r *markdownRenderer) renderHTML(elem *ast.ElementNode) error {
+	source, err := (&Html{Inline: true}).TranspileElement(elem)
+	if err != nil {
+		return err
+	}
+
+	if r.inline || !html.IsBlockElement(elem.LocalName()) {
+		r.buf.WriteString(source)
+		return nil
+	}
+
+	r.buf.blankLine()
+	r.buf.WriteString(source)
+	r.buf.blankLine()
+
+	return nil
+}
+
+// markdownLinkDestination returns the destination of a link, between angle brackets if it is empty or has spaces or unbalanced parentheses.
+func markdownLinkDestination(dest string) string {
+	depth, balanced := 0, true
+	for _, c := range dest {
+		switch c {
+		case '(':
+			depth++
+		case ')':
+			depth--
+			balanced = balanced && depth >= 0
+		}
+	}
+
+	if dest == "" || !balanced || depth != 0 || strings.ContainsAny(dest, " <>") || strings.IndexFunc(dest, unicode.IsControl) >= 0 {
+		return "<" + strings.NewReplacer(`\`, `\\`, "<", `\<`, ">", `\>`, "\n", " ").Replace(dest) + ">"
+	}
+
+	return strings.ReplaceAll(dest, `\`, `\\`)
+}
+
+// regexEntity matches the text after "&" that would be read as an entity or numeric character reference
+var regexEntity = regexp.MustCompile(`^(#[0-9]{1,7}|#[xX][0-9a-fA-F]{1,6}|[a-zA-Z][a-zA-Z0-9]*);`)
+
+// regexOrderedListMarker matches the start of a line that would be read as an ordered list item
+var regexOrderedListMarker = regexp.MustCompile(`^[0-9]{1,9}[.)]`)
+
+// escapeMarkdown escapes the characters of a line of text that would be read as Markdown syntax, some characters are special only at the start of a line.
+func escapeMarkdown(line string, lineStart bool) string {
+	sb := &strings.Builder{}
+
+	if lineStart {
+		if loc := regexOrderedListMarker.FindStringIndex(line); loc != nil {
+			sb.WriteString(line[:loc[1]-1] + `\` + line[loc[1]-1:loc[1]])
+			line = line[loc[1]:]
+		} else if line != "" && strings.ContainsRune("#>-+=", rune(line[0])) {
+			sb.WriteString(`\` + line[:1])
+			line = line[1:]
+		}
+	}
+
+	for i, c := range line {
+		switch c {
+		case '\\', '`', '*', '[', ']', '<', '~':
+			sb.WriteRune('\\')
+		case '_':
+			// underscores inside words like "snake_case" can't start or end emphasis
+			before, _ := utf8.DecodeLastRuneInString(line[:i])
+			after, _ := utf8.DecodeRuneInString(line[i+1:])
+			if !isAlphanumeric(before) || !isAlphanumeric(after) {
+				sb.WriteRune('\\')
+			}
+		case '&':
+			if regexEntity.MatchString(line[i+1:]) {
+				sb.WriteRune('\\')
+			}
+		}
+
+		sb.WriteRune(c)
+	}
+
+	return sb.String()
+}
+
+func isAlphanumeric(r rune) bool {
+	return unicode.IsLetter(r) || unicode.IsDigit(r)
+}
+
+// longestRun returns the length of the longest run of the character in s.
+func longestRun(s string, c byte) int {
+	longest, run := 0, 0
+	for i := 0; i < len(s); i++ {
+		if s[i] == c {
+			run++
+			longest = utils.Max(longest, run)
+		} else {
+			run = 0
+		}
+	}
+
+	return longest
+}
@@ -0,0 +1,162 @@
+package transpile_test
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/aziis98/textml"
+	"github.com/aziis98/textml/runtime/transpile"
+	"github.com/stretchr/testify/assert"
+)
+
+func transpileMarkdown(t *testing.T, source string) (string, error) {
+	t.Helper()
+
+	doc, err := textml.ParseDocument(strings.NewReader(source))
+	assert.Nil(t, err)
+
+	tr, err := transpile.New("markdown", nil)
+	assert.Nil(t, err)
+
+	sb := &strings.Builder{}
+	err = tr.Transpile(sb, doc)
+	return sb.String(), err
+}
+
+func TestMarkdown(t *testing.T) {
+	result, err := transpileMarkdown(t, `
+#metadata {
+    #title { Example #italic{ Title } }
+    #date { 2022-08-15 15:00 }
+    #author { Alice }
+    #author { Bob }
+    #extra {
+        #a { 1 }
+        #b { #c{ x: y } }
+    }
+}
+
+#title { A short title }
+
+A paragraph with #bold{ bold }, #italic{ italic } and #strikethrough{ deleted } text
+and a #link{ link }{ https://example.org/a_(b) }.
+
+#subtitle { Code }
+
+Some #code{ inline } code.
+
+#code {{
+    #format {{ go }}
+    func main() {
+        fmt.Println("hello")
+    }
+}}
+`)
+	assert.Nil(t, err)
+	assert.Equal(t, "---\n"+
+		"title: Example Title\n"+
+		"date: 2022-08-15 15:00\n"+
+		"author:\n"+
+		"  - Alice\n"+
+		"  - Bob\n"+
+		"extra:\n"+
+		"  a: 1\n"+
+		"  b:\n"+
+		"    c: \"x: y\"\n"+
+		"---\n"+
+		"\n"+
+		"# A short title\n"+
+		"\n"+
+		"A paragraph with **bold**, *italic* and ~~deleted~~ text\n"+
+		"and a [link](https://example.org/a_(b)).\n"+
+		"\n"+
+		"## Code\n"+
+		"\n"+
+		"Some `inline` code.\n"+
+		"\n"+
+		"```go\n"+
+		"func main() {\n"+
+		"    fmt.Println(\"hello\")\n"+
+		"}\n"+
+		"```\n", result)
+}
+
+func TestMarkdownMetadataValues(t *testing.T) {
+	result, err := transpileMarkdown(t, `
+#metadata{
+    #title{
+        Example
+    }
+}
+
+Text
+`)
+	assert.Nil(t, err)
+	assert.Equal(t, "---\ntitle: Example\n---\n\nText\n", result)
+}
+
+func TestMarkdownEscape(t *testing.T) {
+	tests := []struct{ source, expected string }{
+		{"Some *stars*, _under_scores_ and [brackets]", `Some \*stars\*, \_under_scores\_ and \[brackets\]`},
+		{"snake_case & AT&amp;T <b>", `snake_case & AT\&amp;T \<b>`},
+		{"First line\n    # not a heading\n    - not a list\n    1. not ordered\n    > not a quote", "First line\n\\# not a heading\n\\- not a list\n1\\. not ordered\n\\> not a quote"},
+		{"Trailing spaces   \nare removed", "Trailing spaces\nare removed"},
+		{"#bold{ spaced out }!", "**spaced out**!"},
+		{"a#bold{ b }c", "a**b**c"},
+		{"#title{ C# }", `# C\#`},
+		{"#code{{ a `b` c }}", "``a `b` c``"},
+		{"#code{{ `tick` }}", "`` `tick` ``"},
+		{"#link{ text }{ a b }", "[text](<a b>)"},
+		{"#link{ text }{ a) }", "[text](<a)>)"},
+		{"#underline{ under }", "<u>under</u>"},
+		{"Some #html.span{ #class{ x } }{ html } and\n\n#html.div{ block }", "Some <span class=\"x\">html</span> and\n\n<div>block</div>"},
+		{"#metadata{ #draft{ no } #version{ 1.10 } #weight{ 2 } }", "---\ndraft: \"no\"\nversion: \"1.10\"\nweight: 2\n---"},
+	}
+
+	for _, test := range tests {
+		result, err := transpileMarkdown(t, test.source)
+		assert.Nil(t, err)
+		assert.Equal(t, test.expected+"\n", result, "source: %q", test.source)
+	}
+}
+
+func TestMarkdownFences(t *testing.T) {
+	result, err := transpileMarkdown(t, "#code{{{\n    ```\n    #code{{ x }}\n    ```\n}}}")
+	assert.Nil(t, err)
+	assert.Equal(t, "````\n```\n#code{{ x }}\n```\n````\n", result)
+}
+
+func TestMarkdownHTML(t *testing.T) {
+	result, err := transpileMarkdown(t, `
+#html.ul{
+    #html.li{ one #italic{ it } }
+    #html.li{ #link{ two }{ https://example.org } }
+}
+
+Some #html.span{ #bold{ bold } }.`)
+	assert.Nil(t, err)
+	assert.Equal(t, "<ul><li>one <i>it</i></li><li><a href=\"https://example.org\">two</a></li></ul>\n\nSome <span><b>bold</b></span>.\n", result)
+}
+
+func TestMarkdownErrors(t *testing.T) {
+	_, err := transpileMarkdown(t, "#unknown{ x }")
+	assert.ErrorContains(t, err, "element #unknown is not supported by the markdown format")
+
+	_, err = transpileMarkdown(t, "#bold{ #title{ x } }")
+	assert.ErrorContains(t, err, "heading #title can't be nested in inline formatting")
+
+	_, err = transpileMarkdown(t, "#link{ x }")
+	assert.ErrorContains(t, err, "invalid argument count for #link, expected 2 but got 1")
+
+	_, err = transpileMarkdown(t, "#bold{ a\n\n b }")
+	assert.EqualError(t, err, "1:1: #bold can't contain a paragraph break")
+
+	_, err = transpileMarkdown(t, "x #link{ #italic{ a } \n  \n b }{ https://example.org }")
+	assert.EqualError(t, err, "1:3: #link can't contain a paragraph break")
+
+	_, err = transpileMarkdown(t, "#html.div{ #unknown{ x } }")
+	assert.EqualError(t, err, "1:12: element #unknown is not supported by the html format")
+
+	_, err = transpileMarkdown(t, "#metadata{ text }")
+	assert.ErrorContains(t, err, "#metadata must contain only #KEY{ VALUE } entries")
+}
@@ -0,0 +1,407 @@
+package transpile
+
+import (
+	"fmt"
+	"io"
+	"regexp"
+	"strconv"
+	"strings"
+	"unicode"
+	"unicode/utf8"
+
+	"github.com/aziis98/textml/ast"
+)
+
+// Sexpr writes documents as S-expressions, an element becomes a list with its name followed by a list for each argument and text becomes a string. For example #link{ #bold{ TextML } }{ https://example.org } becomes (link ((bold ("TextML"))) ("https://example.org")). Strings use the escapes of R7RS Scheme and names that a reader could mistake for numbers are written as |NAME|.
+type Sexpr struct{ Inline bool }
+
+func newSexpr(opts Options, inline bool) (Transpiler, error) {
+	if err := opts.Check("inline"); err != nil {
+		return nil, err
+	}
+
+	inline, err := opts.Bool("inline", inline)
+	if err != nil {
+		return nil, err
+	}
+
+	return &Sexpr{Inline: inline}, nil
+}
+
+// Transpile writes the top level nodes of the document one per line, elements with other elements in their arguments have each argument on its own line. In inline mode the document is written on a single line.
+func (t *Sexpr) Transpile(w io.Writer, block ast.Block) error {
+	sb := &strings.Builder{}
+
+	for i, n := range block {
+		if i > 0 {
+			if t.Inline {
+				sb.WriteString(" ")
+			} else {
+				sb.WriteString("\n")
+			}
+		}
+
+		t.writeNode(sb, n, "")
+	}
+
+	if len(block) > 0 {
+		sb.WriteString("\n")
+	}
+
+	_, err := io.WriteString(w, sb.String())
+	return err
+}
+
+func (t *Sexpr) writeNode(sb *strings.Builder, n ast.Node, indent string) {
+	switch n := n.(type) {
+	case *ast.TextNode:
+		sb.WriteString(sexprString(n.Text))
+	case *ast.ElementNode:
+		sb.WriteString("(" + sexprSymbol(n.Name))
+
+		multiline := !t.Inline && hasElementArguments(n)
+		for _, arg := range n.Arguments {
+			if multiline {
+				sb.WriteString("\n" + indent + "  ")
+				t.writeBlock(sb, arg, indent+"  ")
+			} else {
+				sb.WriteString(" ")
+				t.writeBlock(sb, arg, indent)
+			}
+		}
+
+		sb.WriteString(")")
+	default:
+		panic("illegal state")
+	}
+}
+
+// writeBlock writes an argument as a list of nodes, nodes are aligned on their own lines when the argument contains elements.
+func (t *Sexpr) writeBlock(sb *strings.Builder, block ast.Block, indent string) {
+	multiline := !t.Inline && block.FirstElement() != nil
+
+	sb.WriteString("(")
+	for i, n := range block {
+		if i > 0 {
+			if multiline {
+				sb.WriteString("\n" + indent + " ")
+			} else {
+				sb.WriteString(" ")
+			}
+		}
+
+		t.writeNode(sb, n, indent+" ")
+	}
+	sb.WriteString(")")
+}
+
+func hasElementArguments(elem *ast.ElementNode) bool {
+	for _, arg := range elem.Arguments {
+		if arg.FirstElement() != nil {
+			return true
+		}
+	}
+
+	return false
+}
+
+// regexSexprNumber matches the names starting like a number, a reader would parse these as numbers instead of symbols
+var regexSexprNumber = regexp.MustCompile(`^[+-]?\.?[0-9]`)
+
+// sexprSymbol returns the name of an element as a symbol, the empty name and names that look like numbers or the dot of dotted pairs are written between bars.
+func sexprSymbol(name string) string {
+	if name == "" || regexSexprNumber.MatchString(name) || strings.Trim(name, ".") == "" {
+		return "|" + name + "|"
+	}
+
+	return name
+}
+
+// sexprString returns a string literal using the escapes of R7RS Scheme, control characters without a short escape are written as \xHH;
+func sexprString(s string) string {
+	sb := &strings.Builder{}
+	sb.WriteByte('"')
+
+	for _, r := range s {
+		switch r {
+		case '"':
+			sb.WriteString(`\"`)
+		case '\\':
+			sb.WriteString(`\\`)
+		case '\n':
+			sb.WriteString(`\n`)
+		case '\t':
+			sb.WriteString(`\t`)
+		case '\r':
+			sb.WriteString(`\r`)
+		default:
+			if unicode.IsControl(r) {
+				fmt.Fprintf(sb, `\x%X;`, r)
+			} else {
+				sb.WriteRune(r)
+			}
+		}
+	}
+
+	sb.WriteByte('"')
+	return sb.String()
+}
+
+//
+// Reader
+//
+
+// Read decodes a document written by [Sexpr.Transpile]. Line comments starting with ";" are ignored and nodes get the positions of their opening parenthesis or quote.
+func (t *Sexpr) Read(r io.Reader) (ast.Block, error) {
+	source, err := io.ReadAll(r)
+	if err != nil {
+		return nil, err
+	}
+
+	sr := &sexprReader{source: string(source), pos: ast.Position{Line: 1, Column: 1}}
+
+	block := ast.Block{}
+	for {
+		sr.skipSpace()
+		if sr.eof() {
+			return block, nil
+		}
+
+		n, err := sr.readNode()
+		if err != nil {
+			return nil, err
+		}
+
+		block = append(block, n)
+	}
+}
+
+type sexprReader struct {
+	source string
+	offset int
+	pos    ast.Position
+}
+
+func (sr *sexprReader) eof() bool {
+	return sr.offset >= len(sr.source)
+}
+
+// peek returns the next character or -1 at the end of the source.
+func (sr *sexprReader) peek() rune {
+	if sr.eof() {
+		return -1
+	}
+
+	r, _ := utf8.DecodeRuneInString(sr.source[sr.offset:])
+	return r
+}
+
+func (sr *sexprReader) next() rune {
+	r, size := utf8.DecodeRuneInString(sr.source[sr.offset:])
+	sr.offset += size
+
+	if r == '\n' {
+		sr.pos.Line++
+		sr.pos.Column = 1
+	} else {
+		sr.pos.Column++
+	}
+
+	return r
+}
+
+func (sr *sexprReader) skipSpace() {
+	for !sr.eof() {
+		switch r := sr.peek(); {
+		case r == ';':
+			for !sr.eof() && sr.peek() != '\n' {
+				sr.next()
+			}
+		case unicode.IsSpace(r):
+			sr.next()
+		default:
+			return
+		}
+	}
+}
+
+// unexpected returns an error for the next character or the end of the source.
+func (sr *sexprReader) unexpected(expected string) error {
+	if sr.eof() {
+		return fmt.Errorf("%v: unexpected end of input, expected %s", sr.pos, expected)
+	}
+
+	return fmt.Errorf("%v: unexpected character %q, expected %s", sr.pos, sr.peek(), expected)
+}
+
+// readNode reads a string as a text node or an element list.
+func (sr *sexprReader) readNode() (ast.Node, error) {
+	pos := sr.pos
+
+	switch sr.peek() {
+	case '"':
+		text, err := sr.readString()
+		if err != nil {
+			return nil, err
+		}
+
+		return &ast.TextNode{Text: text, Pos: pos}, nil
+	case '(':
+		sr.next()
+		sr.skipSpace()
+
+		name, err := sr.readSymbol()
+		if err != nil {
+			return nil, err
+		}
+
+		elem := &ast.ElementNode{Name: name, Arguments: []ast.Block{}, Pos: pos}
+		for {
+			sr.skipSpace()
+			if sr.peek() == ')' {
+				sr.next()
+				return elem, nil
+			}
+
+			arg, err := sr.readBlock()
+			if err != nil {
+				return nil, err
+			}
+
+			elem.Arguments = append(elem.Arguments, arg)
+		}
+	default:
+		return nil, sr.unexpected("a string or an element")
+	}
+}
+
+// readBlock reads the list of nodes of an argument.
+func (sr *sexprReader) readBlock() (ast.Block, error) {
+	if sr.peek() != '(' {
+		return nil, sr.unexpected("an argument list or \")\"")
+	}
+	sr.next()
+
+	block := ast.Block{}
+	for {
+		sr.skipSpace()
+		if sr.peek() == ')' {
+			sr.next()
+			return block, nil
+		}
+
+		n, err := sr.readNode()
+		if err != nil {
+			return nil, err
+		}
+
+		block = append(block, n)
+	}
+}
+
+// readSymbol reads the name of an element, bare or between bars.
+func (sr *sexprReader) readSymbol() (string, error) {
+	pos := sr.pos
+
+	name := ""
+	if sr.peek() == '|' {
+		sr.next()
+		for sr.peek() != '|' {
+			if sr.eof() {
+				return "", sr.unexpected(`"|"`)
+			}
+
+			name += string(sr.next())
+		}
+		sr.next()
+	} else {
+		for !sr.eof() && !unicode.IsSpace(sr.peek()) && !strings.ContainsRune(`()";|`, sr.peek()) {
+			name += string(sr.next())
+		}
+
+		if name == "" {
+			return "", sr.unexpected("an element name")
+		}
+	}
+
+	if !ast.IsValidName(name) {
+		return "", fmt.Errorf("%v: invalid element name %q", pos, name)
+	}
+
+	return name, nil
+}
+
+// sexprEscapes are the single character escapes of strings
+var sexprEscapes = map[rune]rune{
+	'"':  '"',
+	'\\': '\\',
+	'|':  '|',
+	'n':  '\n',
+	't':  '\t',
+	'r':  '\r',
+	'a':  '\a',
+	'b':  '\b',
+}
+
+// readString reads a string literal with the escapes of R7RS Scheme, including \xHH; and line continuations.
+func (sr *sexprReader) readString() (string, error) {
+	sr.next()
+
+	sb := &strings.Builder{}
+	for {
+		if sr.eof() {
+			return "", sr.unexpected(`"\""`)
+		}
+
+		pos := sr.pos
+
+		switch r := sr.next(); r {
+		case '"':
+			return sb.String(), nil
+		case '\\':
+			if sr.eof() {
+				return "", sr.unexpected("an escape sequence")
+			}
+
+			e := sr.next()
+			if unescaped, ok := sexprEscapes[e]; ok {
+				sb.WriteRune(unescaped)
+				continue
+			}
+
+			switch {
+			case e == 'x':
+				hex := ""
+				for !sr.eof() && sr.peek() != ';' && sr.peek() != '"' {
+					hex += string(sr.next())
+				}
+
+				code, err := strconv.ParseUint(hex, 16, 32)
+				if err != nil || sr.peek() != ';' || !utf8.ValidRune(rune(code)) {
+					return "", fmt.Errorf("%v: invalid escape sequence \\x%s", pos, hex)
+				}
+				sr.next()
+
+				sb.WriteRune(rune(code))
+			case e == ' ' || e == '\t' || e == '\n':
+				// \ followed by spaces, a newline and spaces is a line continuation
+				for e != '\n' && (sr.peek() == ' ' || sr.peek() == '\t') {
+					sr.next()
+				}
+				if e != '\n' {
+					if sr.peek() != '\n' {
+						return "", fmt.Errorf("%v: invalid escape sequence \\%c", pos, e)
+					}
+					sr.next()
+				}
+				for sr.peek() == ' ' || sr.peek() == '\t' {
+					sr.next()
+				}
+			default:
+				return "", fmt.Errorf("%v: invalid escape sequence \\%c", pos, e)
+			}
+		default:
+			sb.WriteRune(r)
+		}
+	}
+}
@@ -0,0 +1,97 @@
+package transpile_test
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/aziis98/textml"
+	"github.com/aziis98/textml/ast"
+	"github.com/aziis98/textml/runtime/transpile"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestSexpr(t *testing.T) {
+	doc, err := textml.ParseDocument(strings.NewReader(`#title{ Example }
+#link{ #bold{ TextML } and "more" }{ https://example.org } #{ x }{} #1st{ a\b }`))
+	assert.Nil(t, err)
+
+	sb := &strings.Builder{}
+	assert.Nil(t, (&transpile.Sexpr{}).Transpile(sb, doc))
+	assert.Equal(t, `(title ("Example"))
+"\n"
+(link
+  ((bold ("TextML"))
+   " and \"more\"")
+  ("https://example.org"))
+" "
+(|| ("x") ())
+" "
+(|1st| ("a\\b"))
+`, sb.String())
+
+	sb.Reset()
+	assert.Nil(t, (&transpile.Sexpr{Inline: true}).Transpile(sb, doc))
+	assert.Equal(t, `(title ("Example")) "\n" (link ((bold ("TextML")) " and \"more\"") ("https://example.org")) " " (|| ("x") ()) " " (|1st| ("a\\b"))`+"\n", sb.String())
+}
+
+func TestSexprRoundTrip(t *testing.T) {
+	doc, err := textml.ParseDocument(strings.NewReader(`
+#document {
+    #title{ Round trip }
+    #p{ Text with #italic{ nested #bold{ elements } }, tabs	and "quotes" }
+    #code{{
+        if (a) { b() }
+    }}
+    #.{ dots } #-1{ minus } #html.div{ #class{ x } }{}
+}
+`))
+	assert.Nil(t, err)
+
+	for _, inline := range []bool{false, true} {
+		sb := &strings.Builder{}
+		assert.Nil(t, (&transpile.Sexpr{Inline: inline}).Transpile(sb, doc))
+
+		result, err := (&transpile.Sexpr{}).Read(strings.NewReader(sb.String()))
+		assert.Nil(t, err)
+		assert.True(t, ast.Block(doc).Equal(result), sb.String())
+	}
+}
+
+func TestSexprRead(t *testing.T) {
+	result, err := (&transpile.Sexpr{}).Read(strings.NewReader(`
+; a comment
+(link ( (bold ("a" "b")) ) ("c\x41;\t\
+    d")) "e" ; another comment
+(|| ()) (br)
+`))
+	assert.Nil(t, err)
+	assert.True(t, ast.Block{
+		ast.EN("link", ast.B(ast.EN("bold", ast.B(ast.T("a"), ast.T("b")))), ast.B(ast.T("cA\td"))),
+		ast.T("e"),
+		ast.EN("", ast.B()),
+		ast.EN("br"),
+	}.Equal(result))
+
+	link := result[0].(*ast.ElementNode)
+	assert.Equal(t, ast.Position{Line: 3, Column: 1}, link.Pos)
+	assert.Equal(t, ast.Position{Line: 3, Column: 9}, link.Arguments[0][0].(*ast.ElementNode).Pos)
+}
+
+func TestSexprReadErrors(t *testing.T) {
+	for source, message := range map[string]string{
+		`(a ("x")`:       `1:9: unexpected end of input, expected an argument list or ")"`,
+		`(a "x")`:        `1:4: unexpected character '"', expected an argument list or ")"`,
+		`abc`:            `1:1: unexpected character 'a', expected a string or an element`,
+		`()`:             `1:2: unexpected character ')', expected an element name`,
+		`(a/b)`:          `1:2: invalid element name "a/b"`,
+		`(|a ())`:        `1:8: unexpected end of input, expected "|"`,
+		`("x")`:          `1:2: unexpected character '"', expected an element name`,
+		`"abc`:           `1:5: unexpected end of input, expected "\""`,
+		`"a\qb"`:         `1:3: invalid escape sequence \q`,
+		`"a\x4G;"`:       `1:3: invalid escape sequence \x4G`,
+		"(a (\"x\"))\n)": `2:1: unexpected character ')', expected a string or an element`,
+	} {
+		_, err := (&transpile.Sexpr{}).Read(strings.NewReader(source))
+		assert.EqualError(t, err, message, source)
+	}
+}
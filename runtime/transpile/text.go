@@ -0,0 +1,446 @@
+package transpile
+
+import (
+	"fmt"
+	"io"
+	"regexp"
+	"strconv"
+	"strings"
+
+	"github.com/aziis98/textml/ast"
+	"github.com/aziis98/textml/html"
+	"github.com/aziis98/textml/utils"
+)
+
+// Text converts documents using the vocabulary of runtime/document to plain text for emails or search indexing. Formatting is dropped, headings are underlined, links are written as "text (url)", code blocks are indented and paragraphs are wrapped. Lists and the other block elements come from the "html" namespace, like #html.ul{ #html.li{ ... } }, the other html elements only give their text.
+type Text struct {
+	// Width is the number of columns of wrapped lines, characters like CJK ideographs and emoji take two columns. Lines are not wrapped when zero.
+	Width int
+}
+
+func newText(opts Options) (Transpiler, error) {
+	if err := opts.Check("width"); err != nil {
+		return nil, err
+	}
+
+	width, err := opts.Int("width", 80)
+	if err != nil {
+		return nil, err
+	}
+	if width < 0 {
+		return nil, fmt.Errorf("invalid value %d for option %q, expected a positive width or 0 to disable wrapping", width, "width")
+	}
+
+	return &Text{Width: width}, nil
+}
+
+// textUnderlines are the characters underlining headings by level
+var textUnderlines = map[int]string{
+	1: "=",
+	2: "-",
+	3: "~",
+	4: ".",
+}
+
+// regexBlankLine matches the blank lines separating paragraphs
+var regexBlankLine = regexp.MustCompile(`\n[ \t]*\n`)
+
+// Transpile writes the document as plain text, the #metadata elements are dropped.
+func (t *Text) Transpile(w io.Writer, block ast.Block) error {
+	block, err := ast.ResolveNamespaces(block)
+	if err != nil {
+		return err
+	}
+
+	block = ast.Normalize(block, ast.MergeText)
+
+	_, content, err := splitMetadata(block)
+	if err != nil {
+		return err
+	}
+
+	r := &textRenderer{width: t.Width}
+	if err := r.render(content); err != nil {
+		return err
+	}
+
+	lines := r.lines()
+	if len(lines) == 0 {
+		return nil
+	}
+
+	_, err = io.WriteString(w, strings.Join(lines, "\n")+"\n")
+	return err
+}
+
+// textRenderer collects the paragraphs and the other blocks of a document as sections of lines, blocks nested in lists and quotes are rendered by nested renderers with a smaller width.
+type textRenderer struct {
+	width int
+
+	sections  [][]string
+	paragraph strings.Builder
+}
+
+// nested returns a renderer for content indented by the given number of columns.
+func (r *textRenderer) nested(indent int) *textRenderer {
+	if r.width == 0 {
+		return &textRenderer{}
+	}
+
+	return &textRenderer{width: utils.Max(r.width-indent, 1)}
+}
+
+// flush ends the current paragraph.
+func (r *textRenderer) flush() {
+	if lines := wrapText(r.paragraph.String(), r.width); len(lines) > 0 {
+		r.sections = append(r.sections, lines)
+	}
+
+	r.paragraph.Reset()
+}
+
+// lines returns the lines of the rendered sections separated by blank lines.
+func (r *textRenderer) lines() []string {
+	r.flush()
+
+	lines := []string{}
+	for i, section := range r.sections {
+		if i > 0 {
+			lines = append(lines, "")
+		}
+
+		lines = append(lines, section...)
+	}
+
+	return lines
+}
+
+// inline returns the text of a block on a single line.
+func (r *textRenderer) inline(block ast.Block) (string, error) {
+	nested := &textRenderer{}
+	if err := nested.render(block); err != nil {
+		return "", err
+	}
+
+	return strings.Join(nested.lines(), " "), nil
+}
+
+func (r *textRenderer) render(block ast.Block) error {
+	for _, n := range block {
+		switch n := n.(type) {
+		case *ast.TextNode:
+			for i, part := range regexBlankLine.Split(n.Text, -1) {
+				if i > 0 {
+					r.flush()
+				}
+
+				// single newlines are soft breaks, "\n" in the paragraph marks hard line breaks
+				r.paragraph.WriteString(strings.ReplaceAll(part, "\n", " "))
+			}
+		case *ast.ElementNode:
+			if err := r.renderElement(n); err != nil {
+				return err
+			}
+		default:
+			panic("illegal state")
+		}
+	}
+
+	return nil
+}
+
+func (r *textRenderer) renderElement(elem *ast.ElementNode) error {
+	if elem.Namespace() == "html" {
+		return r.renderHTML(elem)
+	}
+
+	if level, ok := documentHeadings[elem.Name]; ok {
+		if err := checkArity(elem, 1); err != nil {
+			return err
+		}
+
+		return r.renderHeading(level, elem.Arguments[0])
+	}
+
+	switch elem.Name {
+	case "bold", "italic", "underline", "strikethrough":
+		if err := checkArity(elem, 1); err != nil {
+			return err
+		}
+
+		return r.render(elem.Arguments[0])
+
+	case "code":
+		if err := checkArity(elem, 1); err != nil {
+			return err
+		}
+
+		language, code := codeContent(elem.Arguments[0])
+		if !isCodeBlock(language, code) {
+			r.paragraph.WriteString(strings.TrimSpace(code))
+			return nil
+		}
+
+		r.renderCodeBlock(code)
+		return nil
+
+	case "link":
+		if err := checkArity(elem, 2); err != nil {
+			return err
+		}
+
+		if err := r.render(elem.Arguments[0]); err != nil {
+			return err
+		}
+
+		url := elem.Arguments[1].Text(linkTargetText)
+		if url != "" && url != elem.Arguments[0].Text(linkTargetText) {
+			r.paragraph.WriteString(" (" + url + ")")
+		}
+
+		return nil
+	}
+
+	// other elements only give the text of their arguments
+	for i, arg := range elem.Arguments {
+		if i > 0 {
+			r.paragraph.WriteString(" ")
+		}
+		if err := r.render(arg); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// renderHeading writes the heading wrapped and underlined by the longest of its lines.
+func (r *textRenderer) renderHeading(level int, block ast.Block) error {
+	text, err := r.inline(block)
+	if err != nil {
+		return err
+	}
+
+	r.flush()
+
+	lines := wrapText(text, r.width)
+	if len(lines) == 0 {
+		return nil
+	}
+
+	width := 0
+	for _, line := range lines {
+		width = utils.Max(width, stringWidth(line))
+	}
+
+	r.sections = append(r.sections, append(lines, strings.Repeat(textUnderlines[level], width)))
+	return nil
+}
+
+// renderCodeBlock writes the lines of the code indented by four spaces, these are never wrapped.
+func (r *textRenderer) renderCodeBlock(code string) {
+	r.flush()
+
+	lines := strings.Split(dedentCode(code), "\n")
+	for i, line := range lines {
+		if line != "" {
+			lines[i] = "    " + line
+		}
+	}
+
+	if len(lines) > 1 || lines[0] != "" {
+		r.sections = append(r.sections, lines)
+	}
+}
+
+// renderHTML writes the text of an element of the "html" namespace, lists, quotes, headings and preformatted text are laid out like in a browser and block elements start new paragraphs.
+func (r *textRenderer) renderHTML(elem *ast.ElementNode) error {
+	attributes, children := ast.Block{}, ast.Block{}
+	if len(elem.Arguments) == 2 {
+		attributes = elem.Arguments[0]
+	}
+	if len(elem.Arguments) > 0 {
+		children = elem.Arguments[len(elem.Arguments)-1]
+	}
+
+	tag := elem.LocalName()
+
+	switch tag {
+	case "head", "script", "style":
+		return nil
+
+	case "br":
+		r.paragraph.WriteString("\n")
+		return nil
+
+	case "hr":
+		r.flush()
+
+		width := r.width
+		if width == 0 {
+			width = 80
+		}
+		r.sections = append(r.sections, []string{strings.Repeat("-", width)})
+		return nil
+
+	case "img":
+		r.paragraph.WriteString(htmlAttribute(attributes, "alt"))
+		return nil
+
+	case "h1", "h2", "h3", "h4", "h5", "h6":
+		return r.renderHeading(utils.Min(int(tag[1]-'0'), 4), children)
+
+	case "pre":
+		r.renderCodeBlock(children.Text(ast.TextOptions{Recursive: true}))
+		return nil
+
+	case "ul", "ol":
+		return r.renderList(tag == "ol", attributes, children)
+
+	case "blockquote":
+		r.flush()
+
+		nested := r.nested(2)
+		if err := nested.render(children); err != nil {
+			return err
+		}
+
+		lines := nested.lines()
+		for i, line := range lines {
+			lines[i] = strings.TrimRight("> "+line, " ")
+		}
+		if len(lines) > 0 {
+			r.sections = append(r.sections, lines)
+		}
+
+		return nil
+	}
+
+	if !html.IsBlockElement(tag) {
+		return r.render(children)
+	}
+
+	r.flush()
+	if err := r.render(children); err != nil {
+		return err
+	}
+	r.flush()
+
+	return nil
+}
+
+// renderList writes the items of a list with a bullet or their number, the lines of the items are indented after the marker. Items made of many paragraphs are separated by blank lines.
+func (r *textRenderer) renderList(ordered bool, attributes, children ast.Block) error {
+	r.flush()
+
+	start := 1
+	if n, err := strconv.Atoi(htmlAttribute(attributes, "start")); err == nil {
+		start = n
+	}
+
+	items := listItems(children)
+
+	markers := []string{}
+	indent := 2
+	for i := range items {
+		marker := "- "
+		if ordered {
+			marker = strconv.Itoa(start+i) + ". "
+		}
+
+		markers = append(markers, marker)
+		indent = utils.Max(indent, len(marker))
+	}
+
+	rendered := [][]string{}
+	loose := false
+	for _, item := range items {
+		nested := r.nested(indent)
+		if err := nested.render(item); err != nil {
+			return err
+		}
+
+		nested.flush()
+		loose = loose || len(nested.sections) > 1
+		rendered = append(rendered, nested.lines())
+	}
+
+	lines := []string{}
+	for i, itemLines := range rendered {
+		if loose && i > 0 {
+			lines = append(lines, "")
+		}
+		if len(itemLines) == 0 {
+			lines = append(lines, strings.TrimSpace(markers[i]))
+			continue
+		}
+
+		for k, line := range itemLines {
+			switch {
+			case k == 0:
+				line = markers[i] + strings.Repeat(" ", indent-len(markers[i])) + line
+			case line != "":
+				line = strings.Repeat(" ", indent) + line
+			}
+
+			lines = append(lines, line)
+		}
+	}
+
+	if len(lines) > 0 {
+		r.sections = append(r.sections, lines)
+	}
+
+	return nil
+}
+
+// htmlAttribute returns the text of an attribute written as a #NAME{ VALUE } entry.
+func htmlAttribute(attributes ast.Block, name string) string {
+	for _, n := range attributes {
+		if elem, ok := n.(*ast.ElementNode); ok && elem.Name == name && len(elem.Arguments) > 0 {
+			return elem.Arguments[0].Text(ast.ValueText)
+		}
+	}
+
+	return ""
+}
+
+// isTextSpace reports whether the character separates words, unlike [unicode.IsSpace] non breaking spaces are kept in the words
+func isTextSpace(r rune) bool {
+	return r == ' ' || r == '\t' || r == '\r' || r == '\f' || r == '\v'
+}
+
+// wrapText splits the text in lines at most width columns wide, breaking lines between words. Words longer than the width are put on their own line, newlines in the text are hard line breaks and a zero width disables wrapping.
+func wrapText(text string, width int) []string {
+	lines := []string{}
+
+	for _, hardLine := range strings.Split(text, "\n") {
+		line, lineWidth := "", 0
+
+		for _, word := range strings.FieldsFunc(hardLine, isTextSpace) {
+			wordWidth := stringWidth(word)
+
+			switch {
+			case line == "":
+				line, lineWidth = word, wordWidth
+			case width > 0 && lineWidth+1+wordWidth > width:
+				lines = append(lines, line)
+				line, lineWidth = word, wordWidth
+			default:
+				line, lineWidth = line+" "+word, lineWidth+1+wordWidth
+			}
+		}
+
+		lines = append(lines, line)
+	}
+
+	// blank lines around the text come from spaces and line breaks at the ends of the paragraph
+	for len(lines) > 0 && lines[0] == "" {
+		lines = lines[1:]
+	}
+	for len(lines) > 0 && lines[len(lines)-1] == "" {
+		lines = lines[:len(lines)-1]
+	}
+
+	return lines
+}
@@ -0,0 +1,140 @@
+package transpile_test
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/aziis98/textml"
+	"github.com/aziis98/textml/runtime/transpile"
+	"github.com/stretchr/testify/assert"
+)
+
+func transpileText(t *testing.T, source string, opts transpile.Options) (string, error) {
+	t.Helper()
+
+	doc, err := textml.ParseDocument(strings.NewReader(source))
+	assert.Nil(t, err)
+
+	tr, err := transpile.New("text", opts)
+	assert.Nil(t, err)
+
+	sb := &strings.Builder{}
+	err = tr.Transpile(sb, doc)
+	return sb.String(), err
+}
+
+func TestText(t *testing.T) {
+	result, err := transpileText(t, `
+#metadata { #title { Ignored } }
+
+#title { A short title }
+
+Some #bold{ bold } and #italic{ italic } text with a #link{ link }{ https://example.org },
+#link{ https://example.org }{ https://example.org } and #code{ x := 1 }.
+
+#subtitle { Code }
+
+#code {{
+    func main() {
+        fmt.Println("Hello")
+    }
+}}
+
+#subsubtitle { Lists }
+
+#html.ul {
+    #html.li { First item }
+    #html.li { Second #italic{ item } }
+}
+
+#html.ol { #start{ 9 } }{
+    #html.li { Nine }
+    #html.li { Ten }
+}
+
+#html.blockquote { A quote#html.br{} Second line }
+`, nil)
+	assert.Nil(t, err)
+	assert.Equal(t, `A short title
+=============
+
+Some bold and italic text with a link (https://example.org), https://example.org
+and x := 1.
+
+Code
+----
+
+    func main() {
+        fmt.Println("Hello")
+    }
+
+Lists
+~~~~~
+
+- First item
+- Second item
+
+9.  Nine
+10. Ten
+
+> A quote
+> Second line
+`, result)
+}
+
+func TestTextWrap(t *testing.T) {
+	source := `
+#subtitle { A heading long enough to be wrapped }
+
+The quick brown fox jumps over the lazy dog, a sentence using every letter.
+
+#html.ul {
+    #html.li { An item long enough to be wrapped on the next line }
+}
+`
+
+	result, err := transpileText(t, source, transpile.Options{"width": "20"})
+	assert.Nil(t, err)
+	assert.Equal(t, `A heading long
+enough to be wrapped
+--------------------
+
+The quick brown fox
+jumps over the lazy
+dog, a sentence
+using every letter.
+
+- An item long
+  enough to be
+  wrapped on the
+  next line
+`, result)
+
+	result, err = transpileText(t, source, transpile.Options{"width": "0"})
+	assert.Nil(t, err)
+	assert.Equal(t, `A heading long enough to be wrapped
+-----------------------------------
+
+The quick brown fox jumps over the lazy dog, a sentence using every letter.
+
+- An item long enough to be wrapped on the next line
+`, result)
+}
+
+func TestTextWidth(t *testing.T) {
+	result, err := transpileText(t, `#title{ 日本語 } #title{ café } #title{ 👍 ok }`, nil)
+	assert.Nil(t, err)
+	assert.Equal(t, "日本語\n======\n\ncafé\n====\n\n👍 ok\n=====\n", result)
+
+	result, err = transpileText(t, `漢字 漢字 漢字 ab`, transpile.Options{"width": "9"})
+	assert.Nil(t, err)
+	assert.Equal(t, "漢字 漢字\n漢字 ab\n", result)
+}
+
+func TestTextErrors(t *testing.T) {
+	_, err := transpile.New("text", transpile.Options{"width": "-1"})
+	assert.EqualError(t, err, `format "text": invalid value -1 for option "width", expected a positive width or 0 to disable wrapping`)
+
+	_, err = transpileText(t, `#link{ a }`, nil)
+	assert.EqualError(t, err, "1:1: invalid argument count for #link, expected 2 but got 1")
+}
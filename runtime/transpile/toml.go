@@ -0,0 +1,233 @@
+package transpile
+
+import (
+	"fmt"
+	"io"
+	"regexp"
+	"strings"
+	"time"
+
+	"github.com/aziis98/textml/ast"
+)
+
+// Toml converts dictionary shaped documents to TOML like [Yaml]. Values that are dictionaries become tables, repeated dictionaries become arrays of tables and other repeated entries become arrays. Booleans, numbers and dates are written as such when their text would be read back unchanged, like true, 42 or 1.5 but not 1.10 or 0x1F, the other values as strings.
+type Toml struct{}
+
+func newToml(opts Options) (Transpiler, error) {
+	if err := opts.Check(); err != nil {
+		return nil, err
+	}
+
+	return &Toml{}, nil
+}
+
+// Transpile writes the document as a TOML file, documents with text outside of the entries are rejected.
+func (t *Toml) Transpile(w io.Writer, block ast.Block) error {
+	block, err := ast.ResolveNamespaces(block)
+	if err != nil {
+		return err
+	}
+
+	block = ast.Normalize(block, ast.MergeText)
+
+	if err := checkDictionary(block, "toml"); err != nil {
+		return err
+	}
+
+	tw := &tomlWriter{w: w}
+	tw.writeTable(block, nil)
+
+	return tw.err
+}
+
+// tomlWriter keeps the first write error, like [bufio.Writer]
+type tomlWriter struct {
+	w       io.Writer
+	err     error
+	written bool
+}
+
+func (tw *tomlWriter) printf(format string, args ...any) {
+	if tw.err != nil {
+		return
+	}
+
+	_, tw.err = fmt.Fprintf(tw.w, format, args...)
+	tw.written = true
+}
+
+// header writes a table header separated by a blank line from the previous content.
+func (tw *tomlWriter) header(open string, path []string, close string) {
+	if tw.written {
+		tw.printf("\n")
+	}
+
+	tw.printf("%s%s%s\n", open, tomlPath(path), close)
+}
+
+// writeTable writes the key and value pairs of a table followed by its sub tables, as TOML requires.
+func (tw *tomlWriter) writeTable(block ast.Block, path []string) {
+	tables := []*dictEntry{}
+
+	for _, entry := range dictEntries(block) {
+		if isTOMLTable(entry) {
+			tables = append(tables, entry)
+			continue
+		}
+
+		tw.printf("%s = %s\n", tomlKey(entry.Name), tomlEntryValue(entry))
+	}
+
+	for _, entry := range tables {
+		entryPath := append(path[:len(path):len(path)], entry.Name)
+
+		if len(entry.Values) == 1 {
+			// tables without values are implicitly defined by their sub tables
+			if hasTOMLValues(entry.Values[0]) {
+				tw.header("[", entryPath, "]")
+			}
+
+			tw.writeTable(entry.Values[0], entryPath)
+			continue
+		}
+
+		for _, value := range entry.Values {
+			tw.header("[[", entryPath, "]]")
+			tw.writeTable(value, entryPath)
+		}
+	}
+}
+
+// isTOMLTable reports whether all the values of the entry are dictionaries, these are written as tables or arrays of tables.
+func isTOMLTable(entry *dictEntry) bool {
+	for _, value := range entry.Values {
+		if !value.IsDictionary() {
+			return false
+		}
+	}
+
+	return true
+}
+
+// hasTOMLValues reports whether the dictionary has entries written as key and value pairs.
+func hasTOMLValues(block ast.Block) bool {
+	for _, entry := range dictEntries(block) {
+		if !isTOMLTable(entry) {
+			return true
+		}
+	}
+
+	return false
+}
+
+// tomlEntryValue returns the value of an entry, repeated entries become arrays.
+func tomlEntryValue(entry *dictEntry) string {
+	if len(entry.Values) == 1 {
+		return tomlValue(entry.Values[0])
+	}
+
+	values := []string{}
+	for _, value := range entry.Values {
+		values = append(values, tomlValue(value))
+	}
+
+	return "[" + strings.Join(values, ", ") + "]"
+}
+
+// tomlValue returns a scalar or an inline table for dictionaries.
+func tomlValue(value ast.Block) string {
+	if !value.IsDictionary() {
+		return tomlScalar(scalarValue(value))
+	}
+
+	pairs := []string{}
+	for _, entry := range dictEntries(value) {
+		pairs = append(pairs, tomlKey(entry.Name)+" = "+tomlEntryValue(entry))
+	}
+
+	return "{ " + strings.Join(pairs, ", ") + " }"
+}
+
+var (
+	regexTOMLDate    = regexp.MustCompile(`^([0-9]{4}-[0-9]{2}-[0-9]{2}([T ][0-9]{2}:[0-9]{2}:[0-9]{2}(\.[0-9]+)?(Z|[+-][0-9]{2}:[0-9]{2})?)?|[0-9]{2}:[0-9]{2}:[0-9]{2}(\.[0-9]+)?)$`)
+	regexTOMLBareKey = regexp.MustCompile(`^[A-Za-z0-9_-]+$`)
+)
+
+// tomlDateLayouts are the layouts of the TOML date and time values, offset date-times, local date-times, local dates and local times. These check the ranges of values matched by regexTOMLDate.
+var tomlDateLayouts = []string{
+	time.RFC3339Nano,
+	"2006-01-02 15:04:05.999999999Z07:00",
+	"2006-01-02T15:04:05.999999999",
+	"2006-01-02 15:04:05.999999999",
+	"2006-01-02",
+	"15:04:05.999999999",
+}
+
+// tomlScalar returns the value as a TOML boolean or number following [isTypedValue], as a date when it is a valid one, otherwise as a string.
+func tomlScalar(s string) string {
+	if isTypedValue(s) {
+		return s
+	}
+
+	if regexTOMLDate.MatchString(s) {
+		for _, layout := range tomlDateLayouts {
+			if _, err := time.Parse(layout, s); err == nil {
+				return s
+			}
+		}
+	}
+
+	return tomlString(s)
+}
+
+// tomlString returns a TOML basic string.
+func tomlString(s string) string {
+	sb := &strings.Builder{}
+	sb.WriteByte('"')
+
+	for _, r := range s {
+		switch r {
+		case '"':
+			sb.WriteString(`\"`)
+		case '\\':
+			sb.WriteString(`\\`)
+		case '\b':
+			sb.WriteString(`\b`)
+		case '\t':
+			sb.WriteString(`\t`)
+		case '\n':
+			sb.WriteString(`\n`)
+		case '\f':
+			sb.WriteString(`\f`)
+		case '\r':
+			sb.WriteString(`\r`)
+		default:
+			if r < 0x20 || r == 0x7f {
+				fmt.Fprintf(sb, `\u%04X`, r)
+			} else {
+				sb.WriteRune(r)
+			}
+		}
+	}
+
+	sb.WriteByte('"')
+	return sb.String()
+}
+
+// tomlKey returns a bare key when possible, otherwise a quoted one. Element names with dots are quoted as dots separate the parts of dotted keys.
+func tomlKey(key string) string {
+	if regexTOMLBareKey.MatchString(key) {
+		return key
+	}
+
+	return tomlString(key)
+}
+
+func tomlPath(path []string) string {
+	keys := []string{}
+	for _, key := range path {
+		keys = append(keys, tomlKey(key))
+	}
+
+	return strings.Join(keys, ".")
+}
@@ -0,0 +1,82 @@
+package transpile_test
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestToml(t *testing.T) {
+	result, err := transpileData(t, "toml", config)
+	assert.Nil(t, err)
+	assert.Equal(t, `name = "textml"
+version = 1.0
+released = 2022-08-15
+tags = ["a", "b: c"]
+title = "An \"example\""
+
+[server]
+host = "localhost"
+
+[server.tls]
+cert = "a.pem"
+
+[[user]]
+name = "alice"
+role = "admin"
+
+[[user]]
+name = "bob"
+`, result)
+}
+
+func TestTomlValues(t *testing.T) {
+	result, err := transpileData(t, "toml", `
+#int{ -42 } #float{ 0.25 } #exponent{ 6.02e23 } #trailing-zero{ 1.10 } #big{ 99999999999999999999 } #bool{ false } #leading-zero{ 0123 } #hex{ 0x1F }
+#datetime{ 1979-05-27T07:32:00Z } #local{ 1979-05-27 07:32:00 } #time{ 07:32:00 } #invalid-date{ 2022-13-01 }
+#html.title{ x } #mixed{ 1 } #mixed{ #a{ b } }
+#only{ #sub{ #x{ 1 } } }`)
+	assert.Nil(t, err)
+	assert.Equal(t, `int = -42
+float = 0.25
+exponent = "6.02e23"
+trailing-zero = "1.10"
+big = "99999999999999999999"
+bool = false
+leading-zero = "0123"
+hex = "0x1F"
+datetime = 1979-05-27T07:32:00Z
+local = 1979-05-27 07:32:00
+time = 07:32:00
+invalid-date = "2022-13-01"
+"html.title" = "x"
+mixed = [1, { a = "b" }]
+
+[only.sub]
+x = 1
+`, result)
+}
+
+func TestTomlMultilineValues(t *testing.T) {
+	result, err := transpileData(t, "toml", `
+#title{
+    Example
+}
+#tags{
+    a
+}
+#tags{ b }
+`)
+	assert.Nil(t, err)
+	assert.Equal(t, "title = \"Example\"\ntags = [\"a\", \"b\"]\n", result)
+}
+
+func TestTomlErrors(t *testing.T) {
+	// values with text are not dictionaries, they are read as text like #metadata values
+	result, err := transpileData(t, "toml", "#a{ #b{ 1 } c }")
+	assert.Nil(t, err)
+	assert.Equal(t, "a = \"1 c\"\n", result)
+
+	_, err = transpileData(t, "toml", "text")
+	assert.ErrorContains(t, err, `unexpected text "text", the toml format expects a dictionary of #KEY{ VALUE } entries`)
+}
@@ -1,26 +1,253 @@
+// Package transpile converts TextML documents to other formats. Formats are registered by name with [Register], so other packages can add their own, and are created with [New].
 package transpile
 
 import (
+	"fmt"
 	"io"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
 
 	"github.com/aziis98/textml/ast"
 )
 
-type StringTranspiler interface {
-	Transpile(block ast.Block) (string, error)
+// Transpiler converts a document to another format.
+type Transpiler interface {
+	Transpile(w io.Writer, block ast.Block) error
 }
 
-type WriteTranspiler interface {
-	Transpile(w io.Writer, block ast.Block) error
+// Options configure a transpiler, these are format specific "key=value" settings like "inline=true" given with -O on the command line.
+type Options map[string]string
+
+// String returns the option or the default value if it is not set.
+func (o Options) String(key, defaultValue string) string {
+	if value, ok := o[key]; ok {
+		return value
+	}
+
+	return defaultValue
+}
+
+// Bool returns the option parsed as a boolean or the default value if it is not set.
+func (o Options) Bool(key string, defaultValue bool) (bool, error) {
+	value, ok := o[key]
+	if !ok {
+		return defaultValue, nil
+	}
+
+	b, err := strconv.ParseBool(value)
+	if err != nil {
+		return false, fmt.Errorf("invalid value %q for option %q, expected a boolean", value, key)
+	}
+
+	return b, nil
+}
+
+// Int returns the option parsed as an integer or the default value if it is not set.
+func (o Options) Int(key string, defaultValue int) (int, error) {
+	value, ok := o[key]
+	if !ok {
+		return defaultValue, nil
+	}
+
+	n, err := strconv.Atoi(value)
+	if err != nil {
+		return 0, fmt.Errorf("invalid value %q for option %q, expected an integer", value, key)
+	}
+
+	return n, nil
+}
+
+// Check returns an error if some option is not among the allowed ones.
+func (o Options) Check(allowed ...string) error {
+	keys := []string{}
+	for key := range o {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+
+	for _, key := range keys {
+		if !contains(allowed, key) {
+			if len(allowed) == 0 {
+				return fmt.Errorf("unknown option %q, this format has no options", key)
+			}
+
+			return fmt.Errorf("unknown option %q, available options are %s", key, strings.Join(allowed, ", "))
+		}
+	}
+
+	return nil
+}
+
+func contains(list []string, s string) bool {
+	for _, item := range list {
+		if item == s {
+			return true
+		}
+	}
+
+	return false
+}
+
+// Factory describes a format and creates its transpilers.
+type Factory struct {
+	// Description is a short summary shown by "textml transpile --list-formats"
+	Description string
+
+	// New returns a transpiler configured by the options
+	New func(opts Options) (Transpiler, error)
 }
 
-var Registry = map[string]any{
-	// Go Repr
-	"repr": &Repr{},
-	// HTML
-	"html":        &Html{Inline: false},
-	"html.inline": &Html{Inline: true},
-	// Json
-	"json":        &Json{Inline: false},
-	"json.inline": &Json{Inline: true},
+var (
+	registryMu sync.RWMutex
+	registry   = map[string]Factory{}
+)
+
+// Register makes a format available by name, it panics if the name is already registered or the factory has no New function. This is usually called from the init function of the package implementing the format.
+func Register(name string, factory Factory) {
+	registryMu.Lock()
+	defer registryMu.Unlock()
+
+	if factory.New == nil {
+		panic(fmt.Sprintf("transpile: factory of format %q has no New function", name))
+	}
+	if _, ok := registry[name]; ok {
+		panic(fmt.Sprintf("transpile: format %q registered twice", name))
+	}
+
+	registry[name] = factory
+}
+
+// New returns a transpiler for the registered format.
+func New(name string, opts Options) (Transpiler, error) {
+	registryMu.RLock()
+	factory, ok := registry[name]
+	registryMu.RUnlock()
+
+	if !ok {
+		return nil, fmt.Errorf("unknown format %q, available formats are %s", name, strings.Join(FormatNames(), ", "))
+	}
+
+	t, err := factory.New(opts)
+	if err != nil {
+		return nil, fmt.Errorf("format %q: %w", name, err)
+	}
+
+	return t, nil
+}
+
+// Format is a registered format, see [Formats].
+type Format struct {
+	Name        string
+	Description string
+}
+
+// Formats returns the registered formats sorted by name.
+func Formats() []Format {
+	registryMu.RLock()
+	defer registryMu.RUnlock()
+
+	formats := []Format{}
+	for name, factory := range registry {
+		formats = append(formats, Format{name, factory.Description})
+	}
+	sort.Slice(formats, func(i, j int) bool {
+		return formats[i].Name < formats[j].Name
+	})
+
+	return formats
+}
+
+// FormatNames returns the names of the registered formats in order.
+func FormatNames() []string {
+	names := []string{}
+	for _, format := range Formats() {
+		names = append(names, format.Name)
+	}
+
+	return names
+}
+
+func init() {
+	Register("tml", Factory{
+		Description: "TextML source, useful to convert documents read with --from json",
+		New: func(opts Options) (Transpiler, error) {
+			if err := opts.Check(); err != nil {
+				return nil, err
+			}
+
+			return &Tml{}, nil
+		},
+	})
+	Register("repr", Factory{
+		Description: "Go representation of the parsed tree, useful for debugging",
+		New: func(opts Options) (Transpiler, error) {
+			if err := opts.Check(); err != nil {
+				return nil, err
+			}
+
+			return &Repr{}, nil
+		},
+	})
+	Register("json", Factory{
+		Description: "JSON representation of the parsed tree (options: inline)",
+		New: func(opts Options) (Transpiler, error) {
+			return newJson(opts, false)
+		},
+	})
+	Register("json.inline", Factory{
+		Description: "Like json but on a single line",
+		New: func(opts Options) (Transpiler, error) {
+			return newJson(opts, true)
+		},
+	})
+	Register("sexpr", Factory{
+		Description: "S-expressions with elements as (NAME (ARG...)...) lists and text as strings (options: inline)",
+		New: func(opts Options) (Transpiler, error) {
+			return newSexpr(opts, false)
+		},
+	})
+	Register("sexpr.inline", Factory{
+		Description: "Like sexpr but on a single line",
+		New: func(opts Options) (Transpiler, error) {
+			return newSexpr(opts, true)
+		},
+	})
+	Register("html", Factory{
+		Description: "HTML page from #html.TAG{ ... } and runtime/document elements (options: inline)",
+		New: func(opts Options) (Transpiler, error) {
+			return newHtml(opts, false)
+		},
+	})
+	Register("html.inline", Factory{
+		Description: "Like html but on a single line",
+		New: func(opts Options) (Transpiler, error) {
+			return newHtml(opts, true)
+		},
+	})
+	Register("markdown", Factory{
+		Description: "CommonMark/GFM from the runtime/document elements, #metadata becomes a YAML front matter",
+		New:         newMarkdown,
+	})
+	Register("latex", Factory{
+		Description: "LaTeX from the runtime/document elements (options: class, class-options, template, code)",
+		New:         newLatex,
+	})
+	Register("xml", Factory{
+		Description: "XML with elements as XML elements and arguments as <arg> children (options: root, arg, compact)",
+		New:         newXml,
+	})
+	Register("yaml", Factory{
+		Description: "YAML data from documents made of #KEY{ VALUE } entries",
+		New:         newYaml,
+	})
+	Register("toml", Factory{
+		Description: "TOML data from documents made of #KEY{ VALUE } entries",
+		New:         newToml,
+	})
+	Register("text", Factory{
+		Description: "Plain text from the runtime/document elements with wrapped paragraphs (options: width)",
+		New:         newText,
+	})
 }
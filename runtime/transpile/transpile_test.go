@@ -0,0 +1,62 @@
+package transpile_test
+
+import (
+	"io"
+	"strings"
+	"testing"
+
+	"github.com/aziis98/textml/ast"
+	"github.com/aziis98/textml/runtime/transpile"
+	"github.com/stretchr/testify/assert"
+)
+
+type upper struct{}
+
+func (upper) Transpile(w io.Writer, block ast.Block) error {
+	_, err := io.WriteString(w, strings.ToUpper(block.Text(ast.TextOptions{Recursive: true})))
+	return err
+}
+
+func init() {
+	transpile.Register("test.upper", transpile.Factory{
+		Description: "Upper case text",
+		New: func(opts transpile.Options) (transpile.Transpiler, error) {
+			return upper{}, opts.Check()
+		},
+	})
+}
+
+func TestRegister(t *testing.T) {
+	assert.Contains(t, transpile.Formats(), transpile.Format{Name: "test.upper", Description: "Upper case text"})
+
+	tr, err := transpile.New("test.upper", nil)
+	assert.Nil(t, err)
+
+	sb := &strings.Builder{}
+	assert.Nil(t, tr.Transpile(sb, ast.Block{ast.T("hello "), ast.EN("b", ast.B(ast.T("world")))}))
+	assert.Equal(t, "HELLO WORLD", sb.String())
+
+	assert.Panics(t, func() {
+		transpile.Register("test.upper", transpile.Factory{New: func(transpile.Options) (transpile.Transpiler, error) { return upper{}, nil }})
+	})
+}
+
+func TestNewErrors(t *testing.T) {
+	_, err := transpile.New("missing", nil)
+	assert.ErrorContains(t, err, `unknown format "missing", available formats are html, html.inline, json`)
+
+	_, err = transpile.New("html", transpile.Options{"indent": "2"})
+	assert.EqualError(t, err, `format "html": unknown option "indent", available options are inline`)
+
+	_, err = transpile.New("json", transpile.Options{"inline": "maybe"})
+	assert.EqualError(t, err, `format "json": invalid value "maybe" for option "inline", expected a boolean`)
+}
+
+func TestOptions(t *testing.T) {
+	tr, err := transpile.New("json", transpile.Options{"inline": "true"})
+	assert.Nil(t, err)
+
+	sb := &strings.Builder{}
+	assert.Nil(t, tr.Transpile(sb, ast.Block{ast.T("a")}))
+	assert.NotContains(t, strings.TrimSpace(sb.String()), "\n")
+}
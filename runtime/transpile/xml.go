@@ -0,0 +1,262 @@
+package transpile
+
+import (
+	"bufio"
+	"encoding/xml"
+	"fmt"
+	"io"
+	"sort"
+	"strings"
+	"unicode"
+	"unicode/utf8"
+
+	"github.com/aziis98/textml/ast"
+)
+
+// XmlNamespaceBase is the prefix of the URIs of the XML namespaces used for element namespaces without a known URI, for example #foo.bar becomes <foo:bar> with xmlns:foo="urn:textml:foo".
+const XmlNamespaceBase = "urn:textml:"
+
+// xmlNamespaces holds the URIs of well known namespaces
+var xmlNamespaces = map[string]string{
+	"html": "http://www.w3.org/1999/xhtml",
+}
+
+// Xml converts documents to XML: each element becomes an XML element with its arguments as <arg> children and text becomes character data. Element names with dots become namespaced names, #html.div is written as <html:div> and the namespace is declared on the root element.
+//
+// Elements only contain arguments and arguments only contain the content of the document, so the document can be read back exactly by importer.XML.
+type Xml struct {
+	// Root is the name of the root element, "textml" when empty
+	Root string
+
+	// Arg is the name of the elements holding the arguments, "arg" when empty
+	Arg string
+
+	// Compact writes the content of elements with a single argument directly inside them, like <bold>text</bold>, unless that would be read back as arguments
+	Compact bool
+
+	// Namespaces maps namespaces to URIs, other namespaces use the well known URI (like XHTML for "html") or [XmlNamespaceBase] followed by their name
+	Namespaces map[string]string
+}
+
+func newXml(opts Options) (Transpiler, error) {
+	if err := opts.Check("root", "arg", "compact"); err != nil {
+		return nil, err
+	}
+
+	compact, err := opts.Bool("compact", false)
+	if err != nil {
+		return nil, err
+	}
+
+	t := &Xml{
+		Root:    opts.String("root", "textml"),
+		Arg:     opts.String("arg", "arg"),
+		Compact: compact,
+	}
+
+	for key, name := range map[string]string{"root": t.Root, "arg": t.Arg} {
+		if !isXmlName(name) {
+			return nil, fmt.Errorf("invalid value %q for option %q, expected an XML name without namespace", name, key)
+		}
+	}
+
+	return t, nil
+}
+
+func (t *Xml) root() string {
+	if t.Root == "" {
+		return "textml"
+	}
+
+	return t.Root
+}
+
+func (t *Xml) arg() string {
+	if t.Arg == "" {
+		return "arg"
+	}
+
+	return t.Arg
+}
+
+// namespaceURI returns the URI of the XML namespace used for an element namespace.
+func (t *Xml) namespaceURI(namespace string) string {
+	if uri, ok := t.Namespaces[namespace]; ok {
+		return uri
+	}
+	if uri, ok := xmlNamespaces[namespace]; ok {
+		return uri
+	}
+
+	return XmlNamespaceBase + namespace
+}
+
+// Transpile writes the document as an XML document, namespace aliases are resolved first.
+func (t *Xml) Transpile(w io.Writer, block ast.Block) error {
+	block, err := ast.ResolveNamespaces(block)
+	if err != nil {
+		return err
+	}
+
+	block = ast.Normalize(block, ast.MergeText)
+
+	namespaces := map[string]bool{}
+	if err := block.WalkPath(func(_ ast.Path, n ast.Node) error {
+		if elem, ok := n.(*ast.ElementNode); ok {
+			if _, err := xmlElementName(elem); err != nil {
+				return err
+			}
+			if namespace := elem.Namespace(); namespace != "" {
+				namespaces[namespace] = true
+			}
+		}
+
+		return nil
+	}); err != nil {
+		return err
+	}
+
+	prefixes := []string{}
+	for namespace := range namespaces {
+		prefixes = append(prefixes, namespace)
+	}
+	sort.Strings(prefixes)
+
+	bw := bufio.NewWriter(w)
+
+	bw.WriteString(xml.Header)
+	bw.WriteString("<" + t.root())
+	for _, prefix := range prefixes {
+		bw.WriteString(" xmlns:" + prefix + `="`)
+		xml.EscapeText(bw, []byte(t.namespaceURI(prefix)))
+		bw.WriteString(`"`)
+	}
+	bw.WriteString(">")
+
+	if err := t.writeBlock(bw, block); err != nil {
+		return err
+	}
+
+	bw.WriteString("</" + t.root() + ">\n")
+
+	return bw.Flush()
+}
+
+func (t *Xml) writeBlock(w *bufio.Writer, block ast.Block) error {
+	for _, n := range block {
+		switch n := n.(type) {
+		case *ast.TextNode:
+			if i := strings.IndexFunc(n.Text, isXmlInvalidChar); i >= 0 {
+				r, _ := utf8.DecodeRuneInString(n.Text[i:])
+				return fmt.Errorf("%v: character %U can't be written in XML", n.Pos, r)
+			}
+
+			w.WriteString(xmlTextEscapes.Replace(n.Text))
+		case *ast.ElementNode:
+			if err := t.writeElement(w, n); err != nil {
+				return err
+			}
+		default:
+			panic("illegal state")
+		}
+	}
+
+	return nil
+}
+
+func (t *Xml) writeElement(w *bufio.Writer, elem *ast.ElementNode) error {
+	name, err := xmlElementName(elem)
+	if err != nil {
+		return err
+	}
+
+	if len(elem.Arguments) == 0 {
+		w.WriteString("<" + name + "/>")
+		return nil
+	}
+
+	w.WriteString("<" + name + ">")
+
+	if t.Compact && len(elem.Arguments) == 1 && t.isInlineArgument(elem.Arguments[0]) {
+		if err := t.writeBlock(w, elem.Arguments[0]); err != nil {
+			return err
+		}
+	} else {
+		for _, arg := range elem.Arguments {
+			if len(arg) == 0 {
+				w.WriteString("<" + t.arg() + "/>")
+				continue
+			}
+
+			w.WriteString("<" + t.arg() + ">")
+			if err := t.writeBlock(w, arg); err != nil {
+				return err
+			}
+			w.WriteString("</" + t.arg() + ">")
+		}
+	}
+
+	w.WriteString("</" + name + ">")
+	return nil
+}
+
+// isInlineArgument reports whether a single argument can be written without the <arg> element, that is when it isn't blank and has no element with the name of the argument elements.
+func (t *Xml) isInlineArgument(arg ast.Block) bool {
+	if arg.FirstElement() == nil && strings.TrimSpace(arg.TextContent()) == "" {
+		return false
+	}
+
+	for _, n := range arg {
+		if elem, ok := n.(*ast.ElementNode); ok && elem.Name == t.arg() {
+			return false
+		}
+	}
+
+	return true
+}
+
+// xmlTextEscapes escapes character data, unlike [xml.EscapeText] newlines and tabs are kept as they are and carriage returns are escaped so that they are not normalized away by readers
+var xmlTextEscapes = strings.NewReplacer(
+	"&", "&amp;",
+	"<", "&lt;",
+	">", "&gt;",
+	"\r", "&#xD;",
+)
+
+// isXmlInvalidChar reports whether the character is not allowed in XML 1.0 documents, even as a character reference.
+func isXmlInvalidChar(r rune) bool {
+	return r < 0x20 && r != '\t' && r != '\n' && r != '\r' || r == 0xFFFE || r == 0xFFFF || 0xD800 <= r && r <= 0xDFFF
+}
+
+// xmlElementName returns the qualified XML name of an element, the namespace becomes the prefix.
+func xmlElementName(elem *ast.ElementNode) (string, error) {
+	namespace, local := ast.SplitName(elem.Name)
+
+	if !isXmlName(local) || namespace != "" && (!isXmlName(namespace) || strings.HasPrefix(strings.ToLower(namespace), "xml")) {
+		return "", fmt.Errorf("%v: element name %q can't be written as an XML name", elem.Pos, elem.Name)
+	}
+
+	if namespace == "" {
+		return local, nil
+	}
+
+	return namespace + ":" + local, nil
+}
+
+// isXmlName reports whether name is a valid XML name without colons, element names are already restricted to letters, digits, "-", "_" and "." so only the first character needs more checks.
+func isXmlName(name string) bool {
+	if name == "" {
+		return false
+	}
+
+	for i, r := range name {
+		if i == 0 && !unicode.IsLetter(r) && r != '_' {
+			return false
+		}
+		if !unicode.IsLetter(r) && !unicode.IsDigit(r) && !strings.ContainsRune("-_.", r) {
+			return false
+		}
+	}
+
+	return true
+}
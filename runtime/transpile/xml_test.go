@@ -0,0 +1,69 @@
+package transpile_test
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/aziis98/textml"
+	"github.com/aziis98/textml/ast"
+	"github.com/aziis98/textml/runtime/transpile"
+	"github.com/stretchr/testify/assert"
+)
+
+func transpileXml(t *testing.T, block ast.Block, opts transpile.Options) (string, error) {
+	t.Helper()
+
+	tr, err := transpile.New("xml", opts)
+	assert.Nil(t, err)
+
+	sb := &strings.Builder{}
+	err = tr.Transpile(sb, block)
+	return sb.String(), err
+}
+
+func TestXml(t *testing.T) {
+	doc, err := textml.ParseDocument(strings.NewReader(`#namespace{ h }{ html }
+A #bold{ b & <c> } #link{ x }{ y } #h.p{ z } #svg.circle{ #r{ 1 } }{}`))
+	assert.Nil(t, err)
+
+	result, err := transpileXml(t, doc, nil)
+	assert.Nil(t, err)
+	assert.Equal(t, xmlHeader+`<textml xmlns:html="http://www.w3.org/1999/xhtml" xmlns:svg="urn:textml:svg">
+A <bold><arg>b &amp; &lt;c&gt;</arg></bold> <link><arg>x</arg><arg>y</arg></link> <html:p><arg>z</arg></html:p> <svg:circle><arg><r><arg>1</arg></r></arg><arg/></svg:circle></textml>
+`, result)
+
+	result, err = transpileXml(t, doc, transpile.Options{"compact": "true", "root": "doc", "arg": "a"})
+	assert.Nil(t, err)
+	assert.Equal(t, xmlHeader+`<doc xmlns:html="http://www.w3.org/1999/xhtml" xmlns:svg="urn:textml:svg">
+A <bold>b &amp; &lt;c&gt;</bold> <link><a>x</a><a>y</a></link> <html:p>z</html:p> <svg:circle><a><r>1</r></a><a/></svg:circle></doc>
+`, result)
+}
+
+const xmlHeader = `<?xml version="1.0" encoding="UTF-8"?>` + "\n"
+
+func TestXmlCompact(t *testing.T) {
+	doc := ast.Block{
+		ast.EN("empty"),
+		ast.EN("blank", ast.B(ast.T(" "))),
+		ast.E("wrapped", ast.E("arg", ast.T("x"))),
+		ast.T("a\r\nb"),
+	}
+
+	result, err := transpileXml(t, doc, transpile.Options{"compact": "true"})
+	assert.Nil(t, err)
+	assert.Equal(t, xmlHeader+"<textml><empty/><blank><arg> </arg></blank><wrapped><arg><arg>x</arg></arg></wrapped>a&#xD;\nb</textml>\n", result)
+}
+
+func TestXmlErrors(t *testing.T) {
+	_, err := transpileXml(t, ast.Block{ast.E("1st", ast.T("x"))}, nil)
+	assert.ErrorContains(t, err, `element name "1st" can't be written as an XML name`)
+
+	_, err = transpileXml(t, ast.Block{ast.E("xmlns.a", ast.T("x"))}, nil)
+	assert.ErrorContains(t, err, `element name "xmlns.a" can't be written as an XML name`)
+
+	_, err = transpileXml(t, ast.Block{ast.T("a\x00b")}, nil)
+	assert.ErrorContains(t, err, "character U+0000 can't be written in XML")
+
+	_, err = transpile.New("xml", transpile.Options{"arg": "a:b"})
+	assert.EqualError(t, err, `format "xml": invalid value "a:b" for option "arg", expected an XML name without namespace`)
+}
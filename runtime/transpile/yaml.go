@@ -0,0 +1,136 @@
+package transpile
+
+import (
+	"bytes"
+	"fmt"
+	"io"
+	"regexp"
+	"strconv"
+	"strings"
+	"unicode"
+
+	"github.com/aziis98/textml/ast"
+)
+
+// Yaml converts dictionary shaped documents, like configuration files made of #KEY{ VALUE } entries, to YAML. Values that are dictionaries become nested mappings, repeated entries become sequences and the other values are booleans and numbers like in [Toml] or strings with the text of the value, quoted when a reader would resolve them to another type like no, null or 0x1F.
+type Yaml struct{}
+
+func newYaml(opts Options) (Transpiler, error) {
+	if err := opts.Check(); err != nil {
+		return nil, err
+	}
+
+	return &Yaml{}, nil
+}
+
+// Transpile writes the document as a YAML mapping, documents with text outside of the entries are rejected.
+func (t *Yaml) Transpile(w io.Writer, block ast.Block) error {
+	block, err := ast.ResolveNamespaces(block)
+	if err != nil {
+		return err
+	}
+
+	block = ast.Normalize(block, ast.MergeText)
+
+	if err := checkDictionary(block, "yaml"); err != nil {
+		return err
+	}
+
+	if block.FirstElement() == nil {
+		_, err := io.WriteString(w, "{}\n")
+		return err
+	}
+
+	return writeYAMLMapping(w, block, "")
+}
+
+// writeYAMLMapping writes the entries of a dictionary as a YAML block mapping indented by the given prefix, nested dictionaries become nested mappings and repeated elements become sequences.
+func writeYAMLMapping(w io.Writer, block ast.Block, indent string) error {
+	for _, entry := range dictEntries(block) {
+		key := yamlString(entry.Name)
+
+		if len(entry.Values) == 1 {
+			if err := writeYAMLEntry(w, indent, key+":", entry.Values[0]); err != nil {
+				return err
+			}
+			continue
+		}
+
+		if _, err := fmt.Fprintf(w, "%s%s:\n", indent, key); err != nil {
+			return err
+		}
+		for _, value := range entry.Values {
+			if err := writeYAMLEntry(w, indent+"  ", "-", value); err != nil {
+				return err
+			}
+		}
+	}
+
+	return nil
+}
+
+// writeYAMLEntry writes a value after the prefix, a key or a sequence dash. Dictionaries go on the following lines, except in sequences where the first entry follows the dash.
+func writeYAMLEntry(w io.Writer, indent, prefix string, value ast.Block) error {
+	if !value.IsDictionary() {
+		_, err := fmt.Fprintf(w, "%s%s %s\n", indent, prefix, yamlValue(scalarValue(value)))
+		return err
+	}
+
+	if prefix != "-" {
+		if _, err := fmt.Fprintf(w, "%s%s\n", indent, prefix); err != nil {
+			return err
+		}
+
+		return writeYAMLMapping(w, value, indent+"  ")
+	}
+
+	mapping := &bytes.Buffer{}
+	if err := writeYAMLMapping(mapping, value, indent+"  "); err != nil {
+		return err
+	}
+
+	_, err := fmt.Fprintf(w, "%s- %s", indent, bytes.TrimPrefix(mapping.Bytes(), []byte(indent+"  ")))
+	return err
+}
+
+// regexYAMLNonString matches the plain scalars that YAML 1.1 or 1.2 readers resolve to nulls, booleans, numbers, timestamps or the merge key instead of strings
+var regexYAMLNonString = regexp.MustCompile(`^(` +
+	`~|null|Null|NULL|` +
+	`y|Y|yes|Yes|YES|n|N|no|No|NO|true|True|TRUE|false|False|FALSE|on|On|ON|off|Off|OFF|` +
+	`[-+]?(0b[01_]+|0o?[0-7_]+|0x[0-9a-fA-F_]+|[0-9][0-9_]*(:[0-5]?[0-9])*(\.[0-9_.]*)?([eE][-+]?[0-9]+)?|\.[0-9_.]*([eE][-+]?[0-9]+)?|\.(inf|Inf|INF))|` +
+	`\.(nan|NaN|NAN)|` +
+	`[0-9]{4}-[0-9]{1,2}-[0-9]{1,2}(([Tt]|[ \t]+)[0-9]{1,2}:[0-9]{2}:[0-9]{2}.*)?|` +
+	`<<|=` +
+	`)$`)
+
+// yamlValue returns the scalar for the text of a value, booleans and numbers following [isTypedValue] are written plain and the other values as strings.
+func yamlValue(s string) string {
+	if isTypedValue(s) {
+		return s
+	}
+
+	return yamlString(s)
+}
+
+// yamlString returns a plain YAML scalar when the string can't be mistaken for YAML syntax or for a value of another type, otherwise a double quoted one.
+func yamlString(s string) string {
+	if s == "" || s != strings.TrimSpace(s) || regexYAMLNonString.MatchString(s) {
+		return strconv.Quote(s)
+	}
+	if strings.ContainsRune(",[]{}#&*!|>'\"%@`", rune(s[0])) {
+		return strconv.Quote(s)
+	}
+	if strings.ContainsRune("-?:", rune(s[0])) && (len(s) == 1 || s[1] == ' ') {
+		return strconv.Quote(s)
+	}
+	if strings.Contains(s, ": ") || strings.Contains(s, " #") || strings.HasSuffix(s, ":") {
+		return strconv.Quote(s)
+	}
+	for _, r := range s {
+		if unicode.IsControl(r) {
+			return strconv.Quote(s)
+		}
+	}
+
+	return s
+}
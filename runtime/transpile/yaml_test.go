@@ -0,0 +1,109 @@
+package transpile_test
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/aziis98/textml"
+	"github.com/aziis98/textml/runtime/transpile"
+	"github.com/stretchr/testify/assert"
+)
+
+const config = `
+#name{ textml }
+#version{ 1.0 }
+#released{ 2022-08-15 }
+#tags{ a }
+#tags{ b: c }
+#server{
+    #host{ localhost }
+    #tls{ #cert{ a.pem } }
+}
+#user{ #name{ alice } #role{ admin } }
+#user{ #name{ bob } }
+#title{ An #italic{ "example" } }
+`
+
+func transpileData(t *testing.T, format, source string) (string, error) {
+	t.Helper()
+
+	doc, err := textml.ParseDocument(strings.NewReader(source))
+	assert.Nil(t, err)
+
+	tr, err := transpile.New(format, nil)
+	assert.Nil(t, err)
+
+	sb := &strings.Builder{}
+	err = tr.Transpile(sb, doc)
+	return sb.String(), err
+}
+
+func TestYaml(t *testing.T) {
+	result, err := transpileData(t, "yaml", config)
+	assert.Nil(t, err)
+	assert.Equal(t, `name: textml
+version: 1.0
+released: "2022-08-15"
+tags:
+  - a
+  - "b: c"
+server:
+  host: localhost
+  tls:
+    cert: a.pem
+user:
+  - name: alice
+    role: admin
+  - name: bob
+title: An "example"
+`, result)
+
+	result, err = transpileData(t, "yaml", `#empty{} #quoted{ "x" } #list{ [a] } #dash{ - } #negative{ -1 }`)
+	assert.Nil(t, err)
+	assert.Equal(t, "empty: \"\"\nquoted: \"\\\"x\\\"\"\nlist: \"[a]\"\ndash: \"-\"\nnegative: -1\n", result)
+
+	// values that readers would resolve to other types are quoted, keys are always strings
+	result, err = transpileData(t, "yaml", `
+#no{ no } #null{ null } #tilde{ ~ } #trailing-zero{ 1.10 } #hex{ 0x1F } #octal{ 01234 } #sexagesimal{ 1:30 }
+#big{ 99999999999999999999 } #float{ 0.25 } #bool{ true } #on{ on } #time{ 2022-08-15 15:00:00 } #text{ 1st }
+#1{ a } #true{ b }`)
+	assert.Nil(t, err)
+	assert.Equal(t, `"no": "no"
+"null": "null"
+tilde: "~"
+trailing-zero: "1.10"
+hex: "0x1F"
+octal: "01234"
+sexagesimal: "1:30"
+big: "99999999999999999999"
+float: 0.25
+bool: true
+"on": "on"
+time: "2022-08-15 15:00:00"
+text: 1st
+"1": a
+"true": b
+`, result)
+}
+
+func TestYamlMultilineValues(t *testing.T) {
+	result, err := transpileData(t, "yaml", `
+#title{
+    Example
+}
+#tags{
+    a
+}
+#tags{ b }
+`)
+	assert.Nil(t, err)
+	assert.Equal(t, "title: Example\ntags:\n  - a\n  - b\n", result)
+}
+
+func TestYamlErrors(t *testing.T) {
+	_, err := transpileData(t, "yaml", "#a{ 1 } text")
+	assert.ErrorContains(t, err, `unexpected text "text", the yaml format expects a dictionary of #KEY{ VALUE } entries`)
+
+	_, err = transpileData(t, "yaml", "#a{ #b{ 1 }{ 2 } }")
+	assert.ErrorContains(t, err, "entry #b must have a single argument but has 2")
+}
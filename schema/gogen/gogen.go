@@ -0,0 +1,413 @@
+// Package gogen generates Go types and decoders for the element vocabulary described by a [schema.Schema].
+//
+// Elements with a single text argument become strings and elements with a single "mixed" or "any" argument become [ast.Block]s, all other elements get a struct type and a DecodeNAME function. In these structs an argument with "elements" content and a #children list is read as a dictionary with a field for each child (children listed in #repeated become slices), text arguments become strings and the other arguments are kept as [ast.Block]s. Arguments are named after their #name or "Content" (for single argument elements) or "ArgN".
+//
+// When the #root rule of the schema is a dictionary the package also gets a Document type and a DecodeDocument function, schemas where two elements or an element and the root would get the same type name are rejected.
+//
+// Generated files also define a few unexported helpers, to generate more schemas in the same package give each one a different [Options.Prefix].
+//
+// Generated decoders check argument counts and text content but not the other rules of the schema, use [schema.Schema.Validate] for that.
+package gogen
+
+import (
+	"bytes"
+	"fmt"
+	"go/format"
+	"io"
+	"regexp"
+	"sort"
+	"strings"
+	"unicode"
+	"unicode/utf8"
+
+	"github.com/aziis98/textml/schema"
+)
+
+// Options for [Generate]
+type Options struct {
+	// Package is the name of the generated package
+	Package string
+
+	// Prefix is prepended to the names of the generated types, decoders and helpers, for example "Fig" gives FigFigure, DecodeFigFigure and figDecodeText.
+	Prefix string
+}
+
+// regexPrefix matches the valid prefixes, these must keep the generated types exported
+var regexPrefix = regexp.MustCompile(`^[A-Z][A-Za-z0-9_]*$`)
+
+// Generate writes a Go source file with types and decoders for the elements of the schema.
+func Generate(w io.Writer, s *schema.Schema, opts Options) error {
+	if opts.Package == "" {
+		return fmt.Errorf("missing package name")
+	}
+	if opts.Prefix != "" && !regexPrefix.MatchString(opts.Prefix) {
+		return fmt.Errorf("invalid prefix %q, expected an exported Go identifier", opts.Prefix)
+	}
+
+	g := &generator{schema: s, prefix: opts.Prefix, buf: &bytes.Buffer{}}
+
+	g.printf("// Code generated by \"textml gen go\"; DO NOT EDIT.\n\n")
+	g.printf("package %s\n\n", opts.Package)
+	g.printf("import (\n\"fmt\"\n\"strings\"\n\n\"github.com/aziis98/textml/ast\"\n)\n\n")
+
+	names := []string{}
+	for name := range s.Elements {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	if err := g.checkTypeNames(names); err != nil {
+		return err
+	}
+
+	if isDictionary(s.Root) {
+		if err := g.generateDocument(); err != nil {
+			return err
+		}
+	}
+
+	for _, name := range names {
+		if g.kindOf(s.Elements[name]) != kindStruct {
+			continue
+		}
+
+		if err := g.generateElement(name, s.Elements[name]); err != nil {
+			return err
+		}
+	}
+
+	g.printf("%s", strings.NewReplacer(
+		"decodeText(", g.helper("decodeText")+"(",
+		"decodeBlock(", g.helper("decodeBlock")+"(",
+		"textContent(", g.helper("textContent")+"(",
+	).Replace(helpers))
+
+	source, err := format.Source(g.buf.Bytes())
+	if err != nil {
+		return fmt.Errorf("formatting generated code: %w", err)
+	}
+
+	_, err = w.Write(source)
+	return err
+}
+
+const helpers = `
+func decodeText(elem *ast.ElementNode) (string, error) {
+	if len(elem.Arguments) != 1 {
+		return "", fmt.Errorf("%v: #%s expects 1 argument, got %d", elem.Pos, elem.Name, len(elem.Arguments))
+	}
+
+	return textContent(elem.Arguments[0])
+}
+
+func decodeBlock(elem *ast.ElementNode) (ast.Block, error) {
+	if len(elem.Arguments) != 1 {
+		return nil, fmt.Errorf("%v: #%s expects 1 argument, got %d", elem.Pos, elem.Name, len(elem.Arguments))
+	}
+
+	return elem.Arguments[0], nil
+}
+
+func textContent(block ast.Block) (string, error) {
+	for _, n := range block {
+		if elem, ok := n.(*ast.ElementNode); ok {
+			return "", fmt.Errorf("%v: unexpected element #%s, expected only text", elem.Pos, elem.Name)
+		}
+	}
+
+	return strings.TrimSpace(block.TextContent()), nil
+}
+`
+
+type kind int
+
+const (
+	kindText kind = iota
+	kindBlock
+	kindStruct
+)
+
+type generator struct {
+	schema *schema.Schema
+	prefix string
+	buf    *bytes.Buffer
+}
+
+// typeName returns the name of the type generated for an element.
+func (g *generator) typeName(name string) string {
+	return g.prefix + goName(name)
+}
+
+// helper returns the name of a helper function, helpers of prefixed files start with the prefix in lower case.
+func (g *generator) helper(name string) string {
+	if g.prefix == "" {
+		return name
+	}
+
+	return strings.ToLower(g.prefix[:1]) + g.prefix[1:] + strings.ToUpper(name[:1]) + name[1:]
+}
+
+func (g *generator) printf(format string, args ...any) {
+	fmt.Fprintf(g.buf, format, args...)
+}
+
+func isDictionary(rule schema.Argument) bool {
+	return rule.Content == schema.ContentElements && len(rule.Children) > 0
+}
+
+func (g *generator) kindOf(decl schema.Element) kind {
+	if len(decl.Arguments) == 1 && decl.Args.Min == 1 && decl.Args.Max == 1 {
+		rule := decl.Arguments[0]
+
+		switch {
+		case rule.Content == schema.ContentText:
+			return kindText
+		case !isDictionary(rule):
+			return kindBlock
+		}
+	}
+
+	return kindStruct
+}
+
+// field is a field of a generated struct
+type field struct {
+	name, goType string
+}
+
+// dictionaryFields returns the fields for the children of a dictionary argument.
+func (g *generator) dictionaryFields(rule schema.Argument) ([]field, error) {
+	fields := []field{}
+
+	for _, child := range rule.Children {
+		decl, ok := g.schema.Elements[child]
+		if !ok {
+			return nil, fmt.Errorf("child #%s is not declared in the schema", child)
+		}
+
+		goType := "ast.Block"
+		switch g.kindOf(decl) {
+		case kindText:
+			goType = "string"
+		case kindStruct:
+			goType = "*" + g.typeName(child)
+		}
+
+		if rule.Repeated.Contains(child) {
+			goType = "[]" + goType
+		}
+
+		fields = append(fields, field{goName(child), goType})
+	}
+
+	return fields, nil
+}
+
+func argumentName(decl schema.Element, i int) string {
+	switch {
+	case decl.Arguments[i].Name != "":
+		return goName(decl.Arguments[i].Name)
+	case len(decl.Arguments) == 1:
+		return "Content"
+	default:
+		return fmt.Sprintf("Arg%d", i+1)
+	}
+}
+
+func (g *generator) printStruct(typeName string, fields []field) error {
+	seen := map[string]bool{}
+
+	g.printf("type %s struct {\n", typeName)
+	for _, f := range fields {
+		if seen[f.name] {
+			return fmt.Errorf("type %s has more than one field named %s", typeName, f.name)
+		}
+		seen[f.name] = true
+
+		g.printf("%s %s\n", f.name, f.goType)
+	}
+	g.printf("}\n\n")
+
+	return nil
+}
+
+// printDictionaryLoop decodes each child of the block in blockExpr into the fields of "v".
+func (g *generator) printDictionaryLoop(blockExpr string, rule schema.Argument) {
+	g.printf("for _, n := range %s {\n", blockExpr)
+	g.printf("child, ok := n.(*ast.ElementNode)\nif !ok {\ncontinue\n}\n\n")
+	g.printf("switch child.Name {\n")
+
+	for _, child := range rule.Children {
+		decode := g.helper("decodeBlock")
+		switch g.kindOf(g.schema.Elements[child]) {
+		case kindText:
+			decode = g.helper("decodeText")
+		case kindStruct:
+			decode = "Decode" + g.typeName(child)
+		}
+
+		g.printf("case %q:\n", child)
+		g.printf("value, err := %s(child)\nif err != nil {\nreturn nil, err\n}\n", decode)
+
+		if rule.Repeated.Contains(child) {
+			g.printf("v.%[1]s = append(v.%[1]s, value)\n", goName(child))
+		} else {
+			g.printf("v.%s = value\n", goName(child))
+		}
+	}
+
+	g.printf("}\n}\n")
+}
+
+// checkTypeNames returns an error if two generated types get the same name, like the Document type of a dictionary root and the type of a #document element or the types of #a-b and #a_b.
+func (g *generator) checkTypeNames(names []string) error {
+	owners := map[string]string{}
+	if isDictionary(g.schema.Root) {
+		owners[g.prefix+"Document"] = "#root"
+	}
+
+	for _, name := range names {
+		if g.kindOf(g.schema.Elements[name]) != kindStruct {
+			continue
+		}
+
+		typeName := g.typeName(name)
+		if owner, ok := owners[typeName]; ok {
+			return fmt.Errorf("%s and #%s both generate the type %s", owner, name, typeName)
+		}
+
+		owners[typeName] = "#" + name
+	}
+
+	return nil
+}
+
+func (g *generator) generateDocument() error {
+	fields, err := g.dictionaryFields(g.schema.Root)
+	if err != nil {
+		return fmt.Errorf("root: %w", err)
+	}
+
+	typeName := g.prefix + "Document"
+
+	g.printf("// %s holds the top level elements of a document.\n", typeName)
+	if err := g.printStruct(typeName, fields); err != nil {
+		return err
+	}
+
+	g.printf("// Decode%[1]s decodes the top level elements of a document.\n", typeName)
+	g.printf("func Decode%[1]s(block ast.Block) (*%[1]s, error) {\n", typeName)
+	g.printf("v := &%s{}\n\n", typeName)
+	g.printDictionaryLoop("block", g.schema.Root)
+	g.printf("\nreturn v, nil\n}\n\n")
+
+	return nil
+}
+
+func (g *generator) generateElement(name string, decl schema.Element) error {
+	typeName := g.typeName(name)
+
+	fields := []field{}
+	for i, rule := range decl.Arguments {
+		switch {
+		case isDictionary(rule):
+			children, err := g.dictionaryFields(rule)
+			if err != nil {
+				return fmt.Errorf("#%s: %w", name, err)
+			}
+
+			fields = append(fields, children...)
+		case rule.Content == schema.ContentText:
+			fields = append(fields, field{argumentName(decl, i), "string"})
+		default:
+			fields = append(fields, field{argumentName(decl, i), "ast.Block"})
+		}
+	}
+
+	g.printf("// %s is the #%s element.\n", typeName, name)
+	if err := g.printStruct(typeName, fields); err != nil {
+		return err
+	}
+
+	g.printf("// Decode%s decodes a #%s element.\n", typeName, name)
+	g.printf("func Decode%s(elem *ast.ElementNode) (*%s, error) {\n", typeName, typeName)
+
+	if decl.Args.Min > 0 || decl.Args.Max >= 0 {
+		switch {
+		case decl.Args.Min == decl.Args.Max:
+			g.printf("if len(elem.Arguments) != %d {\n", decl.Args.Min)
+		case decl.Args.Max < 0:
+			g.printf("if len(elem.Arguments) < %d {\n", decl.Args.Min)
+		default:
+			g.printf("if len(elem.Arguments) < %d || len(elem.Arguments) > %d {\n", decl.Args.Min, decl.Args.Max)
+		}
+		g.printf("return nil, fmt.Errorf(\"%%v: #%s expects %v, got %%d\", elem.Pos, len(elem.Arguments))\n}\n\n", name, decl.Args)
+	}
+
+	g.printf("v := &%s{}\n\n", typeName)
+
+	for _, rule := range decl.Arguments {
+		if rule.Content == schema.ContentText {
+			g.printf("var err error\n\n")
+			break
+		}
+	}
+
+	for i, rule := range decl.Arguments {
+		optional := i >= decl.Args.Min
+		if optional {
+			g.printf("if len(elem.Arguments) > %d {\n", i)
+		}
+
+		argExpr := fmt.Sprintf("elem.Arguments[%d]", i)
+
+		switch {
+		case isDictionary(rule):
+			g.printDictionaryLoop(argExpr, rule)
+		case rule.Content == schema.ContentText:
+			g.printf("if v.%s, err = %s(%s); err != nil {\nreturn nil, err\n}\n", argumentName(decl, i), g.helper("textContent"), argExpr)
+		default:
+			g.printf("v.%s = %s\n", argumentName(decl, i), argExpr)
+		}
+
+		if optional {
+			g.printf("}\n")
+		}
+		g.printf("\n")
+	}
+
+	g.printf("return v, nil\n}\n\n")
+
+	return nil
+}
+
+// initialisms are written in upper case in Go names
+var initialisms = map[string]bool{
+	"api": true, "css": true, "html": true, "http": true, "id": true,
+	"json": true, "uri": true, "url": true, "uuid": true, "xml": true,
+}
+
+// goName converts an element name like "a-dict.value" to an exported Go name like "ADictValue".
+func goName(name string) string {
+	parts := strings.FieldsFunc(name, func(r rune) bool {
+		return r == '-' || r == '_' || r == '.'
+	})
+
+	sb := &strings.Builder{}
+	for _, part := range parts {
+		if initialisms[strings.ToLower(part)] {
+			sb.WriteString(strings.ToUpper(part))
+			continue
+		}
+
+		runes := []rune(part)
+		sb.WriteString(string(unicode.ToUpper(runes[0])) + string(runes[1:]))
+	}
+
+	// Go identifiers can't start with a digit
+	if first, _ := utf8.DecodeRuneInString(sb.String()); !unicode.IsLetter(first) {
+		return "X" + sb.String()
+	}
+
+	return sb.String()
+}
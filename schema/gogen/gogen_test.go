@@ -0,0 +1,87 @@
+package gogen_test
+
+import (
+	"bytes"
+	"go/parser"
+	"go/token"
+	"os"
+	"strings"
+	"testing"
+
+	"github.com/aziis98/textml"
+	"github.com/aziis98/textml/schema"
+	"github.com/aziis98/textml/schema/gogen"
+	"github.com/stretchr/testify/assert"
+)
+
+func parseFigureSchema(t *testing.T) *schema.Schema {
+	t.Helper()
+
+	source, err := os.ReadFile("../../examples/figure.schema.tml")
+	assert.Nil(t, err)
+
+	doc, err := textml.ParseDocument(strings.NewReader(string(source)))
+	assert.Nil(t, err)
+
+	s, err := schema.Parse(doc)
+	assert.Nil(t, err)
+
+	return s
+}
+
+func TestGenerateFigures(t *testing.T) {
+	s := parseFigureSchema(t)
+
+	buf := &bytes.Buffer{}
+	err := gogen.Generate(buf, s, gogen.Options{Package: "figures"})
+	assert.Nil(t, err)
+
+	expected, err := os.ReadFile("testdata/figures.go.golden")
+	assert.Nil(t, err)
+	assert.Equal(t, string(expected), buf.String())
+}
+
+func TestGeneratePrefix(t *testing.T) {
+	s := parseFigureSchema(t)
+
+	// files generated with different prefixes can be in the same package
+	declared := map[string]int{}
+	for _, prefix := range []string{"", "Fig"} {
+		buf := &bytes.Buffer{}
+		assert.Nil(t, gogen.Generate(buf, s, gogen.Options{Package: "figures", Prefix: prefix}))
+
+		file, err := parser.ParseFile(token.NewFileSet(), "figures.go", buf.Bytes(), 0)
+		assert.Nil(t, err)
+
+		for name := range file.Scope.Objects {
+			declared[name]++
+		}
+	}
+
+	for name, count := range declared {
+		assert.Equal(t, 1, count, name)
+	}
+	assert.Contains(t, declared, "FigFigure")
+	assert.Contains(t, declared, "DecodeFigDocument")
+	assert.Contains(t, declared, "figDecodeText")
+
+	err := gogen.Generate(&bytes.Buffer{}, s, gogen.Options{Package: "figures", Prefix: "fig"})
+	assert.EqualError(t, err, `invalid prefix "fig", expected an exported Go identifier`)
+}
+
+func TestGenerateNameClash(t *testing.T) {
+	for source, message := range map[string]string{
+		`#root{ #content{ elements } #children{ document } }
+#elements{ #document{ #arg{ #content{ elements } #children{ title } } } #title{ #arg{ #content{ text } } } }`: "#root and #document both generate the type Document",
+		`#elements{ #a-b{ #args{ 2 } } #a_b{ #args{ 2 } } }`: "#a-b and #a_b both generate the type AB",
+	} {
+		doc, err := textml.ParseDocument(strings.NewReader(source))
+		assert.Nil(t, err)
+
+		s, err := schema.Parse(doc)
+		assert.Nil(t, err)
+
+		err = gogen.Generate(&bytes.Buffer{}, s, gogen.Options{Package: "clash"})
+		assert.EqualError(t, err, message)
+	}
+}
@@ -0,0 +1,351 @@
+// Package schema describes element vocabularies with schema documents written in TextML and validates documents against them.
+//
+// A schema declares the rules for the top level of a document with #root and each allowed element in #elements, for example
+//
+//	#root{ #children{ metadata, title, bold, link } }
+//	#elements{
+//	    #metadata{ #arg{ #content{ any } #required{ title } } }
+//	    #title{ #arg{ #content{ text } } }
+//	    #bold{ #arg{ #children{ italic } } }
+//	    #link{
+//	        #arg{ #children{ bold, italic } }
+//	        #arg{ #content{ text } }
+//	    }
+//	}
+//
+// Each #arg describes the corresponding argument of the element, the argument count defaults to the number of #arg entries and can be set explicitly with #args{ N }, #args{ MIN..MAX } or #args{ MIN.. } (arguments past the last #arg have no rules). An argument rule can contain
+//
+//   - #content{ mixed | text | elements | any }: "text" forbids elements, "elements" forbids non blank text and "any" skips validation of the argument content (useful for dictionaries and raw code).
+//   - #children{ NAMES }: element names allowed in the argument, by default every declared element is allowed.
+//   - #required{ NAMES }: element names that must appear in the argument.
+//   - #repeated{ NAMES }: element names that can appear more than once in an argument with "elements" content, the other children of such arguments can appear at most once (the argument is read as a dictionary).
+//   - #name{ NAME }: a name for the argument, used by code generators.
+//
+// Entries that are not one of these rules are rejected by [Parse], so a misspelled rule is not silently ignored.
+package schema
+
+import (
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/aziis98/textml"
+	"github.com/aziis98/textml/ast"
+)
+
+// Schema describes the elements allowed in a document and how they can be nested.
+type Schema struct {
+	Root     Argument           `tml:"root"`
+	Elements map[string]Element `tml:"elements"`
+}
+
+// Element holds the rules for an element name.
+type Element struct {
+	Args      ArgCount   `tml:"args"`
+	Arguments []Argument `tml:"arg"`
+}
+
+// Argument holds the rules for the content of an element argument (or of the top level of a document).
+type Argument struct {
+	Name     string   `tml:"name"`
+	Content  Content  `tml:"content"`
+	Children NameList `tml:"children"`
+	Required NameList `tml:"required"`
+	Repeated NameList `tml:"repeated"`
+}
+
+// Content is the kind of nodes allowed in an argument.
+type Content string
+
+const (
+	ContentMixed    Content = "mixed"
+	ContentText     Content = "text"
+	ContentElements Content = "elements"
+	ContentAny      Content = "any"
+)
+
+func (c *Content) UnmarshalText(text []byte) error {
+	switch content := Content(text); content {
+	case ContentMixed, ContentText, ContentElements, ContentAny:
+		*c = content
+		return nil
+	}
+
+	return fmt.Errorf(`invalid content %q, expected one of "mixed", "text", "elements" or "any"`, text)
+}
+
+// NameList is a list of element names separated by spaces or commas.
+type NameList []string
+
+func (l *NameList) UnmarshalText(text []byte) error {
+	*l = strings.FieldsFunc(string(text), func(r rune) bool {
+		return r == ',' || r == ' ' || r == '\t' || r == '\n'
+	})
+
+	for _, name := range *l {
+		if !ast.IsValidName(name) {
+			return fmt.Errorf("invalid element name %q", name)
+		}
+	}
+
+	return nil
+}
+
+// Contains reports whether name is in the list.
+func (l NameList) Contains(name string) bool {
+	for _, n := range l {
+		if n == name {
+			return true
+		}
+	}
+
+	return false
+}
+
+// ArgCount is the allowed range for the number of arguments of an element, a negative Max means no upper bound.
+type ArgCount struct {
+	Min, Max int
+
+	set bool
+}
+
+func (c *ArgCount) UnmarshalText(text []byte) error {
+	s := string(text)
+
+	min, max, isRange := strings.Cut(s, "..")
+	if !isRange {
+		max = min
+	}
+
+	var err error
+	if c.Min, err = strconv.Atoi(min); err != nil {
+		return fmt.Errorf("invalid argument count %q", s)
+	}
+
+	c.Max = -1
+	if max != "" {
+		if c.Max, err = strconv.Atoi(max); err != nil || c.Max < c.Min {
+			return fmt.Errorf("invalid argument count %q", s)
+		}
+	}
+
+	c.set = true
+	return nil
+}
+
+func (c ArgCount) allows(n int) bool {
+	return n >= c.Min && (c.Max < 0 || n <= c.Max)
+}
+
+func (c ArgCount) String() string {
+	noun := "arguments"
+	if (c.Min == c.Max || c.Max < 0) && c.Min == 1 {
+		noun = "argument"
+	}
+
+	switch {
+	case c.Min == c.Max:
+		return fmt.Sprintf("%d %s", c.Min, noun)
+	case c.Max < 0:
+		return fmt.Sprintf("at least %d %s", c.Min, noun)
+	default:
+		return fmt.Sprintf("between %d and %d %s", c.Min, c.Max, noun)
+	}
+}
+
+// schemaRules, elementRules and argumentRules are the entries allowed at the top level of a schema, in the rules of an element and in the rules of an argument
+var (
+	schemaRules   = NameList{"root", "elements"}
+	elementRules  = NameList{"args", "arg"}
+	argumentRules = NameList{"name", "content", "children", "required", "repeated"}
+)
+
+// checkRules returns an error for the first entry of the block that is not one of the given rules, f is called with the valid entries to check their content.
+func checkRules(block ast.Block, rules NameList, f func(elem *ast.ElementNode) error) error {
+	for _, n := range block {
+		elem, ok := n.(*ast.ElementNode)
+		if !ok {
+			continue
+		}
+
+		if !rules.Contains(elem.Name) {
+			return fmt.Errorf("%v: unknown schema rule #%s, expected one of #%s", elem.Pos, elem.Name, strings.Join(rules, ", #"))
+		}
+		if f == nil || len(elem.Arguments) == 0 {
+			continue
+		}
+
+		if err := f(elem); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// checkSchema rejects the entries of a schema document that don't correspond to a rule, like a misspelled #chidren, that decoding would skip.
+func checkSchema(block ast.Block) error {
+	return checkRules(block, schemaRules, func(elem *ast.ElementNode) error {
+		if elem.Name == "root" {
+			return checkRules(elem.Arguments[0], argumentRules, nil)
+		}
+
+		// the entries of #elements are element names, their content holds the rules
+		for _, n := range elem.Arguments[0] {
+			if element, ok := n.(*ast.ElementNode); ok && len(element.Arguments) > 0 {
+				err := checkRules(element.Arguments[0], elementRules, func(rule *ast.ElementNode) error {
+					if rule.Name == "arg" {
+						return checkRules(rule.Arguments[0], argumentRules, nil)
+					}
+
+					return nil
+				})
+				if err != nil {
+					return err
+				}
+			}
+		}
+
+		return nil
+	})
+}
+
+// Parse reads a schema document, entries that are not rules are rejected.
+func Parse(block ast.Block) (*Schema, error) {
+	if err := checkSchema(block); err != nil {
+		return nil, err
+	}
+
+	s := &Schema{}
+	if err := textml.Unmarshal(block, s); err != nil {
+		return nil, err
+	}
+
+	// without #args the count is given by the #arg entries, if there are none any count is allowed
+	for name, elem := range s.Elements {
+		if !elem.Args.set {
+			elem.Args = ArgCount{Min: len(elem.Arguments), Max: len(elem.Arguments), set: true}
+			if len(elem.Arguments) == 0 {
+				elem.Args.Max = -1
+			}
+
+			s.Elements[name] = elem
+		}
+	}
+
+	return s, nil
+}
+
+// Violation is a single rule broken by a document, Path is the path of the offending node (or of the element missing a required child, empty for the document).
+type Violation struct {
+	Pos     ast.Position
+	Path    ast.Path
+	Message string
+}
+
+func (v Violation) String() string {
+	return fmt.Sprintf("%v: %s", v.Pos, v.Message)
+}
+
+// Violations is the error returned by [Schema.Validate], it lists every violation in document order.
+type Violations []Violation
+
+func (vs Violations) Error() string {
+	lines := []string{}
+	for _, v := range vs {
+		lines = append(lines, v.String())
+	}
+
+	return strings.Join(lines, "\n")
+}
+
+// Validate checks a document against the schema, the returned error is of type [Violations] and reports all problems found.
+func (s *Schema) Validate(block ast.Block) error {
+	v := &validator{schema: s}
+	v.validateArgument(block, s.Root, "the document", ast.Position{Line: 1, Column: 1}, ast.Path{}, 0)
+
+	if len(v.violations) == 0 {
+		return nil
+	}
+
+	sort.SliceStable(v.violations, func(i, j int) bool {
+		a, b := v.violations[i].Pos, v.violations[j].Pos
+		return a.Line < b.Line || (a.Line == b.Line && a.Column < b.Column)
+	})
+
+	return v.violations
+}
+
+type validator struct {
+	schema     *Schema
+	violations Violations
+}
+
+func (v *validator) report(pos ast.Position, path ast.Path, format string, args ...any) {
+	v.violations = append(v.violations, Violation{pos, path, fmt.Sprintf(format, args...)})
+}
+
+// validateArgument checks the argument arg of the element at path (the top level of the document when path is empty).
+func (v *validator) validateArgument(block ast.Block, rule Argument, owner string, pos ast.Position, path ast.Path, arg int) {
+	found := map[string]bool{}
+
+	for i, n := range block {
+		nodePath := path.Child(arg, i)
+
+		switch n := n.(type) {
+		case *ast.TextNode:
+			if rule.Content == ContentElements && strings.TrimSpace(n.Text) != "" {
+				v.report(n.Pos, nodePath, "unexpected text in %s, only elements are allowed", owner)
+			}
+
+		case *ast.ElementNode:
+			if found[n.Name] && rule.Content == ContentElements && !rule.Repeated.Contains(n.Name) {
+				v.report(n.Pos, nodePath, "element #%s can appear only once in %s", n.Name, owner)
+			}
+			found[n.Name] = true
+
+			if rule.Content == ContentAny {
+				continue
+			}
+			if rule.Content == ContentText {
+				v.report(n.Pos, nodePath, "unexpected element #%s in %s, only text is allowed", n.Name, owner)
+				continue
+			}
+			if len(rule.Children) > 0 && !rule.Children.Contains(n.Name) {
+				v.report(n.Pos, nodePath, "element #%s is not allowed in %s", n.Name, owner)
+				continue
+			}
+
+			v.validateElement(n, nodePath)
+		}
+	}
+
+	for _, name := range rule.Required {
+		if !found[name] {
+			v.report(pos, path, "missing required element #%s in %s", name, owner)
+		}
+	}
+}
+
+func (v *validator) validateElement(elem *ast.ElementNode, path ast.Path) {
+	decl, ok := v.schema.Elements[elem.Name]
+	if !ok {
+		v.report(elem.Pos, path, "unknown element #%s", elem.Name)
+		return
+	}
+
+	if !decl.Args.allows(len(elem.Arguments)) {
+		v.report(elem.Pos, path, "element #%s expects %v, got %d", elem.Name, decl.Args, len(elem.Arguments))
+	}
+
+	for i, arg := range elem.Arguments {
+		rule := Argument{}
+		if i < len(decl.Arguments) {
+			rule = decl.Arguments[i]
+		}
+
+		owner := fmt.Sprintf("argument %d of #%s", i+1, elem.Name)
+		v.validateArgument(arg, rule, owner, elem.Pos, path, i)
+	}
+}
@@ -0,0 +1,110 @@
+package schema_test
+
+import (
+	"errors"
+	"os"
+	"strings"
+	"testing"
+
+	"github.com/aziis98/textml"
+	"github.com/aziis98/textml/ast"
+	"github.com/aziis98/textml/schema"
+	"github.com/stretchr/testify/assert"
+)
+
+func parseFile(t *testing.T, filename string) ast.Block {
+	source, err := os.ReadFile(filename)
+	assert.Nil(t, err)
+
+	doc, err := textml.ParseDocument(strings.NewReader(string(source)))
+	assert.Nil(t, err)
+
+	return doc
+}
+
+func TestValidateExample(t *testing.T) {
+	s, err := schema.Parse(parseFile(t, "../examples/document.schema.tml"))
+	assert.Nil(t, err)
+
+	err = s.Validate(parseFile(t, "../examples/document.tml"))
+	assert.Nil(t, err)
+}
+
+const invalidDocument = `#metadata{ #date{ 2022-08-15 } }
+
+#title{ A #link{ title } }
+
+Some #bold{ #code{ text } } and #unknown{ element }.
+#link{ #title{ nested } }{ #bold{ url } }`
+
+func TestValidateViolations(t *testing.T) {
+	s, err := schema.Parse(parseFile(t, "../examples/document.schema.tml"))
+	assert.Nil(t, err)
+
+	doc, err := textml.ParseDocument(strings.NewReader(invalidDocument))
+	assert.Nil(t, err)
+
+	err = s.Validate(doc)
+
+	var violations schema.Violations
+	assert.True(t, errors.As(err, &violations))
+	assert.Equal(t, strings.Join([]string{
+		"1:1: missing required element #title in argument 1 of #metadata",
+		"3:11: element #link expects 2 arguments, got 1",
+		"5:33: element #unknown is not allowed in the document",
+		"6:8: element #title is not allowed in argument 1 of #link",
+		"6:28: unexpected element #bold in argument 2 of #link, only text is allowed",
+	}, "\n"), err.Error())
+
+	paths := []string{}
+	for _, v := range violations {
+		paths = append(paths, v.Path.String())
+	}
+	assert.Equal(t, []string{"/0", "/2/args/0/1", "/6", "/8/args/0/0", "/8/args/1/0"}, paths)
+
+	for _, v := range violations {
+		_, err := doc.Resolve(v.Path)
+		assert.Nil(t, err)
+	}
+}
+
+func TestValidateRepeated(t *testing.T) {
+	schemaDoc, err := textml.ParseDocument(strings.NewReader(`
+		#root{ #content{ elements } #repeated{ item } }
+		#elements{
+			#item{ #arg{ #content{ text } } }
+			#title{ #arg{ #content{ text } } }
+		}
+	`))
+	assert.Nil(t, err)
+
+	s, err := schema.Parse(schemaDoc)
+	assert.Nil(t, err)
+
+	doc, err := textml.ParseDocument(strings.NewReader("#title{ a }\n#item{ b }\n#item{ c }\n#title{ d }"))
+	assert.Nil(t, err)
+
+	err = s.Validate(doc)
+	assert.Equal(t, "4:1: element #title can appear only once in the document", err.Error())
+}
+
+func TestParseErrors(t *testing.T) {
+	doc, err := textml.ParseDocument(strings.NewReader("#elements{ #a{ #args{ 3..1 } } }"))
+	assert.Nil(t, err)
+
+	_, err = schema.Parse(doc)
+	assert.Equal(t, `textml: 1:16: #elements.a.args: invalid argument count "3..1"`, err.Error())
+
+	for source, message := range map[string]string{
+		"#elements{ #a{ #arg{ #chidren{ b } } } }": "1:22: unknown schema rule #chidren, expected one of #name, #content, #children, #required, #repeated",
+		"#root{ #content{ text } }\n#element{}":    "2:1: unknown schema rule #element, expected one of #root, #elements",
+		"#elements{ #a{ #argz{ 1 } } }":            "1:16: unknown schema rule #argz, expected one of #args, #arg",
+		"#root{ #required{ a } #repeat{ a } }":     "1:23: unknown schema rule #repeat, expected one of #name, #content, #children, #required, #repeated",
+	} {
+		doc, err := textml.ParseDocument(strings.NewReader(source))
+		assert.Nil(t, err)
+
+		_, err = schema.Parse(doc)
+		assert.EqualError(t, err, message, source)
+	}
+}
@@ -8,6 +8,9 @@ import (
 	"github.com/aziis98/textml/parser"
 )
 
+// ParserVersion is the version of the syntax read by [ParseDocument], it changes whenever the same source parses to a different document so that stored results like the entries of the cache package are not reused.
+const ParserVersion = 2
+
 // ParseDocument tokenizes the input using [lexer] and then parses it with [parser.ParseDocument]
 func ParseDocument(r io.RuneReader) (ast.Block, error) {
 	tokens, err := lexer.New(r).AllTokens()
@@ -6,6 +6,7 @@ import (
 	"testing"
 
 	"github.com/aziis98/textml"
+	"github.com/aziis98/textml/ast"
 	"github.com/stretchr/testify/assert"
 )
 
@@ -18,3 +19,46 @@ func TestJsonConversion(t *testing.T) {
 
 	assert.Equal(t, `[{"args":[[{"args":[[{"text":"a","type":"text"}]],"name":"bar","type":"element"},{"args":[[{"text":"b","type":"text"}]],"name":"baz","type":"element"},{"text":"c","type":"text"}]],"name":"foo","type":"element"}]`, string(data))
 }
+
+func TestJsonRoundTrip(t *testing.T) {
+	doc, err := textml.ParseDocument(strings.NewReader("#foo{ #bar{{ a } }} }{ b } c"))
+	assert.Nil(t, err)
+
+	data, err := json.Marshal(doc)
+	assert.Nil(t, err)
+
+	var decoded ast.Block
+	err = json.Unmarshal(data, &decoded)
+	assert.Nil(t, err)
+
+	sb := &strings.Builder{}
+	err = ast.Print(sb, decoded)
+	assert.Nil(t, err)
+	assert.Equal(t, "#foo{ #bar{{ a } }} }{ b } c", sb.String())
+
+	err = json.Unmarshal([]byte(`[{"type":"comment","text":"a"}]`), &decoded)
+	assert.Equal(t, `node 0: invalid node type "comment"`, err.Error())
+}
+
+func TestJsonDocument(t *testing.T) {
+	data, err := json.Marshal(ast.NewJSONDocument(ast.Block{ast.T("a")}))
+	assert.Nil(t, err)
+	assert.Equal(t, `{"version":1,"nodes":[{"text":"a","type":"text"}]}`, string(data))
+
+	data, err = json.Marshal(ast.NewJSONDocument(nil))
+	assert.Nil(t, err)
+	assert.Equal(t, `{"version":1,"nodes":[]}`, string(data))
+
+	var doc ast.JSONDocument
+	assert.Nil(t, json.Unmarshal([]byte(`{"nodes":[{"type":"text","text":"a"}],"version":1}`), &doc))
+	assert.True(t, ast.Block{ast.T("a")}.Equal(doc.Nodes))
+
+	for data, message := range map[string]string{
+		`[{"type":"text","text":"a"}]`: `expected a document object with "version" and "nodes" fields, got array`,
+		`{"nodes":[]}`:                 `document without "version" field`,
+		`{"version":2,"nodes":[]}`:     "unsupported document version 2, expected 1",
+		`{"version":1}`:                `document without "nodes" field`,
+	} {
+		assert.EqualError(t, json.Unmarshal([]byte(data), &doc), message, data)
+	}
+}
@@ -0,0 +1,379 @@
+package textml
+
+import (
+	"encoding"
+	"fmt"
+	"reflect"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/aziis98/textml/ast"
+)
+
+// Unmarshaler is implemented by types that can decode themselves from the argument of an element.
+type Unmarshaler interface {
+	UnmarshalTML(block ast.Block) error
+}
+
+// UnmarshalError describes a value that could not be decoded, Path is the dotted list of element names leading to the value.
+type UnmarshalError struct {
+	Pos  ast.Position
+	Path string
+	Err  error
+}
+
+func (e *UnmarshalError) Error() string {
+	if e.Path == "" {
+		return fmt.Sprintf("textml: %v: %v", e.Pos, e.Err)
+	}
+
+	return fmt.Sprintf("textml: %v: #%s: %v", e.Pos, e.Path, e.Err)
+}
+
+func (e *UnmarshalError) Unwrap() error {
+	return e.Err
+}
+
+// Unmarshal decodes a dictionary shaped block (a sequence of `#KEY{ VALUE }` entries) into the value pointed to by v.
+//
+// Entries are matched to struct fields using the `tml:"name"` tag or the field name (case insensitive), unknown entries are skipped. Repeated entries are appended to slices, entries containing other elements are decoded into nested structs and maps and text values are trimmed and parsed based on the target type. Values implementing [Unmarshaler] or [encoding.TextUnmarshaler] decode themselves, [time.Time] values also accept dates without a time part.
+//
+// When decoding into an empty interface the value becomes a string, a map[string]any for blocks containing elements or a []any for repeated entries.
+func Unmarshal(block ast.Block, v any) error {
+	rv := reflect.ValueOf(v)
+	if rv.Kind() != reflect.Pointer || rv.IsNil() {
+		return fmt.Errorf("textml: Unmarshal expects a non-nil pointer, got %T", v)
+	}
+
+	d := &decoder{}
+	return d.decodeValue(block, rv.Elem(), ast.Position{})
+}
+
+var (
+	unmarshalerType     = reflect.TypeOf((*Unmarshaler)(nil)).Elem()
+	textUnmarshalerType = reflect.TypeOf((*encoding.TextUnmarshaler)(nil)).Elem()
+	timeType            = reflect.TypeOf(time.Time{})
+	durationType        = reflect.TypeOf(time.Duration(0))
+)
+
+// timeLayouts are tried in order when decoding a [time.Time]
+var timeLayouts = []string{
+	time.RFC3339,
+	"2006-01-02T15:04:05",
+	"2006-01-02 15:04:05",
+	"2006-01-02 15:04",
+	"2006-01-02",
+}
+
+type decoder struct {
+	path []string
+}
+
+func (d *decoder) errorf(pos ast.Position, format string, args ...any) error {
+	return &UnmarshalError{
+		Pos:  pos,
+		Path: strings.Join(d.path, "."),
+		Err:  fmt.Errorf(format, args...),
+	}
+}
+
+// decodeValue decodes the argument of an element (or the whole document) at pos into v.
+func (d *decoder) decodeValue(block ast.Block, v reflect.Value, pos ast.Position) error {
+	if v.Kind() == reflect.Pointer {
+		if v.IsNil() {
+			v.Set(reflect.New(v.Type().Elem()))
+		}
+
+		return d.decodeValue(block, v.Elem(), pos)
+	}
+
+	if v.CanAddr() && v.Addr().Type().Implements(unmarshalerType) {
+		if err := v.Addr().Interface().(Unmarshaler).UnmarshalTML(block); err != nil {
+			return d.errorf(pos, "%w", err)
+		}
+
+		return nil
+	}
+
+	switch v.Type() {
+	case timeType:
+		text, err := d.textValue(block)
+		if err != nil {
+			return err
+		}
+
+		for _, layout := range timeLayouts {
+			if t, err := time.Parse(layout, text); err == nil {
+				v.Set(reflect.ValueOf(t))
+				return nil
+			}
+		}
+
+		return d.errorf(pos, "cannot parse %q as a time", text)
+
+	case durationType:
+		text, err := d.textValue(block)
+		if err != nil {
+			return err
+		}
+
+		duration, err := time.ParseDuration(text)
+		if err != nil {
+			return d.errorf(pos, "cannot parse %q as a duration", text)
+		}
+
+		v.SetInt(int64(duration))
+		return nil
+	}
+
+	if v.CanAddr() && v.Addr().Type().Implements(textUnmarshalerType) {
+		text, err := d.textValue(block)
+		if err != nil {
+			return err
+		}
+
+		if err := v.Addr().Interface().(encoding.TextUnmarshaler).UnmarshalText([]byte(text)); err != nil {
+			return d.errorf(pos, "%w", err)
+		}
+
+		return nil
+	}
+
+	switch v.Kind() {
+	case reflect.Struct, reflect.Map:
+		return d.decodeEntries(block, v, pos)
+
+	case reflect.Slice:
+		if v.Type().Elem().Kind() == reflect.Uint8 {
+			text, err := d.textValue(block)
+			if err != nil {
+				return err
+			}
+
+			v.SetBytes([]byte(text))
+			return nil
+		}
+
+		item := reflect.New(v.Type().Elem()).Elem()
+		if err := d.decodeValue(block, item, pos); err != nil {
+			return err
+		}
+
+		v.Set(reflect.Append(v, item))
+		return nil
+
+	case reflect.Interface:
+		if v.NumMethod() > 0 {
+			return d.errorf(pos, "cannot decode into non empty interface %v", v.Type())
+		}
+
+		if block.FirstElement() != nil {
+			m := reflect.ValueOf(map[string]any{})
+			if err := d.decodeEntries(block, m, pos); err != nil {
+				return err
+			}
+
+			v.Set(m)
+			return nil
+		}
+
+		text, err := d.textValue(block)
+		if err != nil {
+			return err
+		}
+
+		v.Set(reflect.ValueOf(text))
+		return nil
+	}
+
+	text, err := d.textValue(block)
+	if err != nil {
+		return err
+	}
+
+	switch v.Kind() {
+	case reflect.String:
+		v.SetString(text)
+
+	case reflect.Bool:
+		b, err := strconv.ParseBool(text)
+		if err != nil {
+			return d.errorf(pos, "cannot parse %q as a bool", text)
+		}
+		v.SetBool(b)
+
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		n, err := strconv.ParseInt(text, 0, v.Type().Bits())
+		if err != nil {
+			return d.errorf(pos, "cannot parse %q as %v", text, v.Type())
+		}
+		v.SetInt(n)
+
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64, reflect.Uintptr:
+		n, err := strconv.ParseUint(text, 0, v.Type().Bits())
+		if err != nil {
+			return d.errorf(pos, "cannot parse %q as %v", text, v.Type())
+		}
+		v.SetUint(n)
+
+	case reflect.Float32, reflect.Float64:
+		n, err := strconv.ParseFloat(text, v.Type().Bits())
+		if err != nil {
+			return d.errorf(pos, "cannot parse %q as %v", text, v.Type())
+		}
+		v.SetFloat(n)
+
+	default:
+		return d.errorf(pos, "cannot decode into value of type %v", v.Type())
+	}
+
+	return nil
+}
+
+// textValue returns the trimmed text of a block that must not contain elements.
+func (d *decoder) textValue(block ast.Block) (string, error) {
+	if elem := block.FirstElement(); elem != nil {
+		return "", d.errorf(elem.Pos, "expected text but got element #%s", elem.Name)
+	}
+
+	return strings.TrimSpace(block.TextContent()), nil
+}
+
+// decodeEntries decodes each `#KEY{ VALUE }` entry of block into the corresponding field of a struct or key of a map.
+func (d *decoder) decodeEntries(block ast.Block, v reflect.Value, pos ast.Position) error {
+	var fields []field
+	switch v.Kind() {
+	case reflect.Struct:
+		fields = structFields(v.Type())
+	case reflect.Map:
+		if v.Type().Key().Kind() != reflect.String {
+			return d.errorf(pos, "cannot decode into map with key of type %v", v.Type().Key())
+		}
+		if v.IsNil() {
+			v.Set(reflect.MakeMap(v.Type()))
+		}
+	}
+
+	for _, n := range block {
+		switch n := n.(type) {
+		case *ast.TextNode:
+			if strings.TrimSpace(n.Text) != "" {
+				return d.errorf(n.Pos, "unexpected text %q, expected only elements", strings.TrimSpace(n.Text))
+			}
+
+		case *ast.ElementNode:
+			if v.Kind() == reflect.Struct {
+				f, ok := lookupField(fields, n.Name)
+				if !ok {
+					continue
+				}
+
+				if err := d.decodeElement(n, v.FieldByIndex(f.index)); err != nil {
+					return err
+				}
+				continue
+			}
+
+			key := reflect.ValueOf(n.Name).Convert(v.Type().Key())
+			target := reflect.New(v.Type().Elem()).Elem()
+
+			if existing := v.MapIndex(key); existing.IsValid() {
+				target.Set(existing)
+			}
+
+			if err := d.decodeElement(n, target); err != nil {
+				return err
+			}
+
+			// repeated entries in dynamic maps become lists
+			if existing := v.MapIndex(key); existing.IsValid() && target.Kind() == reflect.Interface {
+				list, ok := existing.Interface().([]any)
+				if !ok {
+					list = []any{existing.Interface()}
+				}
+
+				target = reflect.ValueOf(append(list, target.Interface()))
+			}
+
+			v.SetMapIndex(key, target)
+		}
+	}
+
+	return nil
+}
+
+// decodeElement decodes the single argument of an entry.
+func (d *decoder) decodeElement(elem *ast.ElementNode, v reflect.Value) error {
+	d.path = append(d.path, elem.Name)
+	defer func() { d.path = d.path[:len(d.path)-1] }()
+
+	if len(elem.Arguments) != 1 {
+		return d.errorf(elem.Pos, "expected a single argument but got %d", len(elem.Arguments))
+	}
+
+	return d.decodeValue(elem.Arguments[0], v, elem.Pos)
+}
+
+// field is an exported struct field with its TextML name
+type field struct {
+	name      string
+	index     []int
+	omitEmpty bool
+}
+
+// structFields lists the fields of a struct type following the `tml` tags, fields of embedded structs without a tag are promoted.
+func structFields(t reflect.Type) []field {
+	fields := []field{}
+
+	for i := 0; i < t.NumField(); i++ {
+		sf := t.Field(i)
+
+		tag := sf.Tag.Get("tml")
+		if tag == "-" {
+			continue
+		}
+
+		name, opts, _ := strings.Cut(tag, ",")
+
+		if sf.Anonymous && name == "" && sf.Type.Kind() == reflect.Struct {
+			for _, f := range structFields(sf.Type) {
+				f.index = append([]int{i}, f.index...)
+				fields = append(fields, f)
+			}
+			continue
+		}
+
+		if !sf.IsExported() {
+			continue
+		}
+
+		if name == "" {
+			name = sf.Name
+		}
+
+		fields = append(fields, field{
+			name:      name,
+			index:     []int{i},
+			omitEmpty: opts == "omitempty",
+		})
+	}
+
+	return fields
+}
+
+// lookupField finds a field by exact name and then falls back to a case insensitive match.
+func lookupField(fields []field, name string) (field, bool) {
+	for _, f := range fields {
+		if f.name == name {
+			return f, true
+		}
+	}
+	for _, f := range fields {
+		if strings.EqualFold(f.name, name) {
+			return f, true
+		}
+	}
+
+	return field{}, false
+}
@@ -0,0 +1,141 @@
+package textml_test
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/aziis98/textml"
+	"github.com/stretchr/testify/assert"
+)
+
+type version struct{ Major, Minor int }
+
+func (v *version) UnmarshalText(text []byte) error {
+	major, minor, ok := strings.Cut(string(text), ".")
+	if !ok {
+		return errors.New("invalid version")
+	}
+
+	var err error
+	v.Major, err = parseInt(major)
+	if err != nil {
+		return err
+	}
+	v.Minor, err = parseInt(minor)
+	return err
+}
+
+func (v version) MarshalText() ([]byte, error) {
+	return []byte(fmt.Sprintf("%d.%d", v.Major, v.Minor)), nil
+}
+
+func parseInt(s string) (int, error) {
+	n := 0
+	for _, r := range s {
+		if r < '0' || r > '9' {
+			return 0, errors.New("invalid number")
+		}
+		n = n*10 + int(r-'0')
+	}
+	return n, nil
+}
+
+type author struct {
+	Name  string `tml:"name"`
+	Email string `tml:"email"`
+}
+
+type config struct {
+	Title    string         `tml:"title"`
+	Draft    bool           `tml:"draft"`
+	Weight   int            `tml:"weight"`
+	Ratio    float64        `tml:"ratio"`
+	Date     time.Time      `tml:"date"`
+	Timeout  time.Duration  `tml:"timeout"`
+	Version  version        `tml:"version"`
+	Tags     []string       `tml:"tag"`
+	Authors  []author       `tml:"author"`
+	Extra    map[string]any `tml:"extra"`
+	Internal string         `tml:"-"`
+	Summary  *string
+}
+
+const configSource = `
+#title{ Example }
+#draft{ true }
+#weight{ 10 }
+#ratio{ 0.5 }
+#date{ 2022-08-10 }
+#timeout{ 1m30s }
+#version{ 1.2 }
+#tag{ example }
+#tag{ other }
+#author{
+	#name{ John }
+	#email{ john@example.org }
+}
+#author{ #name{ Jane } }
+#extra{
+	#a{ 1 }
+	#b{ #c{ 2 } }
+	#a{ 3 }
+}
+#internal{ ignored }
+#summary{ A short summary }
+#unknown{ ignored }
+`
+
+func TestUnmarshal(t *testing.T) {
+	doc, err := textml.ParseDocument(strings.NewReader(configSource))
+	assert.Nil(t, err)
+
+	var c config
+	err = textml.Unmarshal(doc, &c)
+	assert.Nil(t, err)
+
+	summary := "A short summary"
+
+	assert.Equal(t, config{
+		Title:   "Example",
+		Draft:   true,
+		Weight:  10,
+		Ratio:   0.5,
+		Date:    time.Date(2022, 8, 10, 0, 0, 0, 0, time.UTC),
+		Timeout: 90 * time.Second,
+		Version: version{1, 2},
+		Tags:    []string{"example", "other"},
+		Authors: []author{
+			{Name: "John", Email: "john@example.org"},
+			{Name: "Jane"},
+		},
+		Extra: map[string]any{
+			"a": []any{"1", "3"},
+			"b": map[string]any{"c": "2"},
+		},
+		Summary: &summary,
+	}, c)
+}
+
+func TestUnmarshalErrors(t *testing.T) {
+	doc, err := textml.ParseDocument(strings.NewReader("#title{ Ok }\n#author{\n  #name{ John }\n  #email{ #bold{ x } }\n}"))
+	assert.Nil(t, err)
+
+	var c config
+	err = textml.Unmarshal(doc, &c)
+	assert.Equal(t, "textml: 4:11: #author.email: expected text but got element #bold", err.Error())
+
+	doc, err = textml.ParseDocument(strings.NewReader("#weight{ ten }"))
+	assert.Nil(t, err)
+
+	err = textml.Unmarshal(doc, &c)
+
+	var unmarshalErr *textml.UnmarshalError
+	assert.True(t, errors.As(err, &unmarshalErr))
+	assert.Equal(t, "weight", unmarshalErr.Path)
+	assert.Equal(t, 1, unmarshalErr.Pos.Line)
+
+	assert.NotNil(t, textml.Unmarshal(doc, c))
+}
@@ -0,0 +1,27 @@
+package utils
+
+import "strings"
+
+// CollapseSpaces replaces each run of the characters matched by isSpace with a single space.
+func CollapseSpaces(s string, isSpace func(rune) bool) string {
+	sb := &strings.Builder{}
+
+	space := false
+	for _, r := range s {
+		if isSpace(r) {
+			space = true
+			continue
+		}
+
+		if space {
+			sb.WriteRune(' ')
+			space = false
+		}
+		sb.WriteRune(r)
+	}
+	if space {
+		sb.WriteRune(' ')
+	}
+
+	return sb.String()
+}